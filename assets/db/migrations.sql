@@ -4,10 +4,20 @@ create table if not exists file_hashes (
      filepath text primary key,
      crc32 text,
      xxhash64 text,
+     crc32c text,
+     crc64_ecma text,
+     crc64_iso text,
+     adler32 text,
+     xxh3 text,
+     xxh128 text,
+     murmur3 text,
+     siphash text,
+     highwayhash text,
      md4 text,
      md5 text,
      sha1 text,
      sha256 text,
+     sha384 text,
      sha512 text,
      blake2b_256 text,
      blake2b_512 text,
@@ -16,7 +26,64 @@ create table if not exists file_hashes (
      sha3_256 text,
      sha3_384 text,
      sha3_512 text,
+     shake128 text,
+     shake256 text,
+     cshake128 text,
+     cshake256 text,
      ed2k text,
+     aich text,
+     tth text,
+     cidv0 text,
+     cidv1 text,
+     git_sha1 text,
+     git_sha256 text,
+     ssdeep text,
+     tlsh text,
      size integer not null,
      mtime text null
 );
+
+-- piecewise hashes for each block of a file
+create table if not exists file_blocks (
+     filepath text not null,
+     byte_offset integer not null,
+     crc32 text,
+     xxhash64 text,
+     crc32c text,
+     crc64_ecma text,
+     crc64_iso text,
+     adler32 text,
+     xxh3 text,
+     xxh128 text,
+     murmur3 text,
+     siphash text,
+     highwayhash text,
+     md4 text,
+     md5 text,
+     sha1 text,
+     sha256 text,
+     sha384 text,
+     sha512 text,
+     blake2b_256 text,
+     blake2b_512 text,
+     blake3 text,
+     sha3_224 text,
+     sha3_256 text,
+     sha3_384 text,
+     sha3_512 text,
+     shake128 text,
+     shake256 text,
+     cshake128 text,
+     cshake256 text,
+     ed2k text,
+     aich text,
+     tth text,
+     cidv0 text,
+     cidv1 text,
+     git_sha1 text,
+     git_sha256 text,
+     ssdeep text,
+     tlsh text,
+     size integer not null,
+     primary key (filepath, byte_offset)
+);
@@ -2,11 +2,30 @@
 
 -- name: FileHashInsertReplace :one
 insert or replace into file_hashes (
-    filepath, crc32, xxhash64, md4, md5, sha1, sha256, sha512,
-    blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, ed2k,
+    filepath, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3, xxh128,
+    murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512, blake2b_256,
+    blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128, shake256,
+    cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256, ssdeep,
+    tlsh,
     size, mtime
-) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 returning *;
 
 -- name: FileHashByFilePath :one
-select * from file_hashes where filepath = ?;
\ No newline at end of file
+select * from file_hashes where filepath = ?;
+
+-- name: FileHashList :many
+select * from file_hashes order by filepath;
+
+-- name: FileBlockInsertReplace :exec
+insert or replace into file_blocks (
+    filepath, byte_offset, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3,
+    xxh128, murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512,
+    blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128,
+    shake256, cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256,
+    ssdeep, tlsh,
+    size
+) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: FileBlockDeleteByFilePath :exec
+delete from file_blocks where filepath = ?;
@@ -5,13 +5,20 @@ go 1.25.0
 require (
 	github.com/boyter/gocodewalker v1.5.1
 	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/dchest/siphash v1.2.3
 	github.com/djherbis/times v1.6.0
+	github.com/glaslos/ssdeep v0.4.0
 	github.com/gosuri/uiprogress v0.0.1
 	github.com/minio/blake2b-simd v0.0.0-20160723061019-3f5f724cb5b1
+	github.com/minio/highwayhash v1.0.3
+	github.com/mr-tron/base58 v1.2.0
+	github.com/spaolacci/murmur3 v1.1.0
 	github.com/spf13/cobra v1.8.1
 	github.com/zeebo/blake3 v0.2.3
+	github.com/zeebo/xxh3 v1.0.2
 	go.felesatra.moe/hash/ed2k v1.0.2
 	golang.org/x/crypto v0.31.0
+	golang.org/x/sys v0.36.0
 	modernc.org/sqlite v1.40.1
 )
 
@@ -28,7 +35,6 @@ require (
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
 	golang.org/x/sync v0.16.0 // indirect
-	golang.org/x/sys v0.36.0 // indirect
 	modernc.org/libc v1.66.10 // indirect
 	modernc.org/mathutil v1.7.1 // indirect
 	modernc.org/memory v1.11.0 // indirect
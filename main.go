@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/boyter/hashit/processor"
 	"github.com/spf13/cobra"
@@ -21,6 +22,14 @@ func main() {
 		Short:   "hashit [FILE or DIRECTORY]",
 		Long:    "Hash It!\nVersion " + processor.Version + "\nBen Boyter <ben@boyter.org>",
 		Version: processor.Version,
+		// files and directories are passed as arguments alongside the subcommands
+		Args: cobra.ArbitraryArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// hashdeep itself writes md5 and sha256 so unless other hashes are asked for so do we
+			if strings.ToLower(processor.Format) == "hashdeep" && !cmd.Flags().Changed("hash") {
+				processor.Hash = []string{"md5", "sha256"}
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			var filePaths []string
 			for _, arg := range args {
@@ -45,21 +54,135 @@ func main() {
 		"hash",
 		"c",
 		[]string{"md5", "sha1", "sha256", "sha512"},
-		"hashes to be run for each file (set to 'all' for all possible hashes)",
+		"hashes to be run for each file (set to 'all' for all possible hashes); extendable-output hashes accept a length in bits, not bytes, such as blake3:512 or shake256:512 for a 64 byte digest",
+	)
+	flags.StringVar(
+		&processor.CShakeCustom,
+		"cshake-custom",
+		"",
+		"customization string for cshake128 and cshake256",
+	)
+	flags.StringVar(
+		&processor.SipHashKeyInput,
+		"siphash-key",
+		"",
+		"hex encoded 16 byte key for siphash (default all zeros)",
+	)
+	flags.StringVar(
+		&processor.HighwayHashKeyInput,
+		"highwayhash-key",
+		"",
+		"hex encoded 32 byte key for highwayhash (default all zeros)",
 	)
 	flags.StringVarP(
 		&processor.Format,
 		"format",
 		"f",
 		"text",
-		"set output format [text, json, sum, hashdeep, hashonly, sqlite]",
+		"set output format [text, json, csv, tsv, sum, hashdeep, dfxml, aide, hashonly, sqlite, uri, sri, spdx-json, spdx-tv, cyclonedx-json, in-toto]",
+	)
+	flags.StringVar(
+		&processor.WriteSums,
+		"write-sums",
+		"",
+		"write a checksum file into each directory listing its files [SHA256SUMS, SHA1SUMS, SHA384SUMS, SHA512SUMS, MD5SUMS, B2SUMS, sfv, md5]",
+	)
+	flags.BoolVar(
+		&processor.VerifySums,
+		"verify-sums",
+		false,
+		"find every checksum file below the paths and verify the files each lists",
+	)
+	flags.BoolVar(
+		&processor.VerifyCAS,
+		"verify-cas",
+		false,
+		"verify blobs named by their digest such as OCI image layouts, docker registries, git objects and hashit store",
+	)
+	flags.StringArrayVar(
+		&processor.OutputFormats,
+		"output-format",
+		[]string{},
+		"also write FORMAT=PATH such as json=out.json in the same pass, can be repeated",
+	)
+	flags.StringSliceVar(
+		&processor.Columns,
+		"columns",
+		[]string{},
+		"columns for csv and tsv from file, bytes, mtime and any hash (default file, bytes and the enabled hashes)",
+	)
+	flags.StringVar(
+		&processor.Template,
+		"template",
+		"",
+		"Go text/template executed for each file in place of the format such as '{{.File}},{{.Bytes}},{{.SHA256}}'",
+	)
+	flags.StringVar(
+		&processor.TemplateFile,
+		"template-file",
+		"",
+		"file holding the template, which may define header and footer templates",
 	)
 	flags.StringVarP(
 		&processor.AuditFile,
 		"audit",
 		"a",
 		"",
-		"audit against supplied file; audit file must be in hashdeep output format",
+		"audit against supplied file; audit file must be in hashdeep or DFXML output format or an AIDE database using any supported hashes",
+	)
+	flags.StringVar(
+		&processor.Encoding,
+		"encoding",
+		"hex",
+		"set digest encoding [hex, base64, base64url, base32, nix32]",
+	)
+	flags.StringVar(
+		&processor.InTotoPredicateType,
+		"predicate-type",
+		"https://github.com/boyter/hashit/digests/v1",
+		"predicateType of the in-toto statement, SLSA provenance types require --predicate",
+	)
+	flags.StringVar(
+		&processor.InTotoPredicate,
+		"predicate",
+		"",
+		"JSON file used as the predicate of the in-toto statement (default empty)",
+	)
+	flags.StringVar(
+		&processor.SigningKey,
+		"signing-key",
+		"",
+		"PEM private key (ed25519, ecdsa or rsa) to sign the in-toto statement in a DSSE envelope",
+	)
+	flags.StringVar(
+		&processor.PiecewiseInput,
+		"piecewise",
+		"",
+		"also hash each block of this size in files such as 1m, accepting k, m, g and t suffixes",
+	)
+	flags.BoolVar(
+		&processor.Tree,
+		"tree",
+		false,
+		"also output git tree IDs for each directory using the git-sha1 and git-sha256 hashes",
+	)
+	flags.StringVar(
+		&processor.SimilarTo,
+		"similar-to",
+		"",
+		"report files similar to the supplied file, ssdeep or TLSH hash",
+	)
+	flags.IntVar(
+		&processor.Threshold,
+		"threshold",
+		0,
+		"ssdeep score (0-100) files must be above to be reported by --similar-to",
+	)
+	flags.IntVar(
+		&processor.TLSHThreshold,
+		"tlsh-threshold",
+		100,
+		"TLSH distance files must be at or below to be reported by --similar-to",
 	)
 	flags.BoolVarP(
 		&processor.Recursive,
@@ -190,6 +313,258 @@ func main() {
 		"ignore files and directories matching regular expression",
 	)
 
+	torrentCmd := &cobra.Command{
+		Use:   "torrent",
+		Short: "create or verify BitTorrent metainfo files",
+	}
+	torrentCmd.AddCommand(&cobra.Command{
+		Use:   "create [FILE or DIRECTORY]",
+		Short: "create a .torrent file for a file or directory",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			processor.TorrentCreate(args[0])
+		},
+	})
+	torrentCmd.AddCommand(&cobra.Command{
+		Use:   "verify TORRENT [FILE or DIRECTORY]",
+		Short: "verify local data against a .torrent file",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			path := ""
+			if len(args) == 2 {
+				path = args[1]
+			}
+			processor.TorrentVerify(args[0], path)
+		},
+	})
+
+	torrentFlags := torrentCmd.PersistentFlags()
+	torrentFlags.StringVar(
+		&processor.TorrentVersion,
+		"meta-version",
+		"hybrid",
+		"torrent version to create [v1, v2, hybrid]",
+	)
+	torrentFlags.StringVar(
+		&processor.TorrentPieceLength,
+		"piece-length",
+		"",
+		"piece length such as 256k which must be a power of two (default based on size)",
+	)
+	torrentFlags.StringArrayVar(
+		&processor.TorrentTrackers,
+		"tracker",
+		[]string{},
+		"tracker announce URL, can be supplied multiple times",
+	)
+	torrentFlags.StringVar(
+		&processor.TorrentComment,
+		"comment",
+		"",
+		"comment to include in the torrent",
+	)
+	torrentFlags.BoolVar(
+		&processor.TorrentPrivate,
+		"private",
+		false,
+		"mark the torrent as private",
+	)
+	rootCmd.AddCommand(torrentCmd)
+
+	dirhashCmd := &cobra.Command{
+		Use:   "dirhash DIRECTORY...",
+		Short: "print the Go module h1: hash of directories as recorded in go.sum",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			processor.DirHash(args)
+		},
+	}
+	dirhashCmd.Flags().StringVar(
+		&processor.DirHashPrefix,
+		"prefix",
+		"",
+		"module@version prefix for each file (default from the module cache path or directory name)",
+	)
+	rootCmd.AddCommand(dirhashCmd)
+
+	narCmd := &cobra.Command{
+		Use:   "nar PATH...",
+		Short: "print the Nix NAR hash of files or directories",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			processor.NarHash(args)
+		},
+	}
+	narCmd.Flags().BoolVar(
+		&processor.NarBase32,
+		"base32",
+		false,
+		"print the hash using the Nix base32 encoding rather than SRI",
+	)
+	rootCmd.AddCommand(narCmd)
+
+	mtreeCmd := &cobra.Command{
+		Use:   "mtree",
+		Short: "create or verify BSD mtree specifications",
+	}
+	mtreeCmd.AddCommand(&cobra.Command{
+		Use:   "create [DIRECTORY]",
+		Short: "print an mtree spec for a directory",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			path := "."
+			if len(args) == 1 {
+				path = args[0]
+			}
+			processor.MtreeCreate(path)
+		},
+	})
+	mtreeCmd.AddCommand(&cobra.Command{
+		Use:   "verify SPEC [DIRECTORY]",
+		Short: "verify a directory against an mtree spec, use - to read the spec from stdin",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			path := "."
+			if len(args) == 2 {
+				path = args[1]
+			}
+			processor.MtreeVerify(args[0], path)
+		},
+	})
+	mtreeCmd.PersistentFlags().StringSliceVar(
+		&processor.MtreeKeywords,
+		"keywords",
+		[]string{"type", "mode", "uid", "gid", "size", "time", "link", "sha256digest"},
+		"keywords to write for each entry, digests can be md5digest, sha1digest, sha256digest, sha384digest, sha512digest or rmd160digest",
+	)
+	rootCmd.AddCommand(mtreeCmd)
+
+	convertCmd := &cobra.Command{
+		Use:   "convert MANIFEST",
+		Short: "convert an existing manifest to another output format without reading the files",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// sum lines without a hash name could be any hash of the same length
+			if cmd.Flags().Changed("hash") {
+				processor.SumHashes = processor.Hash
+			}
+			processor.Convert(args[0])
+		},
+	}
+	convertCmd.Flags().StringVar(
+		&processor.ConvertFrom,
+		"from",
+		"",
+		"format of the manifest [hashdeep, json, sum, sqlite, dfxml, aide] (default detected from the contents)",
+	)
+	convertCmd.Flags().StringVar(
+		&processor.ConvertTo,
+		"to",
+		"",
+		"output format to convert to, any of those supported by --format",
+	)
+	_ = convertCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(convertCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "compare two manifests reporting files added, removed, modified or moved",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.Flags().Changed("hash") {
+				processor.SumHashes = processor.Hash
+			}
+			processor.Diff(args[0], args[1])
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "compare DIR_A DIR_B",
+		Short: "compare two directories reporting files missing, extra, differing or moved",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			// a single hash is enough to prove a copy unless others were asked for
+			if !cmd.Flags().Changed("hash") {
+				processor.Hash = []string{"sha256"}
+			}
+			processor.Compare(args[0], args[1])
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "copy SRC DST",
+		Short: "copy a file or the contents of a directory hashing as it copies and verifying the copy",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			processor.Copy(args[0], args[1])
+		},
+	})
+
+	storeCmd := &cobra.Command{
+		Use:   "store [FILE or DIRECTORY]...",
+		Short: "copy or hardlink files into a content addressed store named by their digest",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// the first hash names the stored files so only one is needed unless others were asked for
+			if !cmd.Flags().Changed("hash") {
+				processor.Hash = []string{"sha256"}
+			}
+			processor.Store(args)
+		},
+	}
+	storeCmd.Flags().StringVar(
+		&processor.StoreInto,
+		"into",
+		"",
+		"directory of the store, where files are kept as HASH/ab/cdef... using the first hash",
+	)
+	storeCmd.Flags().BoolVar(
+		&processor.StoreLink,
+		"link",
+		false,
+		"hardlink files into the store rather than copying them",
+	)
+	_ = storeCmd.MarkFlagRequired("into")
+	rootCmd.AddCommand(storeCmd)
+
+	dedupeCmd := &cobra.Command{
+		Use:   "dedupe [FILE or DIRECTORY]...",
+		Short: "replace duplicate files with reflinks or hardlinks to one copy, journaled so it can be undone",
+		Args:  cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// a single hash finds the duplicates which are then compared byte by byte
+			if !cmd.Flags().Changed("hash") {
+				processor.Hash = []string{"sha256"}
+			}
+			processor.Dedupe(args)
+		},
+	}
+	dedupeCmd.Flags().StringVar(
+		&processor.DedupeMethod,
+		"method",
+		"reflink",
+		"how duplicates are replaced [reflink, hardlink, auto] where auto hardlinks if the filesystem cannot reflink",
+	)
+	dedupeCmd.Flags().BoolVar(
+		&processor.DedupeDryRun,
+		"dry-run",
+		false,
+		"report the duplicates and bytes which could be reclaimed without changing anything",
+	)
+	dedupeCmd.Flags().StringVar(
+		&processor.DedupeJournal,
+		"journal",
+		"hashit-dedupe.journal",
+		"file each replaced duplicate is appended to so it can be undone",
+	)
+	dedupeCmd.Flags().BoolVar(
+		&processor.DedupeUndo,
+		"undo",
+		false,
+		"give every file in the journal its own copy again, restoring its mode, owner and modification time",
+	)
+	rootCmd.AddCommand(dedupeCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
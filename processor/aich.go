@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/sha1"
+	"encoding/base32"
+	"hash"
+)
+
+// AICH (Advanced Intelligent Corruption Handling) is the SHA1 tree hash used by
+// eMule alongside ed2k. Files are split into the same 9728000 byte parts as ed2k
+// which are then split into 184320 byte blocks forming the leaves of the tree.
+const (
+	aichPartSize  = 9728000
+	aichBlockSize = 184320
+)
+
+// aichState holds the SHA1 of every block until the tree can be built when
+// summed, which is 20 bytes for every 180 KiB of input.
+// Sum returns the hash as unpadded base32 text.
+type aichState struct {
+	h      hash.Hash
+	block  int64 // bytes written to the current block
+	part   int64 // bytes written to the current part
+	length int64
+	leaves [][]byte
+}
+
+func newAICH() *aichState {
+	return &aichState{h: sha1.New()}
+}
+
+func (a *aichState) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		// blocks never cross the boundary of a part
+		take := min(aichBlockSize-a.block, aichPartSize-a.part, int64(len(p)))
+		_, _ = a.h.Write(p[:take])
+		a.block += take
+		a.part += take
+		a.length += take
+		p = p[take:]
+
+		if a.block == aichBlockSize || a.part == aichPartSize {
+			a.leaves = append(a.leaves, a.h.Sum(nil))
+			a.h.Reset()
+			a.block = 0
+			if a.part == aichPartSize {
+				a.part = 0
+			}
+		}
+	}
+	return written, nil
+}
+
+func (a *aichState) root() []byte {
+	leaves := a.leaves
+	if a.block != 0 || a.length == 0 {
+		leaves = append(leaves[:len(leaves):len(leaves)], a.h.Sum(nil))
+	}
+
+	next := 0
+	return aichTree(a.length, true, leaves, &next)
+}
+
+// aichTree builds the tree the same way as eMule where a node larger than a part
+// is split on part boundaries and otherwise on block boundaries, with the extra
+// block or part going to the left when the node is itself a left branch
+func aichTree(size int64, left bool, leaves [][]byte, next *int) []byte {
+	base := int64(aichPartSize)
+	if size <= aichPartSize {
+		base = aichBlockSize
+	}
+
+	if size <= base {
+		leaf := leaves[*next]
+		*next++
+		return leaf
+	}
+
+	blocks := (size + base - 1) / base
+	if left {
+		blocks++
+	}
+	leftSize := blocks / 2 * base
+
+	h := sha1.New()
+	h.Write(aichTree(leftSize, true, leaves, next))
+	h.Write(aichTree(size-leftSize, false, leaves, next))
+	return h.Sum(nil)
+}
+
+func (a *aichState) Sum(b []byte) []byte {
+	return append(b, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(a.root())...)
+}
+
+func (a *aichState) Reset() {
+	*a = aichState{h: sha1.New()}
+}
+
+func (a *aichState) Size() int {
+	return 32
+}
+
+func (a *aichState) BlockSize() int {
+	return aichBlockSize
+}
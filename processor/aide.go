@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/djherbis/times"
+)
+
+// aideDigests maps our hash names to the AIDE database columns, with bit
+// being the position of the attribute in the attr field of each entry
+var aideDigests = []struct {
+	hash string
+	aide string
+	bit  uint
+}{
+	{"md5", "md5", 12},
+	{"sha1", "sha1", 13},
+	{"crc32", "crc32", 16},
+	{"sha256", "sha256", 28},
+	{"sha512", "sha512", 29},
+}
+
+// aideColumns are the columns written before the digests along with their attribute bit
+var aideColumns = []struct {
+	name string
+	bit  uint
+}{
+	{"name", 0},
+	{"lname", 1},
+	{"attr", 20},
+	{"perm", 2},
+	{"inode", 9},
+	{"uid", 3},
+	{"gid", 4},
+	{"size", 5},
+	{"mtime", 8},
+	{"ctime", 7},
+	{"bcount", 10},
+	{"lcount", 11},
+}
+
+// aideUnsafe are the characters AIDE escapes in names along with anything unprintable
+const aideUnsafe = " <>\"#%{}|\\^~[]`@:\033'"
+
+// toAIDE writes the results as an AIDE database which can be used by aide --compare,
+// with every file named by its absolute path as AIDE expects
+func toAIDE(input chan Result, output string) string {
+	var str strings.Builder
+
+	var columns []string
+	for _, c := range aideColumns {
+		columns = append(columns, c.name)
+	}
+	for _, d := range aideDigests {
+		if hasHash(d.hash) {
+			columns = append(columns, d.aide)
+		}
+	}
+
+	str.WriteString("@@begin_db\n")
+	str.WriteString(fmt.Sprintf("# This file was generated by hashit, version %s\n", Version))
+	str.WriteString(fmt.Sprintf("# Time of generation was %s\n", time.Now().Format("2006-01-02 15:04:05")))
+	str.WriteString("@@db_spec " + strings.Join(columns, " ") + "\n")
+
+	for res := range input {
+		str.WriteString(aideLine(res))
+
+		if !NoStream && output == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	str.WriteString("@@end_db\n")
+	return str.String()
+}
+
+func aideLine(res Result) string {
+	name := res.File
+	if abs, err := filepath.Abs(res.File); err == nil && !StandardInput {
+		name = abs
+	}
+
+	values := map[string]string{
+		"name":  aideEscape(name),
+		"lname": "0",
+		"attr":  "",
+		"size":  strconv.FormatInt(res.Bytes, 10),
+	}
+
+	if fi, err := os.Lstat(res.File); err == nil && !StandardInput {
+		values["perm"] = strconv.FormatUint(uint64(0o100000|mtreeMode(fi.Mode())), 8)
+		values["mtime"] = aideTime(fi.ModTime())
+		if uid, gid, ok := fileOwner(fi); ok {
+			values["uid"] = strconv.Itoa(uid)
+			values["gid"] = strconv.Itoa(gid)
+		}
+		if inode, links, blocks, ok := fileInode(fi); ok {
+			values["inode"] = strconv.FormatUint(inode, 10)
+			values["lcount"] = strconv.FormatUint(links, 10)
+			values["bcount"] = strconv.FormatInt(blocks, 10)
+		}
+		if t, err := times.Lstat(res.File); err == nil && t.HasChangeTime() {
+			values["ctime"] = aideTime(t.ChangeTime())
+		}
+	}
+
+	var attr uint64
+	for _, c := range aideColumns {
+		if _, ok := values[c.name]; ok {
+			attr |= 1 << c.bit
+		}
+	}
+	for _, d := range aideDigests {
+		if hasHash(d.hash) {
+			values[d.aide] = base64.StdEncoding.EncodeToString(decodeEncoded(res.Digest(d.hash)))
+			attr |= 1 << d.bit
+		}
+	}
+	values["attr"] = strconv.FormatUint(attr, 10)
+
+	var line []string
+	for _, c := range aideColumns {
+		line = append(line, aideValue(values, c.name))
+	}
+	for _, d := range aideDigests {
+		if hasHash(d.hash) {
+			line = append(line, aideValue(values, d.aide))
+		}
+	}
+	return strings.Join(line, " ") + "\n"
+}
+
+// aideValue returns the value of the column where AIDE writes 0 for anything missing
+func aideValue(values map[string]string, column string) string {
+	if v, ok := values[column]; ok && v != "" {
+		return v
+	}
+	return "0"
+}
+
+// aideTime writes the time the same way as AIDE, which is the base64 of the seconds as text
+func aideTime(t time.Time) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.FormatInt(t.Unix(), 10)))
+}
+
+func aideEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= 0x7f || strings.IndexByte(aideUnsafe, c) != -1 {
+			sb.WriteString(fmt.Sprintf("%%%02X", c))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func aideUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// parseAIDEFile builds the audit records from the regular files in an AIDE
+// database. Only the digests are audited and as each entry only has the digests
+// its rule group asked for, any it is missing are not compared.
+func (hdl *Auditor) parseAIDEFile(input string) (map[string]AuditRecord, error) {
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	auditLookup := map[string]AuditRecord{}
+	var spec []string
+	hdl.hashes = []string{}
+	hdl.absolute = true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "@@begin_db" {
+			continue
+		}
+		if line == "@@end_db" {
+			break
+		}
+
+		if strings.HasPrefix(line, "@@db_spec") {
+			spec = strings.Fields(line)[1:]
+			for _, column := range spec {
+				if name := aideHashName(column); name != "" {
+					hdl.hashes = append(hdl.hashes, name)
+				}
+			}
+			continue
+		}
+		if spec == nil {
+			return nil, errors.New("AIDE database has no @@db_spec")
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != len(spec) {
+			return nil, fmt.Errorf("AIDE database entry has %d fields expected %d", len(fields), len(spec))
+		}
+
+		record := AuditRecord{Hashes: map[string]string{}, Size: "0"}
+		regular := true
+		for i, column := range spec {
+			value := fields[i]
+			switch column {
+			case "name":
+				record.Filename = aideUnescape(value)
+			case "size":
+				record.Size = value
+			case "perm":
+				perm, err := strconv.ParseUint(value, 8, 32)
+				regular = err != nil || perm == 0 || perm&0o170000 == 0o100000
+			default:
+				if name := aideHashName(column); name != "" && value != "0" {
+					record.Hashes[name] = hdl.normalizeDigest(name, value)
+				}
+			}
+		}
+
+		// directories and links are not hashed so cannot be audited
+		if regular {
+			auditLookup[record.Filename] = record
+		}
+	}
+
+	return auditLookup, scanner.Err()
+}
+
+// aideHashName returns our name for the hash of an AIDE database column
+func aideHashName(column string) string {
+	for _, d := range aideDigests {
+		if d.aide == column {
+			return d.hash
+		}
+	}
+	switch column {
+	case "sha3_256":
+		return HashNames.Sha3256
+	case "sha3_512":
+		return HashNames.Sha3512
+	}
+	return ""
+}
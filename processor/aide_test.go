@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewAuditorAIDE(t *testing.T) {
+	input := `@@begin_db
+# This file was generated by Aide, version 0.18.6
+@@db_spec name lname attr perm inode uid gid size mtime ctime md5 sha256 rmd160
+/at 0 4294967295 40755 1 0 0 4096 MTcwMDAwMDAwMA== MTcwMDAwMDAwMA== 0 0 0
+/at/a.txt 0 4294967295 100644 2 0 0 6 MTcwMDAwMDAwMA== MTcwMDAwMDAwMA== sZRqySSS0jR8YjW00mERhA== WJG1tSLV3whtD/CxEPvZ0hu0/HFjrzTQgoai6Eb2vgM= ignored
+/at/b%20c.log 0 4294967295 100644 3 0 0 6 MTcwMDAwMDAwMA== MTcwMDAwMDAwMA== sZRqySSS0jR8YjW00mERhA== 0 0
+@@end_db
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	if len(hdl.Hashes()) != 2 || hdl.Hashes()[0] != "md5" || hdl.Hashes()[1] != "sha256" {
+		t.Errorf("expected md5 and sha256 got %v", hdl.Hashes())
+	}
+	if hdl.Count() != 2 {
+		t.Errorf("expected the directory to be skipped got %d files", hdl.Count())
+	}
+
+	res := Result{
+		File:   "/at/a.txt",
+		MD5:    "b1946ac92492d2347c6235b4d2611184",
+		SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	if r := hdl.Find(res); r != FileMatched {
+		t.Errorf("expected match got %d", r)
+	}
+
+	// the sha256 was not recorded for the log so only the md5 is compared
+	res.File = "/at/b c.log"
+	res.SHA256 = "different"
+	if r := hdl.Find(res); r != FileMatched {
+		t.Errorf("expected match got %d", r)
+	}
+}
+
+func TestAIDEEscape(t *testing.T) {
+	for _, name := range []string{"/etc/passwd", "/tmp/sp ace", "/tmp/100%", "/tmp/a:b@c"} {
+		if got := aideUnescape(aideEscape(name)); got != name {
+			t.Errorf("expected %q got %q", name, got)
+		}
+	}
+	if got := aideEscape("/tmp/sp ace"); got != "/tmp/sp%20ace" {
+		t.Errorf("expected /tmp/sp%%20ace got %s", got)
+	}
+}
+
+func TestAIDEAuditRoundTrip(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5", "sha256"}
+
+	// AIDE records absolute paths while the files are audited by the path given
+	t.Chdir(t.TempDir())
+	_ = os.MkdirAll("d", 0755)
+	var results []Result
+	for _, name := range []string{filepath.Join("d", "a.txt"), filepath.Join("d", "b.txt")} {
+		_ = os.WriteFile(name, []byte(name), 0600)
+		res, err := processReader(name, strings.NewReader(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, res)
+	}
+
+	input := make(chan Result, len(results))
+	for _, res := range results {
+		input <- res
+	}
+	close(input)
+	out := toAIDE(input, "aide.db")
+
+	hdl, err := NewAuditor(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range results {
+		if r := hdl.Find(res); r != FileMatched {
+			t.Errorf("expected %s to match got %d", res.File, r)
+		}
+	}
+	if len(hdl.GetUnmatched()) != 0 {
+		t.Errorf("expected every file to be matched got %v", hdl.GetUnmatched())
+	}
+}
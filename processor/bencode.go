@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// bencode encodes the value as used by BitTorrent. Supported types are
+// string, []byte, int, int64, []any and map[string]any with dictionary
+// keys always written in sorted order as the specification requires
+func bencode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := bencodeTo(&buf, v)
+	return buf.Bytes(), err
+}
+
+func bencodeTo(buf *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case string:
+		buf.WriteString(strconv.Itoa(len(x)))
+		buf.WriteByte(':')
+		buf.WriteString(x)
+	case []byte:
+		buf.WriteString(strconv.Itoa(len(x)))
+		buf.WriteByte(':')
+		buf.Write(x)
+	case int:
+		buf.WriteString("i" + strconv.Itoa(x) + "e")
+	case int64:
+		buf.WriteString("i" + strconv.FormatInt(x, 10) + "e")
+	case []any:
+		buf.WriteByte('l')
+		for _, item := range x {
+			if err := bencodeTo(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('d')
+		for _, k := range keys {
+			_ = bencodeTo(buf, k)
+			if err := bencodeTo(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// bdecode decodes bencoded data into string, int64, []any and map[string]any
+func bdecode(data []byte) (any, error) {
+	v, rest, err := bdecodeValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("bencode trailing data")
+	}
+	return v, nil
+}
+
+func bdecodeValue(data []byte) (any, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("bencode unexpected end of data")
+	}
+
+	switch {
+	case data[0] == 'i':
+		end := bytes.IndexByte(data, 'e')
+		if end == -1 {
+			return nil, nil, errors.New("bencode unterminated integer")
+		}
+		i, err := strconv.ParseInt(string(data[1:end]), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("bencode invalid integer: %w", err)
+		}
+		return i, data[end+1:], nil
+	case data[0] == 'l':
+		list := []any{}
+		data = data[1:]
+		for len(data) != 0 && data[0] != 'e' {
+			v, rest, err := bdecodeValue(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, v)
+			data = rest
+		}
+		if len(data) == 0 {
+			return nil, nil, errors.New("bencode unterminated list")
+		}
+		return list, data[1:], nil
+	case data[0] == 'd':
+		dict := map[string]any{}
+		data = data[1:]
+		for len(data) != 0 && data[0] != 'e' {
+			k, rest, err := bdecodeValue(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, nil, errors.New("bencode dictionary key must be a string")
+			}
+			v, rest, err := bdecodeValue(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			dict[key] = v
+			data = rest
+		}
+		if len(data) == 0 {
+			return nil, nil, errors.New("bencode unterminated dictionary")
+		}
+		return dict, data[1:], nil
+	case data[0] >= '0' && data[0] <= '9':
+		colon := bytes.IndexByte(data, ':')
+		if colon == -1 {
+			return nil, nil, errors.New("bencode invalid string")
+		}
+		length, err := strconv.Atoi(string(data[:colon]))
+		if err != nil || length < 0 || colon+1+length > len(data) {
+			return nil, nil, errors.New("bencode invalid string length")
+		}
+		return string(data[colon+1 : colon+1+length]), data[colon+1+length:], nil
+	}
+
+	return nil, nil, fmt.Errorf("bencode unexpected character %q", data[0])
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package processor
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dropCache asks the kernel to evict the file from the page cache so it is
+// read back from the disk, which only works once the file has been synced
+func dropCache(f *os.File) {
+	_ = unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package processor
+
+import "os"
+
+// dropCache does nothing where evicting a file from the page cache is not supported
+func dropCache(f *os.File) {}
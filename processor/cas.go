@@ -0,0 +1,348 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"compress/zlib"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// casBlob is a file in a content addressed store along with the digest its path says it has
+type casBlob struct {
+	path   string
+	hash   string
+	digest string
+	git    bool // a zlib compressed git loose object named by the digest of its decompressed content
+}
+
+// ociDescriptor is a reference to a blob in an OCI image layout
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociManifest holds the references of an OCI index.json, image index or image manifest
+type ociManifest struct {
+	Manifests []ociDescriptor `json:"manifests"`
+	Config    *ociDescriptor  `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Blobs     []ociDescriptor `json:"blobs"`
+	Subject   *ociDescriptor  `json:"subject"`
+}
+
+// casHashName returns our name for the hash used in a store path such as sha256,
+// or an empty string if it is not a hash with a fixed length digest
+func casHashName(name string) string {
+	name = strings.ToLower(name)
+	for _, n := range []string{name, strings.ReplaceAll(name, "-", "")} {
+		if HashNames.Digest(n) == n && !textHashes[n] && casDigestSize(n) != 0 {
+			return n
+		}
+	}
+	return ""
+}
+
+func casDigestSize(hash string) int {
+	if size, ok := digestSizes[hash]; ok {
+		return size
+	}
+	return hashLength(hash)
+}
+
+// casDigest checks the digest is hex of the length the hash produces
+func casDigest(hash string, digest string) bool {
+	if len(digest) != casDigestSize(hash)*2 {
+		return false
+	}
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return s != "" && len(s)%2 == 0 && err == nil
+}
+
+// casBlobFor works out from the path of a file whether it is a blob in a known
+// layout, returning the hash and digest its content should have
+func casBlobFor(path string) (casBlob, bool) {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	name := parts[len(parts)-1]
+	parent := func(i int) string {
+		if len(parts)-1-i < 0 {
+			return ""
+		}
+		return parts[len(parts)-1-i]
+	}
+
+	// git loose objects are kept as objects/ab/cdef... for sha1 and sha256 repositories
+	if parent(2) == "objects" && isHex(parent(1)) && len(parent(1)) == 2 {
+		digest := parent(1) + name
+		for _, h := range []string{HashNames.SHA1, HashNames.SHA256} {
+			if casDigest(h, digest) {
+				return casBlob{path: path, hash: h, digest: digest, git: true}, true
+			}
+		}
+	}
+
+	// docker registry storage keeps blobs as blobs/sha256/ab/abcdef.../data
+	if name == "data" {
+		if h := casHashName(parent(3)); h != "" && casDigest(h, parent(1)) && strings.HasPrefix(parent(1), parent(2)) {
+			return casBlob{path: path, hash: h, digest: parent(1)}, true
+		}
+	}
+
+	// files named by their digest such as sha256:abcdef...
+	if alg, digest, ok := strings.Cut(name, ":"); ok {
+		if h := casHashName(alg); h != "" && casDigest(h, strings.ToLower(digest)) {
+			return casBlob{path: path, hash: h, digest: strings.ToLower(digest)}, true
+		}
+	}
+
+	// OCI image layouts keep blobs as blobs/sha256/abcdef...
+	if h := casHashName(parent(1)); h != "" && casDigest(h, name) {
+		return casBlob{path: path, hash: h, digest: name}, true
+	}
+
+	// hashit store keeps blobs as sha256/ab/cdef...
+	if h := casHashName(parent(2)); h != "" && len(parent(1)) == 2 && casDigest(h, parent(1)+name) {
+		return casBlob{path: path, hash: h, digest: parent(1) + name}, true
+	}
+
+	return casBlob{}, false
+}
+
+// verifyCAS finds every blob below the paths whose name is its digest, in
+// layouts such as OCI images, docker registries, git objects and hashit store,
+// rehashing each to check its content matches its name. The references of any
+// OCI image layouts found are also checked.
+func verifyCAS(paths []string) (string, bool) {
+	found := make(chan string, FileListQueueSize)
+	go func() {
+		for _, p := range paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				printError(fmt.Sprintf("file or directory issue: %s %s", p, err.Error()))
+				continue
+			}
+			if !fi.IsDir() {
+				found <- p
+				continue
+			}
+			walkDirectoryWithIgnore(p, found)
+		}
+		close(found)
+	}()
+
+	var blobs []casBlob
+	var layouts []string
+	for f := range found {
+		if filepath.Base(f) == "oci-layout" {
+			layouts = append(layouts, filepath.Dir(f))
+		}
+		if b, ok := casBlobFor(f); ok {
+			blobs = append(blobs, b)
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].path < blobs[j].path })
+	sort.Strings(layouts)
+
+	if len(blobs) == 0 && len(layouts) == 0 {
+		printError("no content addressed blobs found")
+		return "", false
+	}
+
+	// hash every blob which is not a git object using all of the hashes needed
+	Hash = []string{}
+	Encoding = "hex"
+	files := make(chan string, len(blobs))
+	for _, b := range blobs {
+		if b.git {
+			continue
+		}
+		if !contains(Hash, b.hash) {
+			Hash = append(Hash, b.hash)
+		}
+		files <- b.path
+	}
+	close(files)
+
+	var str strings.Builder
+	valid := true
+	results, err := hashFiles(files)
+	if err != nil {
+		printError(err.Error())
+		valid = false
+	}
+	digests := map[string]Result{}
+	for _, res := range results {
+		digests[res.File] = res
+	}
+
+	failed := 0
+	for _, b := range blobs {
+		digest := ""
+		if b.git {
+			digest, err = gitObjectDigest(b.path, b.hash)
+			if err != nil {
+				printError(fmt.Sprintf("unable to read git object %s: %s", b.path, err.Error()))
+			}
+		} else if res, ok := digests[b.path]; ok {
+			digest = res.Digest(b.hash)
+		}
+
+		switch {
+		case digest == "":
+			str.WriteString(fmt.Sprintf("%s: FAILED open or read\n", b.path))
+			failed++
+		case digest != b.digest:
+			str.WriteString(fmt.Sprintf("%s: FAILED %s is %s\n", b.path, b.hash, digest))
+			failed++
+		default:
+			if Verbose {
+				str.WriteString(fmt.Sprintf("%s: OK\n", b.path))
+			}
+		}
+	}
+
+	references, broken := 0, 0
+	for _, layout := range layouts {
+		c, problems := verifyOCILayout(layout)
+		references += c
+		broken += len(problems)
+		for _, p := range problems {
+			str.WriteString(p + "\n")
+		}
+	}
+
+	if failed != 0 || broken != 0 {
+		valid = false
+	}
+	str.WriteString(fmt.Sprintf("%d blobs checked, %d failed, %d references checked, %d broken\n", len(blobs), failed, references, broken))
+	return str.String(), valid
+}
+
+// gitObjectDigest decompresses a git loose object returning the digest of its content
+func gitObjectDigest(path string, name string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r, err := zlib.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	var h hash.Hash = sha1.New()
+	if name == HashNames.SHA256 {
+		h = sha256.New()
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyOCILayout follows every reference from the index.json of an OCI image
+// layout checking each blob exists with the expected size, returning the number
+// of references checked along with any problems found
+func verifyOCILayout(layout string) (int, []string) {
+	index := filepath.Join(layout, "index.json")
+	b, err := os.ReadFile(index)
+	if err != nil {
+		return 0, []string{fmt.Sprintf("%s: unable to read: %s", index, err.Error())}
+	}
+
+	type reference struct {
+		descriptor ociDescriptor
+		from       string
+	}
+	var queue []reference
+	add := func(content []byte, from string) error {
+		var m ociManifest
+		if err := json.Unmarshal(content, &m); err != nil {
+			return err
+		}
+		descriptors := append(append(append([]ociDescriptor{}, m.Manifests...), m.Layers...), m.Blobs...)
+		for _, d := range []*ociDescriptor{m.Config, m.Subject} {
+			if d != nil {
+				descriptors = append(descriptors, *d)
+			}
+		}
+		for _, d := range descriptors {
+			queue = append(queue, reference{descriptor: d, from: from})
+		}
+		return nil
+	}
+
+	if err := add(b, index); err != nil {
+		return 0, []string{fmt.Sprintf("%s: unable to parse: %s", index, err.Error())}
+	}
+
+	var problems []string
+	checked := 0
+	seen := map[string]bool{}
+	for len(queue) != 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		checked++
+
+		d := ref.descriptor
+		// the digest becomes part of the path so must be checked before it is used
+		alg, digest, _ := strings.Cut(d.Digest, ":")
+		if hash := casHashName(alg); hash == "" || !casDigest(hash, digest) {
+			problems = append(problems, fmt.Sprintf("%s: invalid digest, referenced by %s", d.Digest, ref.from))
+			continue
+		}
+		blob := filepath.Join(layout, "blobs", alg, digest)
+		fi, err := os.Stat(blob)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: missing, referenced by %s", d.Digest, ref.from))
+			continue
+		case fi.Size() != d.Size:
+			problems = append(problems, fmt.Sprintf("%s: size %d expected %d, referenced by %s", d.Digest, fi.Size(), d.Size, ref.from))
+		}
+
+		// manifests and indexes reference further blobs
+		if seen[d.Digest] || !ociManifestType(d.MediaType) {
+			continue
+		}
+		seen[d.Digest] = true
+		content, err := os.ReadFile(blob)
+		if err == nil {
+			err = add(content, blob)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: unable to read manifest: %s", blob, err.Error()))
+		}
+	}
+
+	return checked, problems
+}
+
+// ociManifestType returns true for the media types of image manifests and indexes
+func ociManifestType(mediaType string) bool {
+	switch mediaType {
+	case "application/vnd.oci.image.manifest.v1+json",
+		"application/vnd.oci.image.index.v1+json",
+		"application/vnd.docker.distribution.manifest.v2+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json":
+		return true
+	}
+	return false
+}
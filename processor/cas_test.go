@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCasBlobFor(t *testing.T) {
+	digest := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	cases := []struct {
+		path   string
+		hash   string
+		digest string
+		git    bool
+	}{
+		{"image/blobs/sha256/" + digest, "sha256", digest, false},
+		{"registry/v2/blobs/sha256/b9/" + digest + "/data", "sha256", digest, false},
+		{"cache/sha256:" + digest, "sha256", digest, false},
+		{"store/sha256/b9/" + digest[2:], "sha256", digest, false},
+		{".git/objects/2a/ae6c35c94fcfb415dbe95f408b9ce91ee846ed", "sha1", "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed", true},
+		{"image/blobs/sha256/abc", "", "", false},
+		{"image/index.json", "", "", false},
+		{"notes/sha256/b9/readme.txt", "", "", false},
+	}
+
+	for _, c := range cases {
+		b, ok := casBlobFor(c.path)
+		if ok != (c.hash != "") || b.hash != c.hash || b.digest != c.digest || b.git != c.git {
+			t.Errorf("unexpected blob for %s: %v %v", c.path, b, ok)
+		}
+	}
+}
+
+func TestVerifyCAS(t *testing.T) {
+	t.Cleanup(resetState)
+	dir := t.TempDir()
+	write := func(path string, content []byte) {
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// an OCI layout with a manifest referencing a good layer and one which is missing
+	layer := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	missing := "sha256:" + strings.Repeat("0", 64)
+	manifest := []byte(`{"layers":[{"digest":"sha256:` + layer + `","size":11},{"digest":"` + missing + `","size":1}]}`)
+	sum := sha256.Sum256(manifest)
+	write(filepath.Join(dir, "oci", "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`))
+	write(filepath.Join(dir, "oci", "blobs", "sha256", layer), []byte("hello world"))
+	write(filepath.Join(dir, "oci", "blobs", "sha256", hex.EncodeToString(sum[:])), manifest)
+	write(filepath.Join(dir, "oci", "index.json"), []byte(`{"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:`+hex.EncodeToString(sum[:])+`","size":`+strconv.Itoa(len(manifest))+`}]}`))
+
+	// a git blob object for "hello world" which is stored compressed
+	var object bytes.Buffer
+	w := zlib.NewWriter(&object)
+	_, _ = w.Write([]byte("blob 11\x00hello world"))
+	_ = w.Close()
+	write(filepath.Join(dir, "objects", "95", "d09f2b10159347eece71399a7e2e907ea3df4f"), object.Bytes())
+
+	out, valid := verifyCAS([]string{dir})
+	if valid {
+		t.Errorf("expected the missing layer to fail\n%s", out)
+	}
+	for _, expected := range []string{
+		missing + ": missing, referenced by ",
+		"3 blobs checked, 0 failed, 3 references checked, 1 broken\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestVerifyOCILayoutInvalidDigest(t *testing.T) {
+	t.Cleanup(resetState)
+	dir := t.TempDir()
+	layout := filepath.Join(dir, "oci")
+	_ = os.MkdirAll(filepath.Join(layout, "blobs", "sha256"), 0755)
+	if err := os.WriteFile(filepath.Join(dir, "outside.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	index := `{"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"sha256:../../../outside.json","size":2},{"digest":"sha256","size":0}]}`
+	if err := os.WriteFile(filepath.Join(layout, "index.json"), []byte(index), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checked, problems := verifyOCILayout(layout)
+	if checked != 2 || len(problems) != 2 {
+		t.Fatalf("expected 2 broken references got %d %v", checked, problems)
+	}
+	for i, digest := range []string{"sha256:../../../outside.json", "sha256"} {
+		if !strings.HasPrefix(problems[i], digest+": invalid digest, referenced by ") {
+			t.Errorf("expected invalid digest for %s got %q", digest, problems[i])
+		}
+	}
+}
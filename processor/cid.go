@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// IPFS content identifiers calculated the same way as the default settings of
+// ipfs add, which chunks files into 256 KiB pieces and builds a balanced UnixFS
+// DAG with up to 174 links per node. CIDv0 wraps each chunk in a dag-pb node
+// while CIDv1 uses raw leaves as ipfs add --cid-version 1 does.
+const (
+	cidChunkSize = 262144
+	cidMaxLinks  = 174
+
+	cidCodecRaw   = 0x55
+	cidCodecDagPB = 0x70
+	cidSHA256     = 0x12
+)
+
+// cidNode is a node which has been added to the DAG and can be linked to
+type cidNode struct {
+	cid      []byte // binary CID
+	tsize    uint64 // size of the node plus everything it links to
+	filesize uint64 // bytes of the file below this node
+}
+
+// cidState builds the leaves as data is written keeping only the links
+// to them, as the rest of the tree is small enough to build when summed.
+// Sum returns the CID as text, base58 for v0 and base32 for v1.
+type cidState struct {
+	version int
+	chunk   []byte
+	leaves  []cidNode
+}
+
+func newCIDv0() *cidState {
+	return &cidState{version: 0}
+}
+
+func newCIDv1() *cidState {
+	return &cidState{version: 1}
+}
+
+func (c *cidState) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		take := min(cidChunkSize-len(c.chunk), len(p))
+		c.chunk = append(c.chunk, p[:take]...)
+		p = p[take:]
+
+		if len(c.chunk) == cidChunkSize {
+			c.leaves = append(c.leaves, c.leaf(c.chunk))
+			c.chunk = c.chunk[:0]
+		}
+	}
+	return written, nil
+}
+
+func (c *cidState) leaf(chunk []byte) cidNode {
+	if c.version == 1 {
+		return cidNode{cid: cidBytes(1, cidCodecRaw, chunk), tsize: uint64(len(chunk)), filesize: uint64(len(chunk))}
+	}
+
+	block := dagPBNode(nil, unixfsFile(chunk, uint64(len(chunk)), nil))
+	return cidNode{cid: cidBytes(0, cidCodecDagPB, block), tsize: uint64(len(block)), filesize: uint64(len(chunk))}
+}
+
+func (c *cidState) root() cidNode {
+	leaves := c.leaves
+	// an empty file is still a single empty chunk
+	if len(c.chunk) != 0 || len(leaves) == 0 {
+		leaves = append(leaves[:len(leaves):len(leaves)], c.leaf(c.chunk))
+	}
+
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	// work out how deep the tree needs to be to hold every leaf
+	depth, capacity := 1, cidMaxLinks
+	for capacity < len(leaves) {
+		depth++
+		capacity *= cidMaxLinks
+	}
+
+	return c.parent(leaves, depth)
+}
+
+// parent creates a node for the leaves at the given depth, where each
+// child is filled completely before moving onto the next
+func (c *cidState) parent(leaves []cidNode, depth int) cidNode {
+	var children []cidNode
+	if depth == 1 {
+		children = leaves
+	} else {
+		per := 1
+		for i := 1; i < depth; i++ {
+			per *= cidMaxLinks
+		}
+		for i := 0; i < len(leaves); i += per {
+			children = append(children, c.parent(leaves[i:min(i+per, len(leaves))], depth-1))
+		}
+	}
+
+	var filesize uint64
+	var tsize uint64
+	blocksizes := make([]uint64, 0, len(children))
+	for _, child := range children {
+		filesize += child.filesize
+		tsize += child.tsize
+		blocksizes = append(blocksizes, child.filesize)
+	}
+
+	block := dagPBNode(children, unixfsFile(nil, filesize, blocksizes))
+	return cidNode{cid: cidBytes(c.version, cidCodecDagPB, block), tsize: tsize + uint64(len(block)), filesize: filesize}
+}
+
+func (c *cidState) Sum(b []byte) []byte {
+	cid := c.root().cid
+	if c.version == 0 {
+		return append(b, base58.Encode(cid)...)
+	}
+	return append(b, "b"+strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(cid))...)
+}
+
+func (c *cidState) Reset() {
+	*c = cidState{version: c.version}
+}
+
+func (c *cidState) Size() int {
+	if c.version == 0 {
+		return 46
+	}
+	return 59
+}
+
+func (c *cidState) BlockSize() int {
+	return cidChunkSize
+}
+
+// cidBytes returns the binary CID of the block using a sha256 multihash
+func cidBytes(version int, codec uint64, block []byte) []byte {
+	sum := sha256.Sum256(block)
+	multihash := append([]byte{cidSHA256, sha256.Size}, sum[:]...)
+	if version == 0 {
+		return multihash
+	}
+
+	cid := binary.AppendUvarint(nil, 1)
+	cid = binary.AppendUvarint(cid, codec)
+	return append(cid, multihash...)
+}
+
+// unixfsFile encodes the UnixFS Data protobuf for a file node
+func unixfsFile(data []byte, filesize uint64, blocksizes []uint64) []byte {
+	var b []byte
+	b = protoVarint(b, 1, 2) // Type File
+	if len(data) != 0 {
+		b = protoBytes(b, 2, data)
+	}
+	b = protoVarint(b, 3, filesize)
+	for _, s := range blocksizes {
+		b = protoVarint(b, 4, s)
+	}
+	return b
+}
+
+// dagPBNode encodes a dag-pb PBNode which always writes the links before the data
+func dagPBNode(links []cidNode, data []byte) []byte {
+	var b []byte
+	for _, l := range links {
+		var link []byte
+		link = protoBytes(link, 1, l.cid)
+		link = protoBytes(link, 2, nil)
+		link = protoVarint(link, 3, l.tsize)
+		b = protoBytes(b, 2, link)
+	}
+	return protoBytes(b, 1, data)
+}
+
+func protoVarint(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3))
+	return binary.AppendUvarint(b, v)
+}
+
+func protoBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3|2))
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// compareFile is a file found below one of the directories being compared
+type compareFile struct {
+	rel  string // path relative to the directory
+	path string
+	size int64
+}
+
+// Compare walks both directories matching files by their relative path,
+// hashing only those where the sizes match, and reports any which are missing
+// from the second, extra in the second, differ or were moved
+func Compare(dirA string, dirB string) {
+	if err := prepareHashes(); err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	for _, dir := range []string{dirA, dirB} {
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			printError(fmt.Sprintf("%s is not a directory", dir))
+			os.Exit(1)
+		}
+	}
+	if len(copyHashes()) == 0 {
+		printError("at least one hash is required to compare files")
+		os.Exit(1)
+	}
+
+	var a, b map[string]compareFile
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		a = compareWalk(dirA)
+		wg.Done()
+	}()
+	go func() {
+		b = compareWalk(dirB)
+		wg.Done()
+	}()
+	wg.Wait()
+
+	result, valid := compareTrees(a, b)
+	fmt.Print(result)
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// compareWalk finds every file below the directory using the same ignore rules as hashing
+func compareWalk(dir string) map[string]compareFile {
+	found := make(chan string, FileListQueueSize)
+	go func() {
+		walkDirectoryWithIgnore(dir, found)
+		close(found)
+	}()
+
+	files := map[string]compareFile{}
+	for path := range found {
+		fi, err := os.Stat(path)
+		if err != nil {
+			printError(fmt.Sprintf("file or directory issue: %s %s", path, err.Error()))
+			continue
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+		files[rel] = compareFile{rel: rel, path: path, size: fi.Size()}
+	}
+	return files
+}
+
+func compareTrees(a map[string]compareFile, b map[string]compareFile) (string, bool) {
+	// only files which could be the same need to be hashed, which are those at
+	// the same path with the same size or missing and extra files of the same size
+	var same []string
+	var missing, extra []compareFile
+	differ := map[string]string{}
+	for _, rel := range sortedKeys(a) {
+		fb, ok := b[rel]
+		switch {
+		case !ok:
+			missing = append(missing, a[rel])
+		case a[rel].size != fb.size:
+			differ[rel] = fmt.Sprintf("\tbytes %d -> %d\n", a[rel].size, fb.size)
+		default:
+			same = append(same, rel)
+		}
+	}
+	for _, rel := range sortedKeys(b) {
+		if _, ok := a[rel]; !ok {
+			extra = append(extra, b[rel])
+		}
+	}
+
+	missingSizes := map[int64]bool{}
+	for _, f := range missing {
+		missingSizes[f.size] = true
+	}
+	extraSizes := map[int64]bool{}
+	for _, f := range extra {
+		extraSizes[f.size] = true
+	}
+	files := make(chan string, FileListQueueSize)
+	go func() {
+		for _, rel := range same {
+			files <- a[rel].path
+			files <- b[rel].path
+		}
+		for _, f := range missing {
+			if extraSizes[f.size] {
+				files <- f.path
+			}
+		}
+		for _, f := range extra {
+			if missingSizes[f.size] {
+				files <- f.path
+			}
+		}
+		close(files)
+	}()
+
+	var str strings.Builder
+	valid := true
+	results, err := hashFiles(files)
+	if err != nil {
+		printError(err.Error())
+		valid = false
+	}
+	digests := map[string]Result{}
+	for _, res := range results {
+		digests[res.File] = res
+	}
+	// the key is empty when any digest is missing so the file is never treated as a match
+	key := func(path string) string {
+		res, ok := digests[path]
+		if !ok {
+			return ""
+		}
+		var sb strings.Builder
+		for _, h := range copyHashes() {
+			d := res.Digest(h)
+			if d == "" {
+				return ""
+			}
+			sb.WriteString(d)
+			sb.WriteString(",")
+		}
+		return sb.String()
+	}
+
+	for _, rel := range same {
+		ka, kb := key(a[rel].path), key(b[rel].path)
+		if ka == "" || ka != kb {
+			differ[rel] = compareDetails(digests[a[rel].path], digests[b[rel].path])
+		}
+	}
+
+	// a missing file with the same content as an extra one was moved
+	extraByKey := map[string][]compareFile{}
+	for _, f := range extra {
+		if k := key(f.path); k != "" {
+			extraByKey[k] = append(extraByKey[k], f)
+		}
+	}
+	moved := map[string]string{}
+	var stillMissing []compareFile
+	for _, f := range missing {
+		k := key(f.path)
+		if candidates := extraByKey[k]; k != "" && len(candidates) != 0 {
+			moved[f.rel] = candidates[0].rel
+			extraByKey[k] = candidates[1:]
+			continue
+		}
+		stillMissing = append(stillMissing, f)
+	}
+	movedTo := map[string]bool{}
+	for _, to := range moved {
+		movedTo[to] = true
+	}
+
+	for _, f := range stillMissing {
+		str.WriteString(fmt.Sprintf("missing: %s\n", f.rel))
+	}
+	extraCount := 0
+	for _, f := range extra {
+		if !movedTo[f.rel] {
+			str.WriteString(fmt.Sprintf("extra: %s\n", f.rel))
+			extraCount++
+		}
+	}
+	for _, rel := range sortedKeys(differ) {
+		str.WriteString(fmt.Sprintf("differ: %s\n", rel))
+		str.WriteString(differ[rel])
+	}
+	for _, from := range sortedKeys(moved) {
+		str.WriteString(fmt.Sprintf("moved: %s -> %s\n", from, moved[from]))
+	}
+
+	if len(stillMissing)+extraCount+len(differ)+len(moved) != 0 {
+		valid = false
+	}
+	str.WriteString(fmt.Sprintf("%d files compared, %d missing, %d extra, %d differ, %d moved\n", len(a), len(stillMissing), extraCount, len(differ), len(moved)))
+	return str.String(), valid
+}
+
+// compareDetails writes the digests of the first and second file which differ
+func compareDetails(a Result, b Result) string {
+	var str strings.Builder
+	for _, h := range copyHashes() {
+		if da, db := a.Digest(h), b.Digest(h); da == "" || da != db {
+			str.WriteString(fmt.Sprintf("\t%s %s -> %s\n", h, da, db))
+		}
+	}
+	return str.String()
+}
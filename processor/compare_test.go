@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareTrees(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	dirA, dirB := t.TempDir(), t.TempDir()
+	write := func(dir, name, content string) {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(dirA, "same.txt", "same")
+	write(dirB, "same.txt", "same")
+	write(dirA, "size.txt", "short")
+	write(dirB, "size.txt", "longer")
+	write(dirA, "content.txt", "aaaa")
+	write(dirB, "content.txt", "bbbb")
+	write(dirA, "old/name.txt", "moved")
+	write(dirB, "new/name.txt", "moved")
+	write(dirA, "gone.txt", "gone")
+	write(dirB, "extra.txt", "extra")
+
+	out, valid := compareTrees(compareWalk(dirA), compareWalk(dirB))
+	if valid {
+		t.Error("expected differences")
+	}
+	for _, expected := range []string{
+		"missing: gone.txt\n",
+		"extra: extra.txt\n",
+		"differ: size.txt\n\tbytes 5 -> 6\n",
+		"differ: content.txt\n\tsha256 ",
+		"moved: old/name.txt -> new/name.txt\n",
+		"5 files compared, 1 missing, 1 extra, 2 differ, 1 moved\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+
+	out, valid = compareTrees(compareWalk(dirA), compareWalk(dirA))
+	if !valid || !strings.HasSuffix(out, "0 missing, 0 extra, 0 differ, 0 moved\n") {
+		t.Errorf("expected no differences got\n%s", out)
+	}
+}
+
+func TestCompareTreesAllHashes(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"all"}
+	dirA, dirB := t.TempDir(), t.TempDir()
+	content := strings.Repeat("the quick brown fox jumps over the lazy dog ", 10)
+	for _, dir := range []string{dirA, dirB} {
+		if err := os.WriteFile(filepath.Join(dir, "same.txt"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.WriteFile(filepath.Join(dirA, "content.txt"), []byte(content+"a"), 0600)
+	_ = os.WriteFile(filepath.Join(dirB, "content.txt"), []byte(content+"b"), 0600)
+
+	out, valid := compareTrees(compareWalk(dirA), compareWalk(dirB))
+	if valid || !strings.Contains(out, "differ: content.txt\n") || strings.Contains(out, "differ: same.txt") {
+		t.Errorf("expected only content.txt to differ got\n%s", out)
+	}
+}
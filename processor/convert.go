@@ -0,0 +1,428 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boyter/hashit/processor/database"
+)
+
+// convertFormats are the manifests convert can read
+var convertFormats = []string{"hashdeep", "json", "sum", "sqlite", "dfxml", "aide"}
+
+// Convert reads an existing manifest and writes it using another output format
+// without reading any of the files it lists
+func Convert(path string) {
+	to := strings.ToLower(ConvertTo)
+	if !contains(outputFormats, to) {
+		printError(fmt.Sprintf("unknown output format %s, must be one of %s", ConvertTo, strings.Join(outputFormats, ", ")))
+		os.Exit(1)
+	}
+
+	results, _, err := readManifest(path, strings.ToLower(ConvertFrom))
+	if err != nil {
+		printError(fmt.Sprintf("unable to read %s: %s", path, err.Error()))
+		os.Exit(1)
+	}
+
+	// only the hashes in the manifest can be written as nothing is hashed
+	Hash = []string{}
+	for _, h := range allHashes {
+		for _, res := range results {
+			if res.Digest(h) != "" {
+				Hash = append(Hash, h)
+				break
+			}
+		}
+	}
+	for _, res := range results {
+		if res.MTime != nil {
+			MTime = true
+		}
+	}
+	if len(Hash) == 0 {
+		printError(fmt.Sprintf("%s does not contain any supported hashes", path))
+		os.Exit(1)
+	}
+	convertEncoding(results)
+
+	if tabularFormat(to) {
+		if err := checkColumns(); err != nil {
+			printError(err.Error())
+			os.Exit(1)
+		}
+	}
+	if to == "sqlite" && FileOutput == "" {
+		FileOutput = "hashit.db"
+	}
+
+	input := make(chan Result, len(results))
+	for _, res := range results {
+		input <- res
+	}
+	close(input)
+
+	result, valid := formatResults(to, FileOutput, input)
+	if FileOutput == "" {
+		fmt.Print(result)
+	} else {
+		if to != "sqlite" {
+			_ = os.WriteFile(FileOutput, []byte(result), 0600)
+		}
+		fmt.Println("results written to " + FileOutput)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// readManifest parses the manifest into results, working out its format from
+// the contents when from is empty, and returns the format it was read as.
+// Every digest is normalised to hex.
+func readManifest(path string, from string) ([]Result, string, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// AIDE databases are usually gzipped
+	if bytes.HasPrefix(b, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, "", err
+		}
+		if b, err = io.ReadAll(r); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if from == "" {
+		from = detectManifest(b)
+	}
+
+	hdl := &Auditor{lengths: map[string]int{}}
+	var records map[string]AuditRecord
+	switch from {
+	case "hashdeep":
+		records, err = hdl.parseHashdeepFile(string(b))
+	case "dfxml":
+		records, err = hdl.parseDFXMLFile(string(b))
+	case "aide":
+		records, err = hdl.parseAIDEFile(string(b))
+	case "json":
+		results, err := readJSONManifest(hdl, b)
+		return results, from, err
+	case "sum":
+		results, err := readSumManifest(hdl, b)
+		return results, from, err
+	case "sqlite":
+		if path == "-" {
+			return nil, "", errors.New("sqlite databases cannot be read from standard input")
+		}
+		results, err := readSqliteManifest(hdl, path)
+		return results, from, err
+	default:
+		return nil, "", fmt.Errorf("unknown manifest format %s, must be one of %s", from, strings.Join(convertFormats, ", "))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	results := make([]Result, 0, len(records))
+	for _, r := range records {
+		results = append(results, recordResult(r))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].File < results[j].File
+	})
+	return results, from, nil
+}
+
+// manifestSizes returns true if the format records the size of each file
+func manifestSizes(format string) bool {
+	return format != "sum"
+}
+
+// detectManifest works out the format of a manifest from how it starts
+func detectManifest(b []byte) string {
+	trimmed := strings.TrimSpace(string(b[:min(len(b), 1024)]))
+	switch {
+	case bytes.HasPrefix(b, []byte("SQLite format 3\x00")):
+		return "sqlite"
+	case strings.HasPrefix(trimmed, "<"):
+		return "dfxml"
+	case strings.HasPrefix(trimmed, "["):
+		return "json"
+	case strings.HasPrefix(trimmed, "@@begin_db"):
+		return "aide"
+	case strings.HasPrefix(trimmed, "%%%% HASHDEEP"):
+		return "hashdeep"
+	}
+	return "sum"
+}
+
+func recordResult(r AuditRecord) Result {
+	size, _ := strconv.ParseInt(r.Size, 10, 64)
+	res := Result{File: r.Filename, Bytes: size}
+	for name, digest := range r.Hashes {
+		res.setDigest(name, digest)
+	}
+	for _, b := range r.Blocks {
+		res.Blocks = append(res.Blocks, Block{Offset: b.Offset, Result: recordResult(b)})
+	}
+	return res
+}
+
+// normalizeResult converts every digest of the result to hex
+func normalizeResult(hdl *Auditor, res *Result) {
+	for _, h := range allHashes {
+		if digest := res.Digest(h); digest != "" && !textHashes[h] {
+			res.setDigest(h, hdl.normalizeDigest(h, digest))
+		}
+	}
+}
+
+// readJSONManifest reads the output of the json format
+func readJSONManifest(hdl *Auditor, b []byte) ([]Result, error) {
+	var results []Result
+	if err := json.Unmarshal(b, &results); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		normalizeResult(hdl, &results[i])
+		for j := range results[i].Blocks {
+			normalizeResult(hdl, &results[i].Blocks[j].Result)
+		}
+	}
+	return results, nil
+}
+
+// readSumManifest reads the output of sha256sum and similar tools including
+// the BSD form, merging the lines for the same file such as from the sum format.
+// Lines without a hash name could be any hash of the same length such as sha256
+// or blake3, so the hash must be one of SumHashes.
+func readSumManifest(hdl *Auditor, b []byte) ([]Result, error) {
+	type sumHash struct {
+		name string
+		size int // size of the digest in bytes, or 0 for any length
+	}
+	var untagged []sumHash
+	for _, h := range SumHashes {
+		name, bits, err := parseHashLength(strings.ToLower(h))
+		if err != nil {
+			return nil, err
+		}
+		if HashNames.Digest(name) != name || textHashes[name] {
+			return nil, fmt.Errorf("%s cannot be used for lines without a hash name", h)
+		}
+		size := digestSizes[name]
+		if bits != 0 {
+			size = bits / 8
+			hdl.lengths[name] = bits
+		}
+		untagged = append(untagged, sumHash{name: name, size: size})
+	}
+
+	var results []Result
+	index := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		digest, name, hash, ok := parseSumLine(line)
+		if !ok {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		guessed := hash == ""
+		switch hash {
+		case "":
+			if len(untagged) == 0 {
+				return nil, fmt.Errorf("ambiguous untagged digest on line %q, name the hash using --hash", line)
+			}
+			raw, err := hex.DecodeString(digest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid digest on line %q", line)
+			}
+			var matches []string
+			for _, h := range untagged {
+				if h.size == 0 || h.size == len(raw) {
+					matches = append(matches, h.name)
+				}
+			}
+			switch len(matches) {
+			case 0:
+				return nil, fmt.Errorf("untagged digest on line %q is not the length of any hash set using --hash", line)
+			case 1:
+			default:
+				return nil, fmt.Errorf("ambiguous untagged digest on line %q, it could be any of %s so name only one using --hash", line, strings.Join(matches, ", "))
+			}
+			hash = matches[0]
+		case "blake2b":
+			hash = HashNames.Blake2b512
+		}
+		if HashNames.Digest(hash) != hash {
+			return nil, fmt.Errorf("unknown hash for line %q", line)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(results)
+			index[name] = i
+			results = append(results, Result{File: name})
+		}
+		// hashes guessed from the length of the digest such as sha256 and blake3
+		// cannot be told apart so a second one for the same file is an error
+		if guessed && results[i].Digest(hash) != "" {
+			return nil, fmt.Errorf("ambiguous untagged digest for %s on line %q, %s is already set so use tagged lines", name, line, hash)
+		}
+		results[i].setDigest(hash, hdl.normalizeDigest(hash, digest))
+	}
+	return results, scanner.Err()
+}
+
+// readSqliteManifest reads the database written by the sqlite format, opening
+// it read only so it is never changed
+func readSqliteManifest(hdl *Auditor, path string) ([]Result, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// the database is never changed, so a database written by an older version
+	// is read through a temporary view giving any missing columns as null
+	missing, err := sqliteMissingColumns(db, "file_hashes")
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) != 0 {
+		db.SetMaxOpenConns(1) // temporary views only exist on the connection which made them
+		columns := "*"
+		for _, c := range missing {
+			columns += ", null as " + c.name
+		}
+		if _, err := db.Exec("create temp view file_hashes as select " + columns + " from main.file_hashes"); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := database.New(db).FileHashList(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(rows))
+	for _, row := range rows {
+		res := sqliteResult(row)
+		normalizeResult(hdl, &res)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func sqliteResult(row database.FileHash) Result {
+	res := Result{
+		File:        row.Filepath,
+		CRC32:       row.Crc32.String,
+		XxHash64:    row.Xxhash64.String,
+		CRC32C:      row.Crc32c.String,
+		CRC64ECMA:   row.Crc64Ecma.String,
+		CRC64ISO:    row.Crc64Iso.String,
+		Adler32:     row.Adler32.String,
+		XXH3:        row.Xxh3.String,
+		XXH128:      row.Xxh128.String,
+		Murmur3:     row.Murmur3.String,
+		SipHash:     row.Siphash.String,
+		HighwayHash: row.Highwayhash.String,
+		MD4:         row.Md4.String,
+		MD5:         row.Md5.String,
+		SHA1:        row.Sha1.String,
+		SHA256:      row.Sha256.String,
+		SHA384:      row.Sha384.String,
+		SHA512:      row.Sha512.String,
+		Blake2b256:  row.Blake2b256.String,
+		Blake2b512:  row.Blake2b512.String,
+		Blake3:      row.Blake3.String,
+		Sha3224:     row.Sha3224.String,
+		Sha3256:     row.Sha3256.String,
+		Sha3384:     row.Sha3384.String,
+		Sha3512:     row.Sha3512.String,
+		Shake128:    row.Shake128.String,
+		Shake256:    row.Shake256.String,
+		CShake128:   row.Cshake128.String,
+		CShake256:   row.Cshake256.String,
+		Ed2k:        row.Ed2k.String,
+		AICH:        row.Aich.String,
+		TTH:         row.Tth.String,
+		CIDv0:       row.Cidv0.String,
+		CIDv1:       row.Cidv1.String,
+		GitSHA1:     row.GitSha1.String,
+		GitSHA256:   row.GitSha256.String,
+		SSDeep:      row.Ssdeep.String,
+		TLSH:        row.Tlsh.String,
+		Bytes:       row.Size,
+	}
+
+	switch mtime := row.Mtime.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, mtime); err == nil && !t.IsZero() {
+			res.MTime = &t
+		}
+	case time.Time:
+		if !mtime.IsZero() {
+			res.MTime = &mtime
+		}
+	}
+	return res
+}
+
+// convertEncoding writes the hex digests using the selected encoding
+func convertEncoding(results []Result) {
+	if strings.ToLower(Encoding) == "hex" {
+		return
+	}
+	encode := func(res *Result) {
+		for _, h := range Hash {
+			if textHashes[h] {
+				continue
+			}
+			if b, err := hex.DecodeString(res.Digest(h)); err == nil {
+				res.setDigest(h, encodeDigest(b))
+			}
+		}
+	}
+	for i := range results {
+		encode(&results[i])
+		for j := range results[i].Blocks {
+			encode(&results[i].Blocks[j].Result)
+		}
+	}
+}
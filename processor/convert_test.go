@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectManifest(t *testing.T) {
+	cases := map[string]string{
+		"%%%% HASHDEEP-1.0\n%%%% size,md5,filename\n": "hashdeep",
+		"<?xml version='1.0'?>\n<dfxml>":              "dfxml",
+		" [{\"File\":\"a\"}]":                         "json",
+		"@@begin_db\n":                                "aide",
+		"SQLite format 3\x00":                         "sqlite",
+		"d41d8cd98f00b204e9800998ecf8427e  a\n":       "sum",
+	}
+	for input, expected := range cases {
+		if got := detectManifest([]byte(input)); got != expected {
+			t.Errorf("expected %s for %q got %s", expected, input, got)
+		}
+	}
+}
+
+func TestReadSumManifest(t *testing.T) {
+	SumHashes = []string{"md5", "SHA256"}
+	t.Cleanup(func() { SumHashes = nil })
+	input := "d41d8cd98f00b204e9800998ecf8427e  empty.txt\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n" +
+		"SHA1 (other file.txt) = DA39A3EE5E6B4B0D3255BFEF95601890AFD80709\n"
+
+	results, err := readSumManifest(&Auditor{lengths: map[string]int{}}, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results got %d", len(results))
+	}
+	if results[0].MD5 != "d41d8cd98f00b204e9800998ecf8427e" || results[0].SHA256 != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("unexpected digests %v", results[0])
+	}
+	if results[1].File != "other file.txt" || results[1].SHA1 != "da39a3ee5e6b4b0d3255bfef95601890afd80709" {
+		t.Errorf("unexpected result %v", results[1])
+	}
+
+	if _, err := readSumManifest(&Auditor{lengths: map[string]int{}}, []byte("abc  a\n")); err == nil {
+		t.Error("expected error for a digest of unknown length")
+	}
+
+	// the same hash twice for a file means the lines are not what they were named as
+	twice := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n" +
+		"af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262  empty.txt\n"
+	if _, err := readSumManifest(&Auditor{lengths: map[string]int{}}, []byte(twice)); err == nil || !strings.Contains(err.Error(), "ambiguous untagged digest") {
+		t.Errorf("expected an ambiguous digest error got %v", err)
+	}
+
+	// the hash of an untagged line is never guessed from its length
+	blake3 := "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262  empty.txt\n"
+	for _, hashes := range [][]string{nil, {"sha256", "blake3"}} {
+		SumHashes = hashes
+		if _, err := readSumManifest(&Auditor{lengths: map[string]int{}}, []byte(blake3)); err == nil || !strings.Contains(err.Error(), "ambiguous untagged digest") {
+			t.Errorf("expected an ambiguous digest error for %v got %v", hashes, err)
+		}
+	}
+	SumHashes = []string{"blake3"}
+	results, err = readSumManifest(&Auditor{lengths: map[string]int{}}, []byte(blake3))
+	if err != nil || len(results) != 1 || results[0].Blake3 != "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262" || results[0].SHA256 != "" {
+		t.Errorf("expected the named hash to be used got %v %v", results, err)
+	}
+
+	// tagged lines never need the hash to be named
+	SumHashes = nil
+	if _, err := readSumManifest(&Auditor{lengths: map[string]int{}}, []byte("SHA1 (a) = da39a3ee5e6b4b0d3255bfef95601890afd80709\n")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadManifestHashdeep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.txt")
+	audit := "%%%% HASHDEEP-1.0\n%%%% size,md5,sha256,filename\n" +
+		"0,d41d8cd98f00b204e9800998ecf8427e,e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855,b.txt\n" +
+		"0,d41d8cd98f00b204e9800998ecf8427e,e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855,a.txt\n"
+	if err := os.WriteFile(path, []byte(audit), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _, err := readManifest(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].File != "a.txt" || results[1].File != "b.txt" {
+		t.Fatalf("expected results sorted by file got %v", results)
+	}
+	if results[0].MD5 != "d41d8cd98f00b204e9800998ecf8427e" || results[0].SHA1 != "" {
+		t.Errorf("unexpected digests %v", results[0])
+	}
+}
+
+func TestSqliteUpgrade(t *testing.T) {
+	t.Cleanup(resetState)
+	// the schema written by releases before more hashes were added
+	path := filepath.Join(t.TempDir(), "old.db")
+	old, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(`create table file_hashes (filepath text primary key, crc32 text, xxhash64 text, md4 text, md5 text, sha1 text,
+sha256 text, sha512 text, blake2b_256 text, blake2b_512 text, blake3 text, sha3_224 text, sha3_256 text, sha3_384 text,
+sha3_512 text, ed2k text, size integer not null, mtime text null);
+insert into file_hashes (filepath, md5, size) values ('old.txt', 'd41d8cd98f00b204e9800998ecf8427e', 0);`)
+	_ = old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reading never changes the database
+	results, _, err := readManifest(path, "sqlite")
+	if err != nil || len(results) != 1 || results[0].MD5 != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Fatalf("unexpected results %v %v", results, err)
+	}
+
+	input := make(chan Result, 1)
+	input <- Result{File: "new.txt", CRC32C: "e3069283", TLSH: "T1", Bytes: 9}
+	close(input)
+	if _, valid := toSqlite(input, path); !valid {
+		t.Fatal("expected the old database to be upgraded and written to")
+	}
+	results, _, err = readManifest(path, "sqlite")
+	if err != nil || len(results) != 2 || results[0].CRC32C != "e3069283" || results[0].TLSH != "T1" {
+		t.Errorf("unexpected results %v %v", results, err)
+	}
+}
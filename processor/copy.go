@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// copyJob is a single file to copy
+type copyJob struct {
+	src string
+	dst string
+}
+
+// Copy copies the file, or the contents of the directory, from src into dst
+// hashing each file as it is read and then reading the copy back to verify it,
+// writing the results for the copies using the selected output format
+func Copy(src string, dst string) {
+	if err := prepareHashes(); err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	hashes, err := sinkHashes(strings.ToLower(Format), Hash)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	Hash = hashes
+	if len(copyHashes()) == 0 {
+		printError("at least one hash is required to verify the copy")
+		os.Exit(1)
+	}
+
+	fi, err := os.Stat(src)
+	if err != nil {
+		printError(fmt.Sprintf("file or directory issue: %s %s", src, err.Error()))
+		os.Exit(1)
+	}
+
+	jobs := make(chan copyJob, FileListQueueSize)
+	go func() {
+		if !fi.IsDir() {
+			target := dst
+			if d, err := os.Stat(dst); err == nil && d.IsDir() {
+				target = filepath.Join(dst, filepath.Base(src))
+			}
+			jobs <- copyJob{src: src, dst: target}
+			close(jobs)
+			return
+		}
+
+		found := make(chan string, FileListQueueSize)
+		go func() {
+			walkDirectoryWithIgnore(src, found)
+			close(found)
+		}()
+		for p := range found {
+			rel, err := filepath.Rel(src, p)
+			if err != nil {
+				printError(err.Error())
+				continue
+			}
+			jobs <- copyJob{src: p, dst: filepath.Join(dst, rel)}
+		}
+		close(jobs)
+	}()
+
+	results := make(chan Result, FileListQueueSize)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	for i := 0; i < NoThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res, err := copyFile(j.src, j.dst)
+				if err != nil {
+					printError(err.Error())
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+				printVerbose(fmt.Sprintf("copied and verified %s to %s", j.src, j.dst))
+				results <- res
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if strings.ToLower(Format) == "sqlite" && FileOutput == "" {
+		FileOutput = "hashit.db"
+	}
+	result, valid := formatResults(Format, FileOutput, results)
+	if FileOutput == "" {
+		fmt.Print(result)
+	} else {
+		if strings.ToLower(Format) != "sqlite" {
+			_ = os.WriteFile(FileOutput, []byte(result), 0600)
+		}
+		fmt.Println("results written to " + FileOutput)
+	}
+
+	if failed != 0 {
+		printError(fmt.Sprintf("%d files failed to copy or verify", failed))
+		os.Exit(1)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// copyHashes returns the hashes being calculated which the copy is verified with
+func copyHashes() []string {
+	return enabledHashes()
+}
+
+// copyFile copies a single file refusing to replace an existing one, hashing
+// the source as it is copied and the destination once it is on disk
+func copyFile(src string, dst string) (Result, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return Result{}, err
+	}
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return Result{}, err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return Result{}, err
+	}
+
+	res, err := processReader(dst, io.TeeReader(in, out))
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = fmt.Errorf("unable to copy %s to %s: %s", src, dst, err.Error())
+	} else {
+		_ = os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+		err = verifyCopy(dst, res)
+	}
+	if err != nil {
+		// remove the partial or corrupt copy so running the copy again does not fail as it exists
+		_ = os.Remove(dst)
+		return Result{}, err
+	}
+
+	if MTime {
+		mtime := fi.ModTime()
+		res.MTime = &mtime
+	}
+	return res, nil
+}
+
+// verifyCopy reads the copy back from the disk rather than the page cache where
+// possible and checks it matches what was hashed as it was written
+func verifyCopy(dst string, res Result) error {
+	f, err := os.Open(dst)
+	if err != nil {
+		return err
+	}
+	dropCache(f)
+	check, err := processReader(dst, f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("unable to verify %s: %s", dst, err.Error())
+	}
+
+	if check.Bytes != res.Bytes {
+		return fmt.Errorf("verification failed for %s: copied %d bytes but read back %d", dst, res.Bytes, check.Bytes)
+	}
+	for _, h := range copyHashes() {
+		if check.Digest(h) != res.Digest(h) {
+			return fmt.Errorf("verification failed for %s: %s %s does not match %s", dst, h, check.Digest(h), res.Digest(h))
+		}
+	}
+	return nil
+}
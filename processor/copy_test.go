@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyFile(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5", "sha256"}
+	Encoding = "hex"
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("hello world"), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "copy", "dst.txt")
+	res, err := copyFile(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.File != dst || res.Bytes != 11 || res.MD5 != "5eb63bbbe01eeed093cb22bb8f5acdc3" || res.SHA256 != "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9" {
+		t.Errorf("unexpected result %v", res)
+	}
+
+	b, err := os.ReadFile(dst)
+	if err != nil || string(b) != "hello world" {
+		t.Errorf("expected copy to match got %q %v", b, err)
+	}
+
+	// existing files are never replaced
+	if _, err := copyFile(src, dst); err == nil {
+		t.Error("expected error copying over an existing file")
+	}
+}
+
+func TestCopyFileRemovesFailedCopy(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	Encoding = "hex"
+	dir := t.TempDir()
+
+	// reading a directory fails after the destination has been created
+	dst := filepath.Join(dir, "dst.txt")
+	if _, err := copyFile(dir, dst); err == nil {
+		t.Error("expected error copying a directory")
+	}
+	if _, err := os.Lstat(dst); !os.IsNotExist(err) {
+		t.Errorf("expected failed copy to be removed got %v", err)
+	}
+
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("hello world"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	res, err := copyFile(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("hello there"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyCopy(dst, res); err == nil || !strings.Contains(err.Error(), "verification failed for "+dst+": sha256") {
+		t.Errorf("expected verification failure got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tabularFormat returns true for the formats which use --columns
+func tabularFormat(format string) bool {
+	format = strings.ToLower(format)
+	return format == "csv" || format == "tsv"
+}
+
+// csvColumns returns the columns to write, which when not set using --columns
+// are the file, its size, the mtime if enabled and then every enabled hash
+func csvColumns() []string {
+	if len(Columns) != 0 {
+		var columns []string
+		for _, c := range Columns {
+			columns = append(columns, strings.ToLower(strings.TrimSpace(c)))
+		}
+		return columns
+	}
+
+	columns := []string{"file", "bytes"}
+	if MTime {
+		columns = append(columns, "mtime")
+	}
+	for _, h := range allHashes {
+		if hasHash(h) {
+			columns = append(columns, h)
+		}
+	}
+	return columns
+}
+
+// checkColumns makes sure every column is known, enabling any hashes and the
+// mtime so they are calculated
+func checkColumns() error {
+	for _, c := range csvColumns() {
+		switch c {
+		case "file", "bytes":
+		case "mtime":
+			MTime = true
+		default:
+			if HashNames.Digest(c) != c {
+				return fmt.Errorf("unknown column %s, must be file, bytes, mtime or a hash", c)
+			}
+			if !hasHash(c) {
+				Hash = append(Hash, c)
+			}
+		}
+	}
+	return nil
+}
+
+// toCSV writes a header row and then a row for each result quoted as per RFC 4180,
+// using tabs rather than commas for tsv
+func toCSV(input chan Result, format string, output string) (string, bool) {
+	var str strings.Builder
+	w := csv.NewWriter(&str)
+	if strings.ToLower(format) == "tsv" {
+		w.Comma = '\t'
+	}
+
+	columns := csvColumns()
+	_ = w.Write(columns)
+
+	for res := range input {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			switch c {
+			case "file":
+				row = append(row, res.File)
+			case "bytes":
+				row = append(row, strconv.FormatInt(res.Bytes, 10))
+			case "mtime":
+				mtime := ""
+				if res.MTime != nil {
+					mtime = res.MTime.UTC().Format(time.RFC3339)
+				}
+				row = append(row, mtime)
+			default:
+				row = append(row, res.Digest(c))
+			}
+		}
+		_ = w.Write(row)
+
+		if !NoStream && output == "" {
+			w.Flush()
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		printError(fmt.Sprintf("unable to write %s: %s", format, err.Error()))
+		return "", false
+	}
+	return str.String(), true
+}
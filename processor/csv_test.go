@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import "testing"
+
+func TestToCSV(t *testing.T) {
+	t.Cleanup(resetState)
+	Format = "csv"
+	Columns = []string{"file", "bytes", "md5"}
+	Hash = []string{}
+	NoStream = true
+	defer func() {
+		Format = ""
+		Columns = []string{}
+		NoStream = false
+	}()
+
+	if err := checkColumns(); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if !hasHash("md5") {
+		t.Error("expected the md5 column to enable md5")
+	}
+
+	input := make(chan Result, 1)
+	input <- Result{File: `we"ird, name.txt`, Bytes: 6, MD5: "b1946ac92492d2347c6235b4d2611184"}
+	close(input)
+
+	out, ok := toCSV(input, "csv", "")
+	expected := "file,bytes,md5\n\"we\"\"ird, name.txt\",6,b1946ac92492d2347c6235b4d2611184\n"
+	if !ok || out != expected {
+		t.Errorf("expected %q got %q", expected, out)
+	}
+}
+
+func TestCheckColumnsUnknown(t *testing.T) {
+	Columns = []string{"file", "nope"}
+	defer func() { Columns = []string{} }()
+
+	if err := checkColumns(); err == nil {
+		t.Error("expected error")
+	}
+}
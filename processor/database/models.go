@@ -8,23 +8,88 @@ import (
 	"database/sql"
 )
 
+type FileBlock struct {
+	Filepath    string
+	ByteOffset  int64
+	Crc32       sql.NullString
+	Xxhash64    sql.NullString
+	Crc32c      sql.NullString
+	Crc64Ecma   sql.NullString
+	Crc64Iso    sql.NullString
+	Adler32     sql.NullString
+	Xxh3        sql.NullString
+	Xxh128      sql.NullString
+	Murmur3     sql.NullString
+	Siphash     sql.NullString
+	Highwayhash sql.NullString
+	Md4         sql.NullString
+	Md5         sql.NullString
+	Sha1        sql.NullString
+	Sha256      sql.NullString
+	Sha384      sql.NullString
+	Sha512      sql.NullString
+	Blake2b256  sql.NullString
+	Blake2b512  sql.NullString
+	Blake3      sql.NullString
+	Sha3224     sql.NullString
+	Sha3256     sql.NullString
+	Sha3384     sql.NullString
+	Sha3512     sql.NullString
+	Shake128    sql.NullString
+	Shake256    sql.NullString
+	Cshake128   sql.NullString
+	Cshake256   sql.NullString
+	Ed2k        sql.NullString
+	Aich        sql.NullString
+	Tth         sql.NullString
+	Cidv0       sql.NullString
+	Cidv1       sql.NullString
+	GitSha1     sql.NullString
+	GitSha256   sql.NullString
+	Ssdeep      sql.NullString
+	Tlsh        sql.NullString
+	Size        int64
+}
+
 type FileHash struct {
-	Filepath   string
-	Crc32      sql.NullString
-	Xxhash64   sql.NullString
-	Md4        sql.NullString
-	Md5        sql.NullString
-	Sha1       sql.NullString
-	Sha256     sql.NullString
-	Sha512     sql.NullString
-	Blake2b256 sql.NullString
-	Blake2b512 sql.NullString
-	Blake3     sql.NullString
-	Sha3224    sql.NullString
-	Sha3256    sql.NullString
-	Sha3384    sql.NullString
-	Sha3512    sql.NullString
-	Ed2k       sql.NullString
-	Size       int64
-	Mtime      interface{}
+	Filepath    string
+	Crc32       sql.NullString
+	Xxhash64    sql.NullString
+	Crc32c      sql.NullString
+	Crc64Ecma   sql.NullString
+	Crc64Iso    sql.NullString
+	Adler32     sql.NullString
+	Xxh3        sql.NullString
+	Xxh128      sql.NullString
+	Murmur3     sql.NullString
+	Siphash     sql.NullString
+	Highwayhash sql.NullString
+	Md4         sql.NullString
+	Md5         sql.NullString
+	Sha1        sql.NullString
+	Sha256      sql.NullString
+	Sha384      sql.NullString
+	Sha512      sql.NullString
+	Blake2b256  sql.NullString
+	Blake2b512  sql.NullString
+	Blake3      sql.NullString
+	Sha3224     sql.NullString
+	Sha3256     sql.NullString
+	Sha3384     sql.NullString
+	Sha3512     sql.NullString
+	Shake128    sql.NullString
+	Shake256    sql.NullString
+	Cshake128   sql.NullString
+	Cshake256   sql.NullString
+	Ed2k        sql.NullString
+	Aich        sql.NullString
+	Tth         sql.NullString
+	Cidv0       sql.NullString
+	Cidv1       sql.NullString
+	GitSha1     sql.NullString
+	GitSha256   sql.NullString
+	Ssdeep      sql.NullString
+	Tlsh        sql.NullString
+	Size        int64
+	Mtime       interface{}
 }
@@ -10,8 +10,117 @@ import (
 	"database/sql"
 )
 
+const fileBlockDeleteByFilePath = `-- name: FileBlockDeleteByFilePath :exec
+delete from file_blocks where filepath = ?
+`
+
+func (q *Queries) FileBlockDeleteByFilePath(ctx context.Context, filepath string) error {
+	_, err := q.db.ExecContext(ctx, fileBlockDeleteByFilePath, filepath)
+	return err
+}
+
+const fileBlockInsertReplace = `-- name: FileBlockInsertReplace :exec
+insert or replace into file_blocks (
+    filepath, byte_offset, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3,
+    xxh128, murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512,
+    blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128,
+    shake256, cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256,
+    ssdeep, tlsh,
+    size
+) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type FileBlockInsertReplaceParams struct {
+	Filepath    string
+	ByteOffset  int64
+	Crc32       sql.NullString
+	Xxhash64    sql.NullString
+	Crc32c      sql.NullString
+	Crc64Ecma   sql.NullString
+	Crc64Iso    sql.NullString
+	Adler32     sql.NullString
+	Xxh3        sql.NullString
+	Xxh128      sql.NullString
+	Murmur3     sql.NullString
+	Siphash     sql.NullString
+	Highwayhash sql.NullString
+	Md4         sql.NullString
+	Md5         sql.NullString
+	Sha1        sql.NullString
+	Sha256      sql.NullString
+	Sha384      sql.NullString
+	Sha512      sql.NullString
+	Blake2b256  sql.NullString
+	Blake2b512  sql.NullString
+	Blake3      sql.NullString
+	Sha3224     sql.NullString
+	Sha3256     sql.NullString
+	Sha3384     sql.NullString
+	Sha3512     sql.NullString
+	Shake128    sql.NullString
+	Shake256    sql.NullString
+	Cshake128   sql.NullString
+	Cshake256   sql.NullString
+	Ed2k        sql.NullString
+	Aich        sql.NullString
+	Tth         sql.NullString
+	Cidv0       sql.NullString
+	Cidv1       sql.NullString
+	GitSha1     sql.NullString
+	GitSha256   sql.NullString
+	Ssdeep      sql.NullString
+	Tlsh        sql.NullString
+	Size        int64
+}
+
+func (q *Queries) FileBlockInsertReplace(ctx context.Context, arg FileBlockInsertReplaceParams) error {
+	_, err := q.db.ExecContext(ctx, fileBlockInsertReplace,
+		arg.Filepath,
+		arg.ByteOffset,
+		arg.Crc32,
+		arg.Xxhash64,
+		arg.Crc32c,
+		arg.Crc64Ecma,
+		arg.Crc64Iso,
+		arg.Adler32,
+		arg.Xxh3,
+		arg.Xxh128,
+		arg.Murmur3,
+		arg.Siphash,
+		arg.Highwayhash,
+		arg.Md4,
+		arg.Md5,
+		arg.Sha1,
+		arg.Sha256,
+		arg.Sha384,
+		arg.Sha512,
+		arg.Blake2b256,
+		arg.Blake2b512,
+		arg.Blake3,
+		arg.Sha3224,
+		arg.Sha3256,
+		arg.Sha3384,
+		arg.Sha3512,
+		arg.Shake128,
+		arg.Shake256,
+		arg.Cshake128,
+		arg.Cshake256,
+		arg.Ed2k,
+		arg.Aich,
+		arg.Tth,
+		arg.Cidv0,
+		arg.Cidv1,
+		arg.GitSha1,
+		arg.GitSha256,
+		arg.Ssdeep,
+		arg.Tlsh,
+		arg.Size,
+	)
+	return err
+}
+
 const fileHashByFilePath = `-- name: FileHashByFilePath :one
-select filepath, crc32, xxhash64, md4, md5, sha1, sha256, sha512, blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, ed2k, size, mtime from file_hashes where filepath = ?
+select filepath, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3, xxh128, murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512, blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128, shake256, cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256, ssdeep, tlsh, size, mtime from file_hashes where filepath = ?
 `
 
 func (q *Queries) FileHashByFilePath(ctx context.Context, filepath string) (FileHash, error) {
@@ -21,10 +130,20 @@ func (q *Queries) FileHashByFilePath(ctx context.Context, filepath string) (File
 		&i.Filepath,
 		&i.Crc32,
 		&i.Xxhash64,
+		&i.Crc32c,
+		&i.Crc64Ecma,
+		&i.Crc64Iso,
+		&i.Adler32,
+		&i.Xxh3,
+		&i.Xxh128,
+		&i.Murmur3,
+		&i.Siphash,
+		&i.Highwayhash,
 		&i.Md4,
 		&i.Md5,
 		&i.Sha1,
 		&i.Sha256,
+		&i.Sha384,
 		&i.Sha512,
 		&i.Blake2b256,
 		&i.Blake2b512,
@@ -33,7 +152,19 @@ func (q *Queries) FileHashByFilePath(ctx context.Context, filepath string) (File
 		&i.Sha3256,
 		&i.Sha3384,
 		&i.Sha3512,
+		&i.Shake128,
+		&i.Shake256,
+		&i.Cshake128,
+		&i.Cshake256,
 		&i.Ed2k,
+		&i.Aich,
+		&i.Tth,
+		&i.Cidv0,
+		&i.Cidv1,
+		&i.GitSha1,
+		&i.GitSha256,
+		&i.Ssdeep,
+		&i.Tlsh,
 		&i.Size,
 		&i.Mtime,
 	)
@@ -43,32 +174,57 @@ func (q *Queries) FileHashByFilePath(ctx context.Context, filepath string) (File
 const fileHashInsertReplace = `-- name: FileHashInsertReplace :one
 
 insert or replace into file_hashes (
-    filepath, crc32, xxhash64, md4, md5, sha1, sha256, sha512,
-    blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, ed2k,
+    filepath, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3, xxh128,
+    murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512, blake2b_256,
+    blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128, shake256,
+    cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256, ssdeep,
+    tlsh,
     size, mtime
-) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-returning filepath, crc32, xxhash64, md4, md5, sha1, sha256, sha512, blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, ed2k, size, mtime
+) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+returning filepath, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3, xxh128, murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512, blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128, shake256, cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256, ssdeep, tlsh, size, mtime
 `
 
 type FileHashInsertReplaceParams struct {
-	Filepath   string
-	Crc32      sql.NullString
-	Xxhash64   sql.NullString
-	Md4        sql.NullString
-	Md5        sql.NullString
-	Sha1       sql.NullString
-	Sha256     sql.NullString
-	Sha512     sql.NullString
-	Blake2b256 sql.NullString
-	Blake2b512 sql.NullString
-	Blake3     sql.NullString
-	Sha3224    sql.NullString
-	Sha3256    sql.NullString
-	Sha3384    sql.NullString
-	Sha3512    sql.NullString
-	Ed2k       sql.NullString
-	Size       int64
-	Mtime      interface{}
+	Filepath    string
+	Crc32       sql.NullString
+	Xxhash64    sql.NullString
+	Crc32c      sql.NullString
+	Crc64Ecma   sql.NullString
+	Crc64Iso    sql.NullString
+	Adler32     sql.NullString
+	Xxh3        sql.NullString
+	Xxh128      sql.NullString
+	Murmur3     sql.NullString
+	Siphash     sql.NullString
+	Highwayhash sql.NullString
+	Md4         sql.NullString
+	Md5         sql.NullString
+	Sha1        sql.NullString
+	Sha256      sql.NullString
+	Sha384      sql.NullString
+	Sha512      sql.NullString
+	Blake2b256  sql.NullString
+	Blake2b512  sql.NullString
+	Blake3      sql.NullString
+	Sha3224     sql.NullString
+	Sha3256     sql.NullString
+	Sha3384     sql.NullString
+	Sha3512     sql.NullString
+	Shake128    sql.NullString
+	Shake256    sql.NullString
+	Cshake128   sql.NullString
+	Cshake256   sql.NullString
+	Ed2k        sql.NullString
+	Aich        sql.NullString
+	Tth         sql.NullString
+	Cidv0       sql.NullString
+	Cidv1       sql.NullString
+	GitSha1     sql.NullString
+	GitSha256   sql.NullString
+	Ssdeep      sql.NullString
+	Tlsh        sql.NullString
+	Size        int64
+	Mtime       interface{}
 }
 
 // SPDX-License-Identifier: MIT
@@ -77,10 +233,20 @@ func (q *Queries) FileHashInsertReplace(ctx context.Context, arg FileHashInsertR
 		arg.Filepath,
 		arg.Crc32,
 		arg.Xxhash64,
+		arg.Crc32c,
+		arg.Crc64Ecma,
+		arg.Crc64Iso,
+		arg.Adler32,
+		arg.Xxh3,
+		arg.Xxh128,
+		arg.Murmur3,
+		arg.Siphash,
+		arg.Highwayhash,
 		arg.Md4,
 		arg.Md5,
 		arg.Sha1,
 		arg.Sha256,
+		arg.Sha384,
 		arg.Sha512,
 		arg.Blake2b256,
 		arg.Blake2b512,
@@ -89,7 +255,19 @@ func (q *Queries) FileHashInsertReplace(ctx context.Context, arg FileHashInsertR
 		arg.Sha3256,
 		arg.Sha3384,
 		arg.Sha3512,
+		arg.Shake128,
+		arg.Shake256,
+		arg.Cshake128,
+		arg.Cshake256,
 		arg.Ed2k,
+		arg.Aich,
+		arg.Tth,
+		arg.Cidv0,
+		arg.Cidv1,
+		arg.GitSha1,
+		arg.GitSha256,
+		arg.Ssdeep,
+		arg.Tlsh,
 		arg.Size,
 		arg.Mtime,
 	)
@@ -98,10 +276,20 @@ func (q *Queries) FileHashInsertReplace(ctx context.Context, arg FileHashInsertR
 		&i.Filepath,
 		&i.Crc32,
 		&i.Xxhash64,
+		&i.Crc32c,
+		&i.Crc64Ecma,
+		&i.Crc64Iso,
+		&i.Adler32,
+		&i.Xxh3,
+		&i.Xxh128,
+		&i.Murmur3,
+		&i.Siphash,
+		&i.Highwayhash,
 		&i.Md4,
 		&i.Md5,
 		&i.Sha1,
 		&i.Sha256,
+		&i.Sha384,
 		&i.Sha512,
 		&i.Blake2b256,
 		&i.Blake2b512,
@@ -110,9 +298,89 @@ func (q *Queries) FileHashInsertReplace(ctx context.Context, arg FileHashInsertR
 		&i.Sha3256,
 		&i.Sha3384,
 		&i.Sha3512,
+		&i.Shake128,
+		&i.Shake256,
+		&i.Cshake128,
+		&i.Cshake256,
 		&i.Ed2k,
+		&i.Aich,
+		&i.Tth,
+		&i.Cidv0,
+		&i.Cidv1,
+		&i.GitSha1,
+		&i.GitSha256,
+		&i.Ssdeep,
+		&i.Tlsh,
 		&i.Size,
 		&i.Mtime,
 	)
 	return i, err
 }
+
+const fileHashList = `-- name: FileHashList :many
+select filepath, crc32, xxhash64, crc32c, crc64_ecma, crc64_iso, adler32, xxh3, xxh128, murmur3, siphash, highwayhash, md4, md5, sha1, sha256, sha384, sha512, blake2b_256, blake2b_512, blake3, sha3_224, sha3_256, sha3_384, sha3_512, shake128, shake256, cshake128, cshake256, ed2k, aich, tth, cidv0, cidv1, git_sha1, git_sha256, ssdeep, tlsh, size, mtime from file_hashes order by filepath
+`
+
+func (q *Queries) FileHashList(ctx context.Context) ([]FileHash, error) {
+	rows, err := q.db.QueryContext(ctx, fileHashList)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FileHash
+	for rows.Next() {
+		var i FileHash
+		if err := rows.Scan(
+			&i.Filepath,
+			&i.Crc32,
+			&i.Xxhash64,
+			&i.Crc32c,
+			&i.Crc64Ecma,
+			&i.Crc64Iso,
+			&i.Adler32,
+			&i.Xxh3,
+			&i.Xxh128,
+			&i.Murmur3,
+			&i.Siphash,
+			&i.Highwayhash,
+			&i.Md4,
+			&i.Md5,
+			&i.Sha1,
+			&i.Sha256,
+			&i.Sha384,
+			&i.Sha512,
+			&i.Blake2b256,
+			&i.Blake2b512,
+			&i.Blake3,
+			&i.Sha3224,
+			&i.Sha3256,
+			&i.Sha3384,
+			&i.Sha3512,
+			&i.Shake128,
+			&i.Shake256,
+			&i.Cshake128,
+			&i.Cshake256,
+			&i.Ed2k,
+			&i.Aich,
+			&i.Tth,
+			&i.Cidv0,
+			&i.Cidv1,
+			&i.GitSha1,
+			&i.GitSha256,
+			&i.Ssdeep,
+			&i.Tlsh,
+			&i.Size,
+			&i.Mtime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
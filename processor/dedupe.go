@@ -0,0 +1,493 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dedupeFile is a file which may have the same content as others
+type dedupeFile struct {
+	path string
+	info os.FileInfo
+}
+
+// dedupeGroup is a set of files with the same size and digests, where the
+// first is kept and the rest are replaced by links to it
+type dedupeGroup struct {
+	hash   string
+	digest string
+	files  []dedupeFile
+}
+
+// dedupeEntry is a replaced duplicate recorded in the journal with what is
+// needed to give it back its own copy and metadata
+type dedupeEntry struct {
+	Path   string      `json:"path"`
+	Kept   string      `json:"kept"`
+	Method string      `json:"method"`
+	Bytes  int64       `json:"bytes"`
+	Hash   string      `json:"hash"`
+	Digest string      `json:"digest"`
+	Mode   os.FileMode `json:"mode"`
+	MTime  time.Time   `json:"mtime"`
+	UID    *int        `json:"uid,omitempty"`
+	GID    *int        `json:"gid,omitempty"`
+}
+
+// Dedupe finds files below the paths with identical content and replaces all
+// but one of each with a reflink or hardlink to it, recording every replacement
+// in a journal so it can be undone. With DedupeDryRun the duplicates and the
+// bytes which could be reclaimed are reported without changing anything.
+func Dedupe(paths []string) {
+	if err := prepareHashes(); err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	// digests are written to the journal which is read back to undo
+	Encoding = "hex"
+
+	if DedupeUndo {
+		result, valid := undoDedupe(DedupeJournal)
+		fmt.Print(result)
+		if !valid {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(copyHashes()) == 0 {
+		printError("at least one hash is required to find duplicates")
+		os.Exit(1)
+	}
+	switch DedupeMethod {
+	case "reflink", "hardlink", "auto":
+	default:
+		printError(fmt.Sprintf("unknown method %s, must be one of reflink, hardlink or auto", DedupeMethod))
+		os.Exit(1)
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	// files which could not be read are left alone but still fail the run
+	groups, findErr := findDuplicates(paths)
+	if findErr != nil {
+		printError(findErr.Error())
+	}
+
+	var journal *os.File
+	if !DedupeDryRun && len(groups) != 0 {
+		var err error
+		journal, err = os.OpenFile(DedupeJournal, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			printError(fmt.Sprintf("unable to open journal %s: %s", DedupeJournal, err.Error()))
+			os.Exit(1)
+		}
+		defer journal.Close()
+	}
+
+	result, valid := dedupeGroups(groups, journal)
+	fmt.Print(result)
+	if journal != nil {
+		printVerbose("journal written to " + DedupeJournal)
+	}
+	if !valid || findErr != nil {
+		os.Exit(1)
+	}
+}
+
+// findDuplicates walks the paths hashing only files which have the same size
+// as another, returning the groups of regular files whose digests all match
+func findDuplicates(paths []string) ([]dedupeGroup, error) {
+	found := make(chan string, FileListQueueSize)
+	go func() {
+		for _, p := range paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				printError(fmt.Sprintf("file or directory issue: %s %s", p, err.Error()))
+				continue
+			}
+			if !fi.IsDir() {
+				found <- p
+				continue
+			}
+			walkDirectoryWithIgnore(p, found)
+		}
+		close(found)
+	}()
+
+	// the journal is never treated as a duplicate of anything
+	seen := map[string]bool{}
+	if abs, err := filepath.Abs(DedupeJournal); err == nil {
+		seen[abs] = true
+	}
+	bySize := map[int64][]dedupeFile{}
+	for p := range found {
+		// links are never followed or replaced and empty files have nothing to reclaim
+		fi, err := os.Lstat(p)
+		if err != nil || !fi.Mode().IsRegular() || fi.Size() == 0 {
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil || seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		bySize[fi.Size()] = append(bySize[fi.Size()], dedupeFile{path: p, info: fi})
+	}
+
+	files := make(chan string, FileListQueueSize)
+	go func() {
+		for _, same := range bySize {
+			if len(same) < 2 {
+				continue
+			}
+			for _, f := range same {
+				files <- f.path
+			}
+		}
+		close(files)
+	}()
+	results, err := hashFiles(files)
+	digests := map[string]Result{}
+	for _, res := range results {
+		digests[res.File] = res
+	}
+
+	hashes := copyHashes()
+	byKey := map[string]*dedupeGroup{}
+	for size, same := range bySize {
+		for _, f := range same {
+			res, ok := digests[f.path]
+			if !ok {
+				continue
+			}
+			var sb strings.Builder
+			sb.WriteString(fmt.Sprintf("%d,", size))
+			// hardlinks share their metadata so as with util-linux hardlink only
+			// files with the same mode and owner are linked together
+			if DedupeMethod == "hardlink" {
+				uid, gid, _ := fileOwner(f.info)
+				sb.WriteString(fmt.Sprintf("%o,%d,%d,", f.info.Mode(), uid, gid))
+			}
+			for _, h := range hashes {
+				sb.WriteString(res.Digest(h))
+				sb.WriteString(",")
+			}
+			g, ok := byKey[sb.String()]
+			if !ok {
+				g = &dedupeGroup{hash: hashes[0], digest: res.Digest(hashes[0])}
+				byKey[sb.String()] = g
+			}
+			g.files = append(g.files, f)
+		}
+	}
+
+	var groups []dedupeGroup
+	for _, g := range byKey {
+		if len(g.files) < 2 {
+			continue
+		}
+		sort.Slice(g.files, func(i, j int) bool { return g.files[i].path < g.files[j].path })
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].files[0].path < groups[j].files[0].path })
+	return groups, err
+}
+
+// dedupeGroups replaces the duplicates in each group writing each to the
+// journal, or only reports them if the journal is nil
+func dedupeGroups(groups []dedupeGroup, journal *os.File) (string, bool) {
+	var str strings.Builder
+	duplicates, replaced, failed := 0, 0, 0
+	var reclaimed int64
+	for _, g := range groups {
+		kept := g.files[0]
+		counted := []os.FileInfo{kept.info}
+		for _, f := range g.files[1:] {
+			if os.SameFile(kept.info, f.info) {
+				printVerbose(fmt.Sprintf("%s is already linked to %s", f.path, kept.path))
+				continue
+			}
+			// the space is only reclaimed once every hardlink to a duplicate is replaced
+			size := f.info.Size()
+			for _, c := range counted {
+				if os.SameFile(c, f.info) {
+					size = 0
+				}
+			}
+			counted = append(counted, f.info)
+			duplicates++
+
+			if journal == nil {
+				str.WriteString(fmt.Sprintf("duplicate: %s -> %s (%d bytes)\n", f.path, kept.path, f.info.Size()))
+				reclaimed += size
+				continue
+			}
+
+			method, err := dedupeFileTo(kept, f, g, journal)
+			if err != nil {
+				printError(fmt.Sprintf("unable to replace %s: %s", f.path, err.Error()))
+				failed++
+				continue
+			}
+			str.WriteString(fmt.Sprintf("%sed: %s -> %s\n", method, f.path, kept.path))
+			replaced++
+			reclaimed += size
+		}
+	}
+
+	if journal == nil {
+		str.WriteString(fmt.Sprintf("%d duplicates in %d groups, %d bytes reclaimable\n", duplicates, len(groups), reclaimed))
+		return str.String(), true
+	}
+	str.WriteString(fmt.Sprintf("%d duplicates replaced, %d bytes reclaimed, %d failed\n", replaced, reclaimed, failed))
+	return str.String(), failed == 0
+}
+
+// dedupeFileTo replaces the duplicate with a reflink or hardlink to the kept
+// file, once a byte by byte comparison proves they are still identical, returning
+// the method used. The link is made beside the duplicate and renamed over it only
+// after the journal entry is on disk, so the duplicate is never lost.
+func dedupeFileTo(kept dedupeFile, dup dedupeFile, g dedupeGroup, journal *os.File) (string, error) {
+	same, err := sameContent(kept.path, dup.path)
+	if err != nil {
+		return "", err
+	}
+	if !same {
+		return "", fmt.Errorf("content differs from %s", kept.path)
+	}
+	fi, err := os.Lstat(dup.path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() || fi.Size() != dup.info.Size() || !fi.ModTime().Equal(dup.info.ModTime()) {
+		return "", errors.New("changed while being deduplicated")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dup.path), ".hashit-dedupe-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	method := DedupeMethod
+	if method != "hardlink" {
+		err = dedupeClone(kept.path, tmp, fi)
+		if err != nil && method == "auto" {
+			method = "hardlink"
+		} else if err != nil {
+			_ = tmp.Close()
+			return "", fmt.Errorf("unable to reflink: %s", err.Error())
+		} else {
+			method = "reflink"
+		}
+	}
+	_ = tmp.Close()
+	if method == "hardlink" {
+		if !dedupeSameMetadata(kept.info, fi) {
+			return "", fmt.Errorf("mode or owner differs from %s so it cannot be hardlinked", kept.path)
+		}
+		// a link cannot replace an existing file so the temporary name is reused
+		if err := os.Remove(tmp.Name()); err != nil {
+			return "", err
+		}
+		if err := os.Link(kept.path, tmp.Name()); err != nil {
+			return "", fmt.Errorf("unable to hardlink: %s", err.Error())
+		}
+	}
+
+	entry := dedupeEntry{
+		Path:   dup.path,
+		Kept:   kept.path,
+		Method: method,
+		Bytes:  fi.Size(),
+		Hash:   g.hash,
+		Digest: g.digest,
+		Mode:   fi.Mode().Perm(),
+		MTime:  fi.ModTime(),
+	}
+	if uid, gid, ok := fileOwner(fi); ok {
+		entry.UID, entry.GID = &uid, &gid
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	if _, err := journal.Write(append(line, '\n')); err != nil {
+		return "", fmt.Errorf("unable to write journal: %s", err.Error())
+	}
+	if err := journal.Sync(); err != nil {
+		return "", fmt.Errorf("unable to write journal: %s", err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), dup.path); err != nil {
+		return "", err
+	}
+	return method, nil
+}
+
+// dedupeSameMetadata returns true if the files have the same mode and owner
+func dedupeSameMetadata(a os.FileInfo, b os.FileInfo) bool {
+	if a.Mode() != b.Mode() {
+		return false
+	}
+	uidA, gidA, okA := fileOwner(a)
+	uidB, gidB, okB := fileOwner(b)
+	return okA == okB && uidA == uidB && gidA == gidB
+}
+
+// dedupeClone reflinks the kept file into tmp giving it the metadata of the
+// duplicate it replaces, as unlike a hardlink a reflink is a file of its own
+func dedupeClone(kept string, tmp *os.File, fi os.FileInfo) error {
+	src, err := os.Open(kept)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := cloneFile(tmp, src); err != nil {
+		return err
+	}
+	_ = tmp.Chmod(fi.Mode().Perm())
+	if uid, gid, ok := fileOwner(fi); ok {
+		_ = tmp.Chown(uid, gid)
+	}
+	return os.Chtimes(tmp.Name(), fi.ModTime(), fi.ModTime())
+}
+
+// sameContent compares two files byte by byte
+func sameContent(a string, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	ra := bufio.NewReaderSize(fa, 64*1024)
+	rb := bufio.NewReaderSize(fb, 64*1024)
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(ra, bufA)
+		nb, errB := io.ReadFull(rb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		endA := errA == io.EOF || errors.Is(errA, io.ErrUnexpectedEOF)
+		endB := errB == io.EOF || errors.Is(errB, io.ErrUnexpectedEOF)
+		switch {
+		case errA != nil && !endA:
+			return false, errA
+		case errB != nil && !endB:
+			return false, errB
+		case endA || endB:
+			return endA == endB, nil
+		}
+	}
+}
+
+// undoDedupe gives every file recorded in the journal its own copy again,
+// newest first, restoring the mode, owner and modification time it had. Files
+// which have changed since are left alone. The journal is removed once every
+// file is restored.
+func undoDedupe(path string) (string, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		printError(fmt.Sprintf("unable to read journal %s: %s", path, err.Error()))
+		return "", false
+	}
+
+	var entries []dedupeEntry
+	Hash = []string{}
+	for i, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var e dedupeEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			printError(fmt.Sprintf("unable to parse journal %s line %d: %s", path, i+1, err.Error()))
+			return "", false
+		}
+		if !contains(Hash, e.Hash) {
+			Hash = append(Hash, e.Hash)
+		}
+		entries = append(entries, e)
+	}
+
+	var str strings.Builder
+	restored, failed := 0, 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := undoEntry(entries[i]); err != nil {
+			printError(fmt.Sprintf("unable to restore %s: %s", entries[i].Path, err.Error()))
+			failed++
+			continue
+		}
+		str.WriteString(fmt.Sprintf("restored: %s\n", entries[i].Path))
+		restored++
+	}
+	str.WriteString(fmt.Sprintf("%d restored, %d failed\n", restored, failed))
+
+	if failed != 0 {
+		return str.String(), false
+	}
+	if err := os.Remove(path); err != nil {
+		printError(fmt.Sprintf("unable to remove journal %s: %s", path, err.Error()))
+	}
+	return str.String(), true
+}
+
+// undoEntry copies the file beside itself checking the digest as it goes and
+// renames the copy over it, which breaks any hardlink or reflink it had
+func undoEntry(e dedupeEntry) error {
+	in, err := os.Open(e.Path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.Path), ".hashit-dedupe-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	// copied through a reader so the copy cannot be made as another reflink
+	res, err := processReader(e.Path, io.TeeReader(in, tmp))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if res.Bytes != e.Bytes || res.Digest(e.Hash) != e.Digest {
+		return errors.New("changed since it was deduplicated")
+	}
+
+	_ = os.Chmod(tmp.Name(), e.Mode)
+	if e.UID != nil && e.GID != nil {
+		_ = os.Chown(tmp.Name(), *e.UID, *e.GID)
+	}
+	if err := os.Chtimes(tmp.Name(), e.MTime, e.MTime); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), e.Path)
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupe(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	Encoding = "hex"
+	defer func() { DedupeMethod = "reflink" }()
+
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a/photo.jpg", "hello world")
+	b := write("b/photo.jpg", "hello world")
+	c := write("c/photo.jpg", "hello world")
+	write("a/other.jpg", "hello there")
+	write("a/empty", "")
+	write("b/empty", "")
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = os.Chtimes(c, mtime, mtime)
+
+	groups, err := findDuplicates([]string{dir})
+	if err != nil || len(groups) != 1 || len(groups[0].files) != 3 || groups[0].files[0].path != a {
+		t.Fatalf("expected one group kept as %s got %v %v", a, groups, err)
+	}
+
+	out, valid := dedupeGroups(groups, nil)
+	if !valid || !strings.HasSuffix(out, "2 duplicates in 1 groups, 22 bytes reclaimable\n") {
+		t.Errorf("unexpected dry run\n%s", out)
+	}
+	fa, _ := os.Stat(a)
+	if fb, _ := os.Stat(b); os.SameFile(fa, fb) {
+		t.Error("expected the dry run to change nothing")
+	}
+
+	DedupeMethod = "hardlink"
+	journal := filepath.Join(t.TempDir(), "journal")
+	f, err := os.OpenFile(journal, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, valid = dedupeGroups(groups, f)
+	_ = f.Close()
+	if !valid || !strings.Contains(out, "hardlinked: "+b+" -> "+a) || !strings.HasSuffix(out, "2 duplicates replaced, 22 bytes reclaimed, 0 failed\n") {
+		t.Errorf("unexpected dedupe\n%s", out)
+	}
+	for _, p := range []string{b, c} {
+		if fi, _ := os.Stat(p); !os.SameFile(fa, fi) {
+			t.Errorf("expected %s to be linked to %s", p, a)
+		}
+	}
+
+	out, valid = undoDedupe(journal)
+	if !valid || !strings.HasSuffix(out, "2 restored, 0 failed\n") {
+		t.Errorf("unexpected undo\n%s", out)
+	}
+	for _, p := range []string{b, c} {
+		fi, _ := os.Stat(p)
+		if os.SameFile(fa, fi) {
+			t.Errorf("expected %s to have its own copy again", p)
+		}
+		if content, _ := os.ReadFile(p); string(content) != "hello world" {
+			t.Errorf("expected %s to keep its content got %q", p, content)
+		}
+	}
+	if fi, _ := os.Stat(c); !fi.ModTime().Equal(mtime) {
+		t.Errorf("expected the modification time of %s to be restored got %s", c, fi.ModTime())
+	}
+	if _, err := os.Stat(journal); !os.IsNotExist(err) {
+		t.Error("expected the journal to be removed once everything is restored")
+	}
+}
+
+func TestDedupeChangedSinceHashed(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	Encoding = "hex"
+	DedupeMethod = "hardlink"
+	defer func() { DedupeMethod = "reflink" }()
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	_ = os.WriteFile(a, []byte("hello world"), 0600)
+	_ = os.WriteFile(b, []byte("hello world"), 0600)
+	groups, _ := findDuplicates([]string{dir})
+	_ = os.WriteFile(b, []byte("hello there"), 0600)
+
+	f, _ := os.CreateTemp(t.TempDir(), "journal")
+	defer f.Close()
+	out, valid := dedupeGroups(groups, f)
+	if valid || !strings.HasSuffix(out, "0 duplicates replaced, 0 bytes reclaimed, 1 failed\n") {
+		t.Errorf("expected the changed file to be left alone\n%s", out)
+	}
+	if content, _ := os.ReadFile(b); string(content) != "hello there" {
+		t.Errorf("expected b to be untouched got %q", content)
+	}
+}
+
+func TestDedupeHardlinkMetadata(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	Encoding = "hex"
+	defer func() { DedupeMethod = "reflink" }()
+
+	dir := t.TempDir()
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+	for _, p := range []string{a, b, c} {
+		if err := os.WriteFile(p, []byte("hello world"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// files with a different mode are never grouped to be hardlinked
+	DedupeMethod = "hardlink"
+	groups, err := findDuplicates([]string{dir})
+	if err != nil || len(groups) != 1 || len(groups[0].files) != 2 || groups[0].files[0].path != a || groups[0].files[1].path != c {
+		t.Fatalf("expected only a and c to be grouped got %v %v", groups, err)
+	}
+
+	// nor linked when a reflink is not possible
+	DedupeMethod = "auto"
+	groups, _ = findDuplicates([]string{dir})
+	if len(groups) != 1 || len(groups[0].files) != 3 {
+		t.Fatalf("expected all three to be grouped got %v", groups)
+	}
+	f, _ := os.CreateTemp(t.TempDir(), "journal")
+	defer f.Close()
+	out, _ := dedupeGroups(groups, f)
+	fa, _ := os.Stat(a)
+	if fb, _ := os.Stat(b); os.SameFile(fa, fb) {
+		t.Errorf("expected b with a different mode not to be hardlinked\n%s", out)
+	}
+	if fb, _ := os.Stat(b); fb.Mode().Perm() != 0644 {
+		t.Errorf("expected b to keep its mode got %s", fb.Mode())
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("x", 200*1024)
+	cases := map[string]bool{
+		big:                    true,
+		big + "y":              false,
+		big[:len(big)-1] + "y": false,
+		"":                     false,
+	}
+	a := filepath.Join(dir, "a")
+	_ = os.WriteFile(a, []byte(big), 0600)
+	for content, expected := range cases {
+		b := filepath.Join(dir, "b")
+		_ = os.WriteFile(b, []byte(content), 0600)
+		if same, err := sameContent(a, b); err != nil || same != expected {
+			t.Errorf("expected %v for %d bytes got %v %v", expected, len(content), same, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Digital Forensics XML as written by hashdeep -d and other forensic tools,
+// where each file is a fileobject holding its hashdigests and any piecewise
+// blocks are byte_runs with their own hashdigests
+
+type dfxmlDocument struct {
+	XMLName     xml.Name          `xml:"dfxml"`
+	FileObjects []dfxmlFileObject `xml:"fileobject"`
+}
+
+type dfxmlFileObject struct {
+	XMLName     xml.Name          `xml:"fileobject"`
+	Filename    string            `xml:"filename"`
+	Filesize    int64             `xml:"filesize"`
+	MTime       string            `xml:"mtime,omitempty"`
+	HashDigests []dfxmlHashDigest `xml:"hashdigest"`
+	ByteRuns    *dfxmlByteRuns    `xml:"byte_runs,omitempty"`
+}
+
+type dfxmlByteRuns struct {
+	ByteRuns []dfxmlByteRun `xml:"byte_run"`
+}
+
+type dfxmlByteRun struct {
+	FileOffset  int64             `xml:"file_offset,attr"`
+	Len         int64             `xml:"len,attr"`
+	HashDigests []dfxmlHashDigest `xml:"hashdigest"`
+}
+
+type dfxmlHashDigest struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// toDFXML writes the results as DFXML including the creator block describing
+// how and where hashit was run
+func toDFXML(input chan Result, output string) string {
+	var str strings.Builder
+	str.WriteString(dfxmlHeader())
+
+	for res := range input {
+		fo := dfxmlFileObject{
+			Filename:    res.File,
+			Filesize:    res.Bytes,
+			HashDigests: dfxmlHashDigests(res),
+		}
+		if MTime && res.MTime != nil {
+			fo.MTime = res.MTime.UTC().Format(time.RFC3339)
+		}
+		if len(res.Blocks) != 0 {
+			fo.ByteRuns = &dfxmlByteRuns{}
+		}
+		for _, b := range res.Blocks {
+			fo.ByteRuns.ByteRuns = append(fo.ByteRuns.ByteRuns, dfxmlByteRun{
+				FileOffset:  b.Offset,
+				Len:         b.Bytes,
+				HashDigests: dfxmlHashDigests(b.Result),
+			})
+		}
+
+		out, _ := xml.MarshalIndent(fo, "  ", "  ")
+		str.Write(out)
+		str.WriteString("\n")
+
+		if !NoStream && output == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	str.WriteString("</dfxml>\n")
+	return str.String()
+}
+
+func dfxmlHeader() string {
+	var str strings.Builder
+	str.WriteString(xml.Header)
+	str.WriteString(`<dfxml xmloutputversion="1.0" xmlns="http://www.forensicswiki.org/wiki/Category:Digital_Forensics_XML" xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	str.WriteString("  <metadata>\n")
+	str.WriteString("    <dc:type>Hash List</dc:type>\n")
+	str.WriteString("  </metadata>\n")
+	str.WriteString(`  <creator version="1.0">` + "\n")
+	str.WriteString("    <program>hashit</program>\n")
+	str.WriteString("    <version>" + dfxmlEscape(Version) + "</version>\n")
+	str.WriteString("    <build_environment>\n")
+	str.WriteString("      <compiler>" + dfxmlEscape(runtime.Version()) + "</compiler>\n")
+	str.WriteString("    </build_environment>\n")
+	str.WriteString("    <execution_environment>\n")
+	str.WriteString("      <os_sysname>" + runtime.GOOS + "</os_sysname>\n")
+	str.WriteString("      <arch>" + runtime.GOARCH + "</arch>\n")
+	if host, err := os.Hostname(); err == nil {
+		str.WriteString("      <host>" + dfxmlEscape(host) + "</host>\n")
+	}
+	if pwd, err := os.Getwd(); err == nil {
+		str.WriteString("      <cwd>" + dfxmlEscape(pwd) + "</cwd>\n")
+	}
+	str.WriteString("      <command_line>" + dfxmlEscape(strings.Join(os.Args, " ")) + "</command_line>\n")
+	str.WriteString("      <start_time>" + getFormattedTime() + "</start_time>\n")
+	str.WriteString("    </execution_environment>\n")
+	str.WriteString("  </creator>\n")
+	return str.String()
+}
+
+func dfxmlEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// dfxmlHashDigests returns every enabled hash for the result, which are
+// always hex as DFXML expects apart from those which are already text
+func dfxmlHashDigests(res Result) []dfxmlHashDigest {
+	var digests []dfxmlHashDigest
+	for _, name := range allHashes {
+		if !hasHash(name) {
+			continue
+		}
+		value := res.Digest(name)
+		if !textHashes[name] {
+			value = hex.EncodeToString(decodeEncoded(value))
+		}
+		digests = append(digests, dfxmlHashDigest{Type: strings.ToUpper(name), Value: value})
+	}
+	return digests
+}
+
+// dfxmlHashName maps a hashdigest type to our name for the hash, allowing
+// for the upper case and dashed names other tools use such as SHA-256
+func dfxmlHashName(t string) (string, bool) {
+	name := strings.ToLower(t)
+	if HashNames.Digest(name) == name {
+		return name, true
+	}
+	name = strings.ReplaceAll(name, "-", "")
+	if HashNames.Digest(name) == name {
+		return name, true
+	}
+	return "", false
+}
+
+// parseDFXMLFile builds the audit records from the fileobjects of a DFXML
+// file, where the hashes to audit are every supported hash found
+func (hdl *Auditor) parseDFXMLFile(input string) (map[string]AuditRecord, error) {
+	var doc dfxmlDocument
+	if err := xml.Unmarshal([]byte(input), &doc); err != nil {
+		return nil, err
+	}
+
+	auditLookup := map[string]AuditRecord{}
+	hdl.hashes = []string{}
+	for _, fo := range doc.FileObjects {
+		record := AuditRecord{
+			Size:     strconv.FormatInt(fo.Filesize, 10),
+			Filename: fo.Filename,
+			Hashes:   hdl.dfxmlRecordHashes(fo.HashDigests),
+		}
+
+		var runs []dfxmlByteRun
+		if fo.ByteRuns != nil {
+			runs = fo.ByteRuns.ByteRuns
+		}
+		for _, run := range runs {
+			record.Blocks = append(record.Blocks, AuditRecord{
+				Size:     strconv.FormatInt(run.Len, 10),
+				Filename: pieceFilename(fo.Filename, Block{Offset: run.FileOffset, Result: Result{Bytes: run.Len}}),
+				Hashes:   hdl.dfxmlRecordHashes(run.HashDigests),
+				Offset:   run.FileOffset,
+				End:      run.FileOffset + run.Len - 1,
+			})
+			hdl.pieceSize = max(hdl.pieceSize, run.Len)
+		}
+
+		auditLookup[fo.Filename] = record
+	}
+
+	return auditLookup, nil
+}
+
+func (hdl *Auditor) dfxmlRecordHashes(digests []dfxmlHashDigest) map[string]string {
+	hashes := map[string]string{}
+	for _, d := range digests {
+		name, ok := dfxmlHashName(d.Type)
+		if !ok {
+			continue
+		}
+		if !contains(hdl.hashes, name) {
+			hdl.hashes = append(hdl.hashes, name)
+		}
+
+		value := strings.TrimSpace(d.Value)
+		if !textHashes[name] && value != "" {
+			value = hdl.normalizeDigest(name, value)
+		}
+		hashes[name] = value
+	}
+	return hashes
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDFXMLAuditRoundTrip(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5", "sha256"}
+	NoStream = true
+	defer func() { NoStream = false }()
+
+	input := make(chan Result, 1)
+	input <- Result{
+		File:   "at/a.txt",
+		Bytes:  6,
+		MD5:    "b1946ac92492d2347c6235b4d2611184",
+		SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	close(input)
+
+	out := toDFXML(input, "")
+	if !strings.Contains(out, `<hashdigest type="MD5">b1946ac92492d2347c6235b4d2611184</hashdigest>`) {
+		t.Errorf("expected md5 hashdigest got %s", out)
+	}
+
+	hdl, err := NewAuditor(out)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if len(hdl.Hashes()) != 2 {
+		t.Errorf("expected md5 and sha256 got %v", hdl.Hashes())
+	}
+	if r := hdl.Find(Result{File: "at/a.txt", MD5: "b1946ac92492d2347c6235b4d2611184", SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"}); r != FileMatched {
+		t.Errorf("expected match got %d", r)
+	}
+}
+
+func TestNewAuditorDFXML(t *testing.T) {
+	input := `<?xml version='1.0' encoding='UTF-8'?>
+<dfxml xmloutputversion='1.0'>
+  <fileobject>
+    <filename>at/a.bin</filename>
+    <filesize>10</filesize>
+    <hashdigest type='SHA-256'>5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03</hashdigest>
+    <hashdigest type='TIGER'>ignored</hashdigest>
+    <byte_runs>
+      <byte_run file_offset='0' len='4'><hashdigest type='SHA-256'>aaaa</hashdigest></byte_run>
+      <byte_run file_offset='4' len='4'><hashdigest type='SHA-256'>bbbb</hashdigest></byte_run>
+      <byte_run file_offset='8' len='2'><hashdigest type='SHA-256'>cccc</hashdigest></byte_run>
+    </byte_runs>
+  </fileobject>
+</dfxml>
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	if len(hdl.Hashes()) != 1 || hdl.Hashes()[0] != "sha256" {
+		t.Errorf("expected only sha256 got %v", hdl.Hashes())
+	}
+	if hdl.PieceSize() != 4 {
+		t.Errorf("expected piece size 4 got %d", hdl.PieceSize())
+	}
+
+	res := Result{File: "at/a.bin", Blocks: []Block{
+		{Offset: 0, Result: Result{SHA256: "aaaa", Bytes: 4}},
+		{Offset: 4, Result: Result{SHA256: "bbbb", Bytes: 4}},
+		{Offset: 8, Result: Result{SHA256: "cccc", Bytes: 2}},
+	}}
+	if r := hdl.Find(res); r != FileMatched {
+		t.Errorf("expected match got %d", r)
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diff compares two manifests of any format convert can read, reporting the
+// files added, removed, modified and moved between them without reading any files
+func Diff(oldPath string, newPath string) {
+	old, oldFormat, err := readManifest(oldPath, "")
+	if err != nil {
+		printError(fmt.Sprintf("unable to read %s: %s", oldPath, err.Error()))
+		os.Exit(1)
+	}
+	current, newFormat, err := readManifest(newPath, "")
+	if err != nil {
+		printError(fmt.Sprintf("unable to read %s: %s", newPath, err.Error()))
+		os.Exit(1)
+	}
+
+	result, valid, err := diffManifests(old, current, manifestSizes(oldFormat), manifestSizes(newFormat))
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	fmt.Print(result)
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// diffManifests audits the new results against the old ones in the same way as
+// --audit, using only the hashes both manifests have. Sizes are only reported
+// for the manifests which record them.
+func diffManifests(old []Result, current []Result, oldSizes bool, newSizes bool) (string, bool, error) {
+	var hashes []string
+	for _, h := range allHashes {
+		if manifestHas(old, h) && manifestHas(current, h) {
+			hashes = append(hashes, h)
+		}
+	}
+	if len(hashes) == 0 {
+		return "", false, errors.New("manifests do not have any hashes in common")
+	}
+
+	hdl := &Auditor{
+		fileLookup: map[string]AuditRecord{},
+		hashes:     hashes,
+		lengths:    map[string]int{},
+	}
+	for _, res := range old {
+		r := resultRecord(res, hashes)
+		if !oldSizes {
+			r.Size = ""
+		}
+		hdl.fileLookup[res.File] = r
+	}
+
+	var str strings.Builder
+	var modified []Result
+	var added []Result
+	unchanged := 0
+	for _, res := range current {
+		switch hdl.Find(res) {
+		case FileMatched:
+			unchanged++
+		case FileModified:
+			modified = append(modified, res)
+		default:
+			added = append(added, res)
+		}
+	}
+	moves, added, removed := hdl.Moves(added)
+
+	sort.Slice(modified, func(i, j int) bool { return modified[i].File < modified[j].File })
+	for _, res := range modified {
+		str.WriteString(fmt.Sprintf("modified: %s\n", res.File))
+		str.WriteString(diffDetails(hdl.fileLookup[res.File], res, hashes, newSizes))
+	}
+	for _, m := range moves {
+		str.WriteString(fmt.Sprintf("moved: %s -> %s\n", m.From.Filename, m.To.File))
+		if VeryVerbose {
+			str.WriteString(diffDetails(m.From, m.To, hashes, newSizes))
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].File < added[j].File })
+	for _, res := range added {
+		str.WriteString(fmt.Sprintf("added: %s\n", res.File))
+		str.WriteString(diffDetails(AuditRecord{}, res, hashes, newSizes))
+	}
+	for _, r := range removed {
+		str.WriteString(fmt.Sprintf("removed: %s\n", r.Filename))
+		str.WriteString(diffDetails(r, Result{}, hashes, newSizes))
+	}
+
+	str.WriteString(fmt.Sprintf("%d added, %d removed, %d modified, %d moved, %d unchanged\n", len(added), len(removed), len(modified), len(moves), unchanged))
+	return str.String(), len(added)+len(removed)+len(modified)+len(moves) == 0, nil
+}
+
+// diffDetails writes the size and digests before and after, where either may be
+// missing including the size before when its Size is empty and after unless afterSizes
+func diffDetails(before AuditRecord, after Result, hashes []string, afterSizes bool) string {
+	var str strings.Builder
+	line := func(name, b, a string) {
+		switch {
+		case b == "" && a == "":
+		case b == "" || b == a:
+			str.WriteString(fmt.Sprintf("\t%s %s\n", name, a))
+		case a == "":
+			str.WriteString(fmt.Sprintf("\t%s %s\n", name, b))
+		default:
+			str.WriteString(fmt.Sprintf("\t%s %s -> %s\n", name, b, a))
+		}
+	}
+
+	afterSize := ""
+	if after.File != "" && afterSizes {
+		afterSize = strconv.FormatInt(after.Bytes, 10)
+	}
+	line("bytes", before.Size, afterSize)
+	for _, h := range hashes {
+		line(h, before.Hashes[h], after.Digest(h))
+	}
+	return str.String()
+}
+
+func manifestHas(results []Result, hash string) bool {
+	for _, res := range results {
+		if res.Digest(hash) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resultRecord turns a result into the record an audit file would hold for it
+func resultRecord(res Result, hashes []string) AuditRecord {
+	r := AuditRecord{
+		Filename: res.File,
+		Size:     strconv.FormatInt(res.Bytes, 10),
+		Hashes:   map[string]string{},
+	}
+	for _, h := range hashes {
+		if digest := res.Digest(h); digest != "" {
+			r.Hashes[h] = digest
+		}
+	}
+	for _, b := range res.Blocks {
+		block := resultRecord(b.Result, hashes)
+		block.Offset = b.Offset
+		block.End = b.End()
+		r.Blocks = append(r.Blocks, block)
+	}
+	return r
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffManifests(t *testing.T) {
+	old := []Result{
+		{File: "same.txt", Bytes: 1, SHA256: "aa"},
+		{File: "changed.txt", Bytes: 1, SHA256: "bb"},
+		{File: "before.txt", Bytes: 1, SHA256: "cc"},
+		{File: "gone.txt", Bytes: 1, SHA256: "dd"},
+		{File: "empty1.txt", Bytes: 0, SHA256: "ee"},
+		{File: "empty2.txt", Bytes: 0, SHA256: "ee"},
+	}
+	current := []Result{
+		{File: "same.txt", Bytes: 1, SHA256: "aa", MD5: "11"},
+		{File: "changed.txt", Bytes: 2, SHA256: "b2"},
+		{File: "after.txt", Bytes: 1, SHA256: "cc"},
+		{File: "new.txt", Bytes: 1, SHA256: "ff"},
+	}
+
+	out, valid, err := diffManifests(old, current, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid {
+		t.Error("expected differences")
+	}
+
+	for _, expected := range []string{
+		"modified: changed.txt\n\tbytes 1 -> 2\n\tsha256 bb -> b2\n",
+		"moved: before.txt -> after.txt\n",
+		"added: new.txt\n",
+		"removed: gone.txt\n",
+		"removed: empty1.txt\n",
+		"removed: empty2.txt\n",
+		"1 added, 3 removed, 1 modified, 1 moved, 1 unchanged\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+
+	if _, _, err := diffManifests(old, []Result{{File: "a", MD5: "11"}}, true, true); err == nil {
+		t.Error("expected error without common hashes")
+	}
+}
+
+func TestDiffManifestsWithoutSizes(t *testing.T) {
+	// sum manifests do not record sizes so they are read as 0
+	old := []Result{
+		{File: "changed.txt", SHA256: "bb"},
+		{File: "gone.txt", SHA256: "dd"},
+	}
+	current := []Result{
+		{File: "changed.txt", Bytes: 2, SHA256: "b2"},
+		{File: "new.txt", Bytes: 1, SHA256: "ff"},
+	}
+
+	out, _, err := diffManifests(old, current, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"modified: changed.txt\n\tbytes 2\n\tsha256 bb -> b2\n",
+		"removed: gone.txt\n\tsha256 dd\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+
+	out, _, _ = diffManifests(current, old, true, false)
+	if strings.Contains(out, "-> 0") || !strings.Contains(out, "added: gone.txt\n\tsha256 dd\n") {
+		t.Errorf("expected no sizes for the new manifest in\n%s", out)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DirHash prints the Go module h1: hash for each directory, which is the hash
+// go.sum records for a module. Every file is prefixed with DirHashPrefix, or the
+// module@version taken from the module cache path when it is empty.
+func DirHash(paths []string) {
+	for _, path := range paths {
+		prefix := DirHashPrefix
+		if prefix == "" {
+			prefix = goModulePrefix(path)
+		}
+
+		h, err := dirHash(path, prefix)
+		if err != nil {
+			printError(fmt.Sprintf("unable to hash %s: %s", path, err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s  %s\n", h, path)
+	}
+}
+
+// dirHash calculates the h1: hash the same way as golang.org/x/mod/sumdb/dirhash
+// being the sha256 of a sha256sum style listing of every file sorted by name
+func dirHash(path string, prefix string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() {
+		return "", errors.New("not a directory")
+	}
+
+	// only sha256 is needed and the listing is always hex, with the hashes and
+	// encoding being used put back afterwards
+	defer func(hashes []string, encoding string) { Hash, Encoding = hashes, encoding }(Hash, Encoding)
+	Hash = []string{HashNames.SHA256}
+	Encoding = "hex"
+	results, err := hashDirectory(path)
+	if err != nil {
+		return "", err
+	}
+
+	sums := map[string]string{}
+	names := make([]string, 0, len(results))
+	for _, res := range results {
+		rel, err := filepath.Rel(path, res.File)
+		if err != nil {
+			return "", err
+		}
+		name := prefix + "/" + filepath.ToSlash(rel)
+		if strings.Contains(name, "\n") {
+			return "", fmt.Errorf("file name %q contains a newline", name)
+		}
+		sums[name] = res.SHA256
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s  %s\n", sums[name], name)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashDirectory walks the directory using the usual ignore rules and hashes
+// every file found the same way as Process, in no particular order
+func hashDirectory(path string) ([]Result, error) {
+	walked := make(chan string, FileListQueueSize)
+
+	go func() {
+		walkDirectoryWithIgnore(path, walked)
+		close(walked)
+	}()
+
+	return hashFiles(walked)
+}
+
+// hashFiles hashes every file sent on the channel the same way as Process,
+// returning an error if any of them could not be read
+func hashFiles(files chan string) ([]Result, error) {
+	fileListQueue := make(chan string, FileListQueueSize)
+	fileSummaryQueue := make(chan Result, FileListQueueSize)
+
+	// count the files so any that could not be read are noticed
+	count := 0
+	go func() {
+		for f := range files {
+			count++
+			fileListQueue <- f
+		}
+		close(fileListQueue)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < NoThreads; i++ {
+		wg.Add(1)
+		go func() {
+			fileProcessorWorker(fileListQueue, fileSummaryQueue)
+			wg.Done()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(fileSummaryQueue)
+	}()
+
+	results := []Result{}
+	for res := range fileSummaryQueue {
+		results = append(results, res)
+	}
+
+	if len(results) != count {
+		return nil, fmt.Errorf("%d files could not be read", count-len(results))
+	}
+	return results, nil
+}
+
+// goModulePrefix works out the module@version from a path inside the module
+// cache, undoing the escaping of upper case letters, otherwise using the name
+// of the directory
+func goModulePrefix(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+
+	slashed := filepath.ToSlash(abs)
+	i := strings.LastIndex(slashed, "/pkg/mod/")
+	if i == -1 {
+		return filepath.Base(abs)
+	}
+
+	var prefix strings.Builder
+	upper := false
+	for _, r := range slashed[i+len("/pkg/mod/"):] {
+		switch {
+		case r == '!':
+			upper = true
+			continue
+		case upper:
+			r = r - 'a' + 'A'
+		}
+		upper = false
+		prefix.WriteRune(r)
+	}
+	return prefix.String()
+}
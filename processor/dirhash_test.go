@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// dirHashTree writes a small module whose hash was produced using
+// golang.org/x/mod/sumdb/dirhash.HashDir with the prefix example.com/m@v1.0.0
+func dirHashTree(t *testing.T) string {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":    "hello\n",
+		"sub/b.go": "package sub\n",
+		"go.mod":   "module example.com/m\n",
+	} {
+		p := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDirHash(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5", "sha1"}
+
+	got, err := dirHash(dirHashTree(t), "example.com/m@v1.0.0")
+	expected := "h1:x7/5d5/j1vloHomzyXQywtfVOXoq2DqCJm1H3gaW8ZY="
+	if err != nil || got != expected {
+		t.Errorf("Expected %s got %s %v", expected, got, err)
+	}
+
+	if len(Hash) != 2 || Hash[0] != "md5" || Hash[1] != "sha1" {
+		t.Errorf("Expected the hashes to be put back got %v", Hash)
+	}
+}
+
+func TestDirHashEncoding(t *testing.T) {
+	t.Cleanup(resetState)
+	Encoding = "base64"
+
+	got, err := dirHash(dirHashTree(t), "example.com/m@v1.0.0")
+	expected := "h1:x7/5d5/j1vloHomzyXQywtfVOXoq2DqCJm1H3gaW8ZY="
+	if err != nil || got != expected {
+		t.Errorf("Expected %s got %s %v", expected, got, err)
+	}
+	if Encoding != "base64" {
+		t.Errorf("Expected the encoding to be put back got %s", Encoding)
+	}
+}
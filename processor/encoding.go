@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// encodings are the ways digests can be written using --encoding
+var encodings = []string{"hex", "base64", "base64url", "base32", "nix32"}
+
+const nix32Alphabet = "0123456789abcdfghijklmnpqrsvwxyz"
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// digestSizes holds the size in bytes of every hash with a fixed size binary
+// digest so that encoded digests can be told apart when decoding them
+var digestSizes = map[string]int{
+	"crc32":       4,
+	"xxhash64":    8,
+	"crc32c":      4,
+	"crc64ecma":   8,
+	"crc64iso":    8,
+	"adler32":     4,
+	"xxh3":        8,
+	"xxh128":      16,
+	"murmur3":     16,
+	"siphash":     8,
+	"highwayhash": 32,
+	"md4":         16,
+	"md5":         16,
+	"sha1":        20,
+	"sha256":      32,
+	"sha384":      48,
+	"sha512":      64,
+	"blake2b256":  32,
+	"blake2b512":  64,
+	"sha3224":     28,
+	"sha3256":     32,
+	"sha3384":     48,
+	"sha3512":     64,
+	"ed2k":        16,
+	"git-sha1":    20,
+	"git-sha256":  32,
+}
+
+// encodeDigest writes the digest using the selected Encoding
+func encodeDigest(b []byte) string {
+	switch strings.ToLower(Encoding) {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(b)
+	case "base32":
+		return base32NoPadding.EncodeToString(b)
+	case "nix32":
+		return nix32Encode(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+// decodeEncoded reverses encodeDigest for digests we have calculated
+func decodeEncoded(s string) []byte {
+	var b []byte
+	switch strings.ToLower(Encoding) {
+	case "base64":
+		b, _ = base64.StdEncoding.DecodeString(s)
+	case "base64url":
+		b, _ = base64.RawURLEncoding.DecodeString(s)
+	case "base32":
+		b, _ = base32NoPadding.DecodeString(s)
+	case "nix32":
+		b, _ = nix32Decode(s)
+	default:
+		b, _ = hex.DecodeString(s)
+	}
+	return b
+}
+
+// decodeDigest decodes a digest written using any of the encodings, or as
+// SRI such as sha256-<base64>, returning the raw bytes. When the size is
+// known it is used to tell apart encodings which could otherwise be confused,
+// otherwise the first encoding the digest is valid for is used.
+func decodeDigest(s string, size int) ([]byte, bool) {
+	for _, prefix := range []string{"sha256-", "sha384-", "sha512-"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+
+	decoders := []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+		base32.StdEncoding.DecodeString,
+		base32NoPadding.DecodeString,
+		nix32Decode,
+		// base32 which has been lower cased
+		func(s string) ([]byte, error) { return base32NoPadding.DecodeString(strings.ToUpper(s)) },
+	}
+
+	for _, decode := range decoders {
+		b, err := decode(s)
+		if err == nil && len(b) != 0 && (size == 0 || len(b) == size) {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// nix32Encode encodes using the base32 Nix uses for hashes, which has its own
+// alphabet and reads the bytes from the end
+func nix32Encode(b []byte) string {
+	size := (len(b)*8-1)/5 + 1
+	out := make([]byte, 0, size)
+	for n := size - 1; n >= 0; n-- {
+		bit := n * 5
+		i := bit / 8
+		j := bit % 8
+		c := b[i] >> j
+		if i+1 < len(b) {
+			c |= b[i+1] << (8 - j)
+		}
+		out = append(out, nix32Alphabet[c&0x1f])
+	}
+	return string(out)
+}
+
+func nix32Decode(s string) ([]byte, error) {
+	size := len(s) * 5 / 8
+	out := make([]byte, size)
+	for n := 0; n < len(s); n++ {
+		digit := strings.IndexByte(nix32Alphabet, s[len(s)-n-1])
+		if digit == -1 {
+			return nil, errors.New("invalid nix32 character")
+		}
+
+		bit := n * 5
+		i := bit / 8
+		j := bit % 8
+		if i >= size {
+			return nil, errors.New("invalid nix32 length")
+		}
+		out[i] |= byte(digit << j)
+		if i+1 < size {
+			out[i+1] |= byte(digit >> (8 - j))
+		} else if digit>>(8-j) != 0 {
+			return nil, errors.New("invalid nix32 padding")
+		}
+	}
+	return out, nil
+}
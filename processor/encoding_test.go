@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestEncodeDecodeDigest(t *testing.T) {
+	t.Cleanup(resetState)
+	sum := sha256.Sum256([]byte("hello\n"))
+
+	for _, e := range encodings {
+		Encoding = e
+		encoded := encodeDigest(sum[:])
+
+		if got := decodeEncoded(encoded); !bytes.Equal(got, sum[:]) {
+			t.Errorf("%s expected %x got %x", e, sum, got)
+		}
+
+		got, ok := decodeDigest(encoded, len(sum))
+		if !ok || !bytes.Equal(got, sum[:]) {
+			t.Errorf("%s decode expected %x got %x", e, sum, got)
+		}
+	}
+}
+
+func TestDecodeDigestSRI(t *testing.T) {
+	got, ok := decodeDigest("sha256-WJG1tSLV3whtD/CxEPvZ0hu0/HFjrzTQgoai6Eb2vgM=", 32)
+	sum := sha256.Sum256([]byte("hello\n"))
+	if !ok || !bytes.Equal(got, sum[:]) {
+		t.Errorf("expected %x got %x", sum, got)
+	}
+}
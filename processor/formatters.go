@@ -3,10 +3,13 @@
 package processor
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -63,20 +66,64 @@ func fileSummarize(input chan Result) (string, bool) {
 		return doAudit(input)
 	}
 
-	switch {
-	case strings.ToLower(Format) == "json":
+	if SimilarTo != "" {
+		return doSimilar(input)
+	}
+
+	if WriteSums != "" {
+		return writeSums(input)
+	}
+
+	if outputTemplate != nil {
+		return toTemplate(input)
+	}
+
+	// sqlite always writes to a file so needs a default name
+	if strings.ToLower(Format) == "sqlite" && FileOutput == "" {
+		FileOutput = "hashit.db"
+	}
+
+	return formatResults(Format, FileOutput, input)
+}
+
+// outputFormats are the formats which can be used with --format and --output-format
+var outputFormats = []string{"text", "json", "csv", "tsv", "sum", "hashdeep", "dfxml", "aide", "hashonly", "sqlite", "uri", "sri", "spdx-json", "spdx-tv", "cyclonedx-json", "in-toto"}
+
+// formatResults writes the results using the format, where output is the file
+// the results are written to or empty when they are streamed to stdout
+func formatResults(format string, output string, input chan Result) (string, bool) {
+	switch strings.ToLower(format) {
+	case "json":
 		return toJSON(input), true
-	case strings.ToLower(Format) == "hashdeep":
+	case "spdx-json":
+		return toSPDXJSON(input), true
+	case "spdx-tv":
+		return toSPDXTagValue(input), true
+	case "cyclonedx-json":
+		return toCycloneDXJSON(input), true
+	case "in-toto":
+		return toInToto(input)
+	case "csv", "tsv":
+		return toCSV(input, format, output)
+	case "hashdeep":
 		return toHashDeep(input), true
-	case strings.ToLower(Format) == "sum": // Similar to md5sum sha1sum output format
-		return toSum(input), true
-	case strings.ToLower(Format) == "hashonly":
-		return toHashOnly(input)
-	case strings.ToLower(Format) == "sqlite":
-		return toSqlite(input)
+	case "dfxml":
+		return toDFXML(input, output), true
+	case "aide":
+		return toAIDE(input, output), true
+	case "sum": // Similar to md5sum sha1sum output format
+		return toSum(input, output), true
+	case "hashonly":
+		return toHashOnly(input, output)
+	case "sqlite":
+		return toSqlite(input, output)
+	case "uri":
+		return toURI(input, output), true
+	case "sri":
+		return toSRI(input, output), true
 	}
 
-	return toText(input)
+	return toText(input, output)
 }
 
 const (
@@ -84,18 +131,38 @@ const (
 	Failed = "failed"
 )
 
-func doAudit(input chan Result) (string, bool) {
-	// open the audit file
-	file, err := os.ReadFile(AuditFile)
+// loadAuditor reads and parses the audit file so we know which hashes
+// need to be calculated before any files are processed
+func loadAuditor(auditFile string) (*Auditor, error) {
+	file, err := os.ReadFile(auditFile)
 	if err != nil {
-		printError(err.Error())
-		return "", false
+		return nil, err
 	}
 
-	hdl, err := NewAuditor(string(file))
-	if err != nil {
-		printError(err.Error())
-		return "", false
+	// AIDE databases are usually gzipped
+	if bytes.HasPrefix(file, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(file))
+		if err != nil {
+			return nil, err
+		}
+		file, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewAuditor(string(file))
+}
+
+func doAudit(input chan Result) (string, bool) {
+	hdl := auditor
+	if hdl == nil {
+		var err error
+		hdl, err = loadAuditor(AuditFile)
+		if err != nil {
+			printError(err.Error())
+			return "", false
+		}
 	}
 
 	examinedCount := 0
@@ -112,7 +179,7 @@ func doAudit(input chan Result) (string, bool) {
 	newFilesList := []Result{}
 	for res := range input {
 		examinedCount++
-		r := hdl.Find(res.File, res.MD5, res.SHA256)
+		r := hdl.Find(res)
 
 		switch r {
 		case FileMatched:
@@ -123,6 +190,9 @@ func doAudit(input chan Result) (string, bool) {
 		case FileModified:
 			if Verbose {
 				fmt.Printf("%v: File modified\n", res.File)
+				for _, c := range hdl.ChangedRanges(res) {
+					fmt.Printf("%v: Bytes %d-%d changed\n", res.File, c[0], c[1])
+				}
 			}
 			filesModified++
 			status = Failed
@@ -141,37 +211,19 @@ func doAudit(input chan Result) (string, bool) {
 	// with the above done it means we have accounted for every file in the input
 	// we now need to check which files we expected to match but did not
 	// but we also need to consider if the file was renamed or moved...
-	unmatched := hdl.GetUnmatched()
-
-	// Create a map for quick lookup of unmatched files by their combined hash
-	unmatchedByHash := make(map[string]AuditRecord)
-	for _, um := range unmatched {
-		unmatchedByHash[um.MD5+um.SHA256] = um
-	}
-
-	// Process newFilesList to identify moved files
-	var genuinelyNewFiles []Result
-	for _, res := range newFilesList {
-		hashKey := res.MD5 + res.SHA256
-		if umRecord, ok := unmatchedByHash[hashKey]; ok {
-			// This is a moved file
-			moved++
-			if Verbose {
-				fmt.Printf("%v -> %v: File moved\n", umRecord.Filename, res.File)
-			}
-			// Remove from unmatchedByHash so it's not counted as missing
-			delete(unmatchedByHash, hashKey)
-		} else {
-			// This is a genuinely new file
-			genuinelyNewFiles = append(genuinelyNewFiles, res)
+	moves, genuinelyNewFiles, missing := hdl.Moves(newFilesList)
+	moved = len(moves)
+	if Verbose {
+		for _, m := range moves {
+			fmt.Printf("%v -> %v: File moved\n", m.From.Filename, m.To.File)
 		}
 	}
 	newFiles = len(genuinelyNewFiles) // Update newFiles count
 
-	// Any remaining in unmatchedByHash are truly missing
-	filesMissing := len(unmatchedByHash)
+	// Any remaining are truly missing
+	filesMissing := len(missing)
 	if Verbose {
-		for _, um := range unmatchedByHash {
+		for _, um := range missing {
 			fmt.Printf("%v: File expected but not found\n", um.Filename)
 		}
 	}
@@ -194,7 +246,7 @@ Known files expecting: %d
 }
 
 // Mimics how md5sum sha1sum etc... work
-func toSum(input chan Result) string {
+func toSum(input chan Result, output string) string {
 	var str strings.Builder
 
 	for res := range input {
@@ -204,6 +256,33 @@ func toSum(input chan Result) string {
 		if hasHash(HashNames.XxHash64) {
 			str.WriteString(res.XxHash64 + "  " + res.File + "\n")
 		}
+		if hasHash(HashNames.CRC32C) {
+			str.WriteString(res.CRC32C + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CRC64ECMA) {
+			str.WriteString(res.CRC64ECMA + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CRC64ISO) {
+			str.WriteString(res.CRC64ISO + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.Adler32) {
+			str.WriteString(res.Adler32 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.XXH3) {
+			str.WriteString(res.XXH3 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.XXH128) {
+			str.WriteString(res.XXH128 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.Murmur3) {
+			str.WriteString(res.Murmur3 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.SipHash) {
+			str.WriteString(res.SipHash + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.HighwayHash) {
+			str.WriteString(res.HighwayHash + "  " + res.File + "\n")
+		}
 		if hasHash(HashNames.MD4) {
 			str.WriteString(res.MD4 + "  " + res.File + "\n")
 		}
@@ -216,6 +295,9 @@ func toSum(input chan Result) string {
 		if hasHash(HashNames.SHA256) {
 			str.WriteString(res.SHA256 + "  " + res.File + "\n")
 		}
+		if hasHash(HashNames.SHA384) {
+			str.WriteString(res.SHA384 + "  " + res.File + "\n")
+		}
 		if hasHash(HashNames.SHA512) {
 			str.WriteString(res.SHA512 + "  " + res.File + "\n")
 		}
@@ -240,15 +322,51 @@ func toSum(input chan Result) string {
 		if hasHash(HashNames.Sha3512) {
 			str.WriteString(res.Sha3512 + "  " + res.File + "\n")
 		}
+		if hasHash(HashNames.Shake128) {
+			str.WriteString(res.Shake128 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.Shake256) {
+			str.WriteString(res.Shake256 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CShake128) {
+			str.WriteString(res.CShake128 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CShake256) {
+			str.WriteString(res.CShake256 + "  " + res.File + "\n")
+		}
 		if hasHash(HashNames.Ed2k) {
 			str.WriteString(res.Ed2k + "  " + res.File + "\n")
 		}
+		if hasHash(HashNames.AICH) {
+			str.WriteString(res.AICH + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.TTH) {
+			str.WriteString(res.TTH + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CIDv0) {
+			str.WriteString(res.CIDv0 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.CIDv1) {
+			str.WriteString(res.CIDv1 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.GitSHA1) {
+			str.WriteString(res.GitSHA1 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.GitSHA256) {
+			str.WriteString(res.GitSHA256 + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.SSDeep) {
+			str.WriteString(res.SSDeep + "  " + res.File + "\n")
+		}
+		if hasHash(HashNames.TLSH) {
+			str.WriteString(res.TLSH + "  " + res.File + "\n")
+		}
 
 		if MTime && res.MTime != nil {
 			str.WriteString(fmt.Sprintf("%s  %s\n", res.MTime.Format("2006-01-02 15:04:05"), res.File))
 		}
 
-		if !NoStream && FileOutput == "" {
+		if !NoStream && output == "" {
 			fmt.Print(str.String())
 			str.Reset()
 		}
@@ -257,7 +375,7 @@ func toSum(input chan Result) string {
 	return str.String()
 }
 
-func toHashOnly(input chan Result) (string, bool) {
+func toHashOnly(input chan Result, output string) (string, bool) {
 	var str strings.Builder
 
 	for res := range input {
@@ -267,6 +385,33 @@ func toHashOnly(input chan Result) (string, bool) {
 		if hasHash(HashNames.XxHash64) {
 			str.WriteString(res.XxHash64 + "\n")
 		}
+		if hasHash(HashNames.CRC32C) {
+			str.WriteString(res.CRC32C + "\n")
+		}
+		if hasHash(HashNames.CRC64ECMA) {
+			str.WriteString(res.CRC64ECMA + "\n")
+		}
+		if hasHash(HashNames.CRC64ISO) {
+			str.WriteString(res.CRC64ISO + "\n")
+		}
+		if hasHash(HashNames.Adler32) {
+			str.WriteString(res.Adler32 + "\n")
+		}
+		if hasHash(HashNames.XXH3) {
+			str.WriteString(res.XXH3 + "\n")
+		}
+		if hasHash(HashNames.XXH128) {
+			str.WriteString(res.XXH128 + "\n")
+		}
+		if hasHash(HashNames.Murmur3) {
+			str.WriteString(res.Murmur3 + "\n")
+		}
+		if hasHash(HashNames.SipHash) {
+			str.WriteString(res.SipHash + "\n")
+		}
+		if hasHash(HashNames.HighwayHash) {
+			str.WriteString(res.HighwayHash + "\n")
+		}
 		if hasHash(HashNames.MD4) {
 			str.WriteString(res.MD4 + "\n")
 		}
@@ -279,6 +424,9 @@ func toHashOnly(input chan Result) (string, bool) {
 		if hasHash(HashNames.SHA256) {
 			str.WriteString(res.SHA256 + "\n")
 		}
+		if hasHash(HashNames.SHA384) {
+			str.WriteString(res.SHA384 + "\n")
+		}
 		if hasHash(HashNames.SHA512) {
 			str.WriteString(res.SHA512 + "\n")
 		}
@@ -303,15 +451,51 @@ func toHashOnly(input chan Result) (string, bool) {
 		if hasHash(HashNames.Sha3512) {
 			str.WriteString(res.Sha3512 + "\n")
 		}
+		if hasHash(HashNames.Shake128) {
+			str.WriteString(res.Shake128 + "\n")
+		}
+		if hasHash(HashNames.Shake256) {
+			str.WriteString(res.Shake256 + "\n")
+		}
+		if hasHash(HashNames.CShake128) {
+			str.WriteString(res.CShake128 + "\n")
+		}
+		if hasHash(HashNames.CShake256) {
+			str.WriteString(res.CShake256 + "\n")
+		}
 		if hasHash(HashNames.Ed2k) {
 			str.WriteString(res.Ed2k + "\n")
 		}
+		if hasHash(HashNames.AICH) {
+			str.WriteString(res.AICH + "\n")
+		}
+		if hasHash(HashNames.TTH) {
+			str.WriteString(res.TTH + "\n")
+		}
+		if hasHash(HashNames.CIDv0) {
+			str.WriteString(res.CIDv0 + "\n")
+		}
+		if hasHash(HashNames.CIDv1) {
+			str.WriteString(res.CIDv1 + "\n")
+		}
+		if hasHash(HashNames.GitSHA1) {
+			str.WriteString(res.GitSHA1 + "\n")
+		}
+		if hasHash(HashNames.GitSHA256) {
+			str.WriteString(res.GitSHA256 + "\n")
+		}
+		if hasHash(HashNames.SSDeep) {
+			str.WriteString(res.SSDeep + "\n")
+		}
+		if hasHash(HashNames.TLSH) {
+			str.WriteString(res.TLSH + "\n")
+		}
 
 		if MTime && res.MTime != nil {
 			str.WriteString(fmt.Sprintf("%s\n", res.MTime.Format("2006-01-02 15:04:05")))
 		}
 
-		if !NoStream && FileOutput == "" {
+		if !NoStream && output == "" {
 			fmt.Print(str.String())
 			str.Reset()
 		}
@@ -320,7 +504,7 @@ func toHashOnly(input chan Result) (string, bool) {
 	return str.String(), true
 }
 
-func toText(input chan Result) (string, bool) {
+func toText(input chan Result, output string) (string, bool) {
 	var str strings.Builder
 	first := true
 
@@ -333,57 +517,18 @@ func toText(input chan Result) (string, bool) {
 
 		str.WriteString(fmt.Sprintf("%s (%d bytes)\n", res.File, res.Bytes))
 
-		if hasHash(HashNames.CRC32) {
-			str.WriteString("      CRC32 " + res.CRC32 + "\n")
-		}
-		if hasHash(HashNames.XxHash64) {
-			str.WriteString("   xxHash64 " + res.XxHash64 + "\n")
-		}
-		if hasHash(HashNames.MD4) {
-			str.WriteString("        MD4 " + res.MD4 + "\n")
-		}
-		if hasHash(HashNames.MD5) {
-			str.WriteString("        MD5 " + res.MD5 + "\n")
-		}
-		if hasHash(HashNames.SHA1) {
-			str.WriteString("       SHA1 " + res.SHA1 + "\n")
-		}
-		if hasHash(HashNames.SHA256) {
-			str.WriteString("     SHA256 " + res.SHA256 + "\n")
-		}
-		if hasHash(HashNames.SHA512) {
-			str.WriteString("     SHA512 " + res.SHA512 + "\n")
-		}
-		if hasHash(HashNames.Blake2b256) {
-			str.WriteString("Blake2b-256 " + res.Blake2b256 + "\n")
-		}
-		if hasHash(HashNames.Blake2b512) {
-			str.WriteString("Blake2b-512 " + res.Blake2b512 + "\n")
-		}
-		if hasHash(HashNames.Blake3) {
-			str.WriteString("     Blake3 " + res.Blake3 + "\n")
-		}
-		if hasHash(HashNames.Sha3224) {
-			str.WriteString("   SHA3-224 " + res.Sha3224 + "\n")
-		}
-		if hasHash(HashNames.Sha3256) {
-			str.WriteString("   SHA3-256 " + res.Sha3256 + "\n")
-		}
-		if hasHash(HashNames.Sha3384) {
-			str.WriteString("   SHA3-384 " + res.Sha3384 + "\n")
-		}
-		if hasHash(HashNames.Sha3512) {
-			str.WriteString("   SHA3-512 " + res.Sha3512 + "\n")
-		}
-		if hasHash(HashNames.Ed2k) {
-			str.WriteString("       ed2k " + res.Ed2k + "\n")
-		}
+		writeTextHashes(&str, res, "")
 
 		if MTime && res.MTime != nil {
 			str.WriteString(fmt.Sprintf("      MTime %s\n", res.MTime.Format("2006-01-02 15:04:05")))
 		}
 
-		if !NoStream && FileOutput == "" {
+		for _, b := range res.Blocks {
+			str.WriteString(fmt.Sprintf("  offset %d-%d (%d bytes)\n", b.Offset, b.End(), b.Bytes))
+			writeTextHashes(&str, b.Result, "  ")
+		}
+
+		if !NoStream && output == "" {
 			fmt.Print(str.String())
 			str.Reset()
 		}
@@ -392,6 +537,122 @@ func toText(input chan Result) (string, bool) {
 	return str.String(), true
 }
 
+// writeTextHashes writes each enabled hash for the result, with indent
+// allowing the hashes of piecewise blocks to be nested under the file
+func writeTextHashes(str *strings.Builder, res Result, indent string) {
+	if hasHash(HashNames.CRC32) {
+		str.WriteString(indent + "      CRC32 " + res.CRC32 + "\n")
+	}
+	if hasHash(HashNames.XxHash64) {
+		str.WriteString(indent + "   xxHash64 " + res.XxHash64 + "\n")
+	}
+	if hasHash(HashNames.CRC32C) {
+		str.WriteString(indent + "     CRC32C " + res.CRC32C + "\n")
+	}
+	if hasHash(HashNames.CRC64ECMA) {
+		str.WriteString(indent + " CRC64-ECMA " + res.CRC64ECMA + "\n")
+	}
+	if hasHash(HashNames.CRC64ISO) {
+		str.WriteString(indent + "  CRC64-ISO " + res.CRC64ISO + "\n")
+	}
+	if hasHash(HashNames.Adler32) {
+		str.WriteString(indent + "    Adler32 " + res.Adler32 + "\n")
+	}
+	if hasHash(HashNames.XXH3) {
+		str.WriteString(indent + "       XXH3 " + res.XXH3 + "\n")
+	}
+	if hasHash(HashNames.XXH128) {
+		str.WriteString(indent + "     XXH128 " + res.XXH128 + "\n")
+	}
+	if hasHash(HashNames.Murmur3) {
+		str.WriteString(indent + "    Murmur3 " + res.Murmur3 + "\n")
+	}
+	if hasHash(HashNames.SipHash) {
+		str.WriteString(indent + "    SipHash " + res.SipHash + "\n")
+	}
+	if hasHash(HashNames.HighwayHash) {
+		str.WriteString(indent + "HighwayHash " + res.HighwayHash + "\n")
+	}
+	if hasHash(HashNames.MD4) {
+		str.WriteString(indent + "        MD4 " + res.MD4 + "\n")
+	}
+	if hasHash(HashNames.MD5) {
+		str.WriteString(indent + "        MD5 " + res.MD5 + "\n")
+	}
+	if hasHash(HashNames.SHA1) {
+		str.WriteString(indent + "       SHA1 " + res.SHA1 + "\n")
+	}
+	if hasHash(HashNames.SHA256) {
+		str.WriteString(indent + "     SHA256 " + res.SHA256 + "\n")
+	}
+	if hasHash(HashNames.SHA384) {
+		str.WriteString(indent + "     SHA384 " + res.SHA384 + "\n")
+	}
+	if hasHash(HashNames.SHA512) {
+		str.WriteString(indent + "     SHA512 " + res.SHA512 + "\n")
+	}
+	if hasHash(HashNames.Blake2b256) {
+		str.WriteString(indent + "Blake2b-256 " + res.Blake2b256 + "\n")
+	}
+	if hasHash(HashNames.Blake2b512) {
+		str.WriteString(indent + "Blake2b-512 " + res.Blake2b512 + "\n")
+	}
+	if hasHash(HashNames.Blake3) {
+		str.WriteString(indent + "     Blake3 " + res.Blake3 + "\n")
+	}
+	if hasHash(HashNames.Sha3224) {
+		str.WriteString(indent + "   SHA3-224 " + res.Sha3224 + "\n")
+	}
+	if hasHash(HashNames.Sha3256) {
+		str.WriteString(indent + "   SHA3-256 " + res.Sha3256 + "\n")
+	}
+	if hasHash(HashNames.Sha3384) {
+		str.WriteString(indent + "   SHA3-384 " + res.Sha3384 + "\n")
+	}
+	if hasHash(HashNames.Sha3512) {
+		str.WriteString(indent + "   SHA3-512 " + res.Sha3512 + "\n")
+	}
+	if hasHash(HashNames.Shake128) {
+		str.WriteString(indent + "   SHAKE128 " + res.Shake128 + "\n")
+	}
+	if hasHash(HashNames.Shake256) {
+		str.WriteString(indent + "   SHAKE256 " + res.Shake256 + "\n")
+	}
+	if hasHash(HashNames.CShake128) {
+		str.WriteString(indent + "  cSHAKE128 " + res.CShake128 + "\n")
+	}
+	if hasHash(HashNames.CShake256) {
+		str.WriteString(indent + "  cSHAKE256 " + res.CShake256 + "\n")
+	}
+	if hasHash(HashNames.Ed2k) {
+		str.WriteString(indent + "       ed2k " + res.Ed2k + "\n")
+	}
+	if hasHash(HashNames.AICH) {
+		str.WriteString(indent + "       AICH " + res.AICH + "\n")
+	}
+	if hasHash(HashNames.TTH) {
+		str.WriteString(indent + "        TTH " + res.TTH + "\n")
+	}
+	if hasHash(HashNames.CIDv0) {
+		str.WriteString(indent + "      CIDv0 " + res.CIDv0 + "\n")
+	}
+	if hasHash(HashNames.CIDv1) {
+		str.WriteString(indent + "      CIDv1 " + res.CIDv1 + "\n")
+	}
+	if hasHash(HashNames.GitSHA1) {
+		str.WriteString(indent + "   Git-SHA1 " + res.GitSHA1 + "\n")
+	}
+	if hasHash(HashNames.GitSHA256) {
+		str.WriteString(indent + " Git-SHA256 " + res.GitSHA256 + "\n")
+	}
+	if hasHash(HashNames.SSDeep) {
+		str.WriteString(indent + "     ssdeep " + res.SSDeep + "\n")
+	}
+	if hasHash(HashNames.TLSH) {
+		str.WriteString(indent + "       TLSH " + res.TLSH + "\n")
+	}
+}
+
 func toJSON(input chan Result) string {
 	results := []Result{}
 	for res := range input {
@@ -412,13 +673,16 @@ func toHashDeep(input chan Result) string {
 	}
 
 	str.WriteString("%%%% HASHDEEP-1.0\n")
-	if !contains(Hash, "sha256") && !contains(Hash, "all") {
-		str.WriteString("%%%% size,md5,filename")
-	} else if contains(Hash, "sha256") && !contains(Hash, "all") && !contains(Hash, "md5") {
-		str.WriteString("%%%% size,sha256,filename")
-	} else {
-		str.WriteString("%%%% size,md5,sha256,filename")
+	str.WriteString("%%%% size,")
+	for _, h := range enabledHashes() {
+		// extendable-output hashes include their length so they can be audited at the same length
+		if bits, ok := HashLengths[h]; ok {
+			str.WriteString(fmt.Sprintf("%s:%d,", h, bits))
+		} else {
+			str.WriteString(h + ",")
+		}
 	}
+	str.WriteString("filename")
 
 	if MTime {
 		str.WriteString(",mtime")
@@ -431,40 +695,45 @@ func toHashDeep(input chan Result) string {
 	str.WriteString("##\n")
 
 	for res := range input {
-		// Bytes first, always the same.
-		str.WriteString(fmt.Sprintf("%d,", res.Bytes))
-
-		// Follows the same logic as the headers.
-		if !contains(Hash, "sha256") && !contains(Hash, "all") {
-			str.WriteString(fmt.Sprintf("%s,", res.MD5))
-		} else if contains(Hash, "sha256") && !contains(Hash, "all") && !contains(Hash, "md5") {
-			str.WriteString(fmt.Sprintf("%s,", res.SHA256))
-		} else {
-			str.WriteString(fmt.Sprintf("%s,%s,", res.MD5, res.SHA256))
-		}
-
-		// Finish with filename and newline.
-		str.WriteString(fmt.Sprintf("%s", res.File))
-
-		if MTime {
-			str.WriteString(",")
-			if res.MTime != nil {
-				str.WriteString(fmt.Sprintf("%s", res.MTime.Format("2006-01-02 15:04:05")))
+		// piecewise hashing writes a line for each block in place of the file
+		if len(res.Blocks) != 0 {
+			for _, b := range res.Blocks {
+				b.File = pieceFilename(res.File, b)
+				b.MTime = res.MTime
+				writeHashDeepLine(&str, b.Result)
 			}
+			continue
 		}
 
-		str.WriteString("\n")
+		writeHashDeepLine(&str, res)
 	}
 
 	return str.String()
 }
 
-func toSqlite(input chan Result) (string, bool) {
-	// if not file output specified we need to do it ourselves
-	if FileOutput == "" {
-		FileOutput = "hashit.db"
+func writeHashDeepLine(str *strings.Builder, res Result) {
+	// Bytes first, always the same.
+	str.WriteString(fmt.Sprintf("%d,", res.Bytes))
+
+	// Follows the same order as the headers.
+	for _, h := range enabledHashes() {
+		str.WriteString(fmt.Sprintf("%s,", res.Digest(h)))
 	}
 
+	// Finish with filename and newline.
+	str.WriteString(fmt.Sprintf("%s", res.File))
+
+	if MTime {
+		str.WriteString(",")
+		if res.MTime != nil {
+			str.WriteString(fmt.Sprintf("%s", res.MTime.Format("2006-01-02 15:04:05")))
+		}
+	}
+
+	str.WriteString("\n")
+}
+
+func toSqlite(input chan Result, output string) (string, bool) {
 	// handle sql conversions where null
 	toSqlNull := func(input string) sql.NullString {
 		if input == "" {
@@ -478,9 +747,9 @@ func toSqlite(input chan Result) (string, bool) {
 		}
 	}
 
-	db, err := connectSqliteDb(FileOutput)
+	db, err := connectSqliteDb(output)
 	if err != nil {
-		printError(fmt.Sprintf("problem connecting to db %s", FileOutput))
+		printError(fmt.Sprintf("problem connecting to db %s", output))
 		return "", false
 	}
 	defer func(db *sql.DB) {
@@ -492,7 +761,7 @@ func toSqlite(input chan Result) (string, bool) {
 
 	tx, err := db.BeginTx(context.Background(), nil)
 	if err != nil {
-		printError(fmt.Sprintf("problem with tx %s", FileOutput))
+		printError(fmt.Sprintf("problem with tx %s", output))
 		return "", false
 	}
 	withTx := queries.WithTx(tx)
@@ -506,30 +775,108 @@ func toSqlite(input chan Result) (string, bool) {
 		}
 
 		_, err = withTx.FileHashInsertReplace(context.Background(), database.FileHashInsertReplaceParams{
-			Filepath:   res.File,
-			Crc32:      toSqlNull(res.CRC32),
-			Xxhash64:   toSqlNull(res.XxHash64),
-			Md4:        toSqlNull(res.MD4),
-			Md5:        toSqlNull(res.MD5),
-			Sha1:       toSqlNull(res.SHA1),
-			Sha256:     toSqlNull(res.SHA256),
-			Sha512:     toSqlNull(res.SHA512),
-			Blake2b256: toSqlNull(res.Blake2b256),
-			Blake2b512: toSqlNull(res.Blake2b512),
-			Blake3:     toSqlNull(res.Blake3),
-			Sha3224:    toSqlNull(res.Sha3224),
-			Sha3256:    toSqlNull(res.Sha3256),
-			Sha3384:    toSqlNull(res.Sha3384),
-			Sha3512:    toSqlNull(res.Sha3512),
-			Ed2k:       toSqlNull(res.Ed2k),
-			Size:       res.Bytes,
-			Mtime:      toSqlNull(mtime),
+			Filepath:    res.File,
+			Crc32:       toSqlNull(res.CRC32),
+			Xxhash64:    toSqlNull(res.XxHash64),
+			Crc32c:      toSqlNull(res.CRC32C),
+			Crc64Ecma:   toSqlNull(res.CRC64ECMA),
+			Crc64Iso:    toSqlNull(res.CRC64ISO),
+			Adler32:     toSqlNull(res.Adler32),
+			Xxh3:        toSqlNull(res.XXH3),
+			Xxh128:      toSqlNull(res.XXH128),
+			Murmur3:     toSqlNull(res.Murmur3),
+			Siphash:     toSqlNull(res.SipHash),
+			Highwayhash: toSqlNull(res.HighwayHash),
+			Md4:         toSqlNull(res.MD4),
+			Md5:         toSqlNull(res.MD5),
+			Sha1:        toSqlNull(res.SHA1),
+			Sha256:      toSqlNull(res.SHA256),
+			Sha384:      toSqlNull(res.SHA384),
+			Sha512:      toSqlNull(res.SHA512),
+			Blake2b256:  toSqlNull(res.Blake2b256),
+			Blake2b512:  toSqlNull(res.Blake2b512),
+			Blake3:      toSqlNull(res.Blake3),
+			Sha3224:     toSqlNull(res.Sha3224),
+			Sha3256:     toSqlNull(res.Sha3256),
+			Sha3384:     toSqlNull(res.Sha3384),
+			Sha3512:     toSqlNull(res.Sha3512),
+			Shake128:    toSqlNull(res.Shake128),
+			Shake256:    toSqlNull(res.Shake256),
+			Cshake128:   toSqlNull(res.CShake128),
+			Cshake256:   toSqlNull(res.CShake256),
+			Ed2k:        toSqlNull(res.Ed2k),
+			Aich:        toSqlNull(res.AICH),
+			Tth:         toSqlNull(res.TTH),
+			Cidv0:       toSqlNull(res.CIDv0),
+			Cidv1:       toSqlNull(res.CIDv1),
+			GitSha1:     toSqlNull(res.GitSHA1),
+			GitSha256:   toSqlNull(res.GitSHA256),
+			Ssdeep:      toSqlNull(res.SSDeep),
+			Tlsh:        toSqlNull(res.TLSH),
+			Size:        res.Bytes,
+			Mtime:       toSqlNull(mtime),
 		})
 		if err != nil {
 			printError(err.Error())
 			return "", false
 		}
 
+		// remove any blocks from a previous run as the file may have changed size
+		err = withTx.FileBlockDeleteByFilePath(context.Background(), res.File)
+		if err != nil {
+			printError(err.Error())
+			return "", false
+		}
+
+		for _, b := range res.Blocks {
+			err = withTx.FileBlockInsertReplace(context.Background(), database.FileBlockInsertReplaceParams{
+				Filepath:    res.File,
+				ByteOffset:  b.Offset,
+				Crc32:       toSqlNull(b.CRC32),
+				Xxhash64:    toSqlNull(b.XxHash64),
+				Crc32c:      toSqlNull(b.CRC32C),
+				Crc64Ecma:   toSqlNull(b.CRC64ECMA),
+				Crc64Iso:    toSqlNull(b.CRC64ISO),
+				Adler32:     toSqlNull(b.Adler32),
+				Xxh3:        toSqlNull(b.XXH3),
+				Xxh128:      toSqlNull(b.XXH128),
+				Murmur3:     toSqlNull(b.Murmur3),
+				Siphash:     toSqlNull(b.SipHash),
+				Highwayhash: toSqlNull(b.HighwayHash),
+				Md4:         toSqlNull(b.MD4),
+				Md5:         toSqlNull(b.MD5),
+				Sha1:        toSqlNull(b.SHA1),
+				Sha256:      toSqlNull(b.SHA256),
+				Sha384:      toSqlNull(b.SHA384),
+				Sha512:      toSqlNull(b.SHA512),
+				Blake2b256:  toSqlNull(b.Blake2b256),
+				Blake2b512:  toSqlNull(b.Blake2b512),
+				Blake3:      toSqlNull(b.Blake3),
+				Sha3224:     toSqlNull(b.Sha3224),
+				Sha3256:     toSqlNull(b.Sha3256),
+				Sha3384:     toSqlNull(b.Sha3384),
+				Sha3512:     toSqlNull(b.Sha3512),
+				Shake128:    toSqlNull(b.Shake128),
+				Shake256:    toSqlNull(b.Shake256),
+				Cshake128:   toSqlNull(b.CShake128),
+				Cshake256:   toSqlNull(b.CShake256),
+				Ed2k:        toSqlNull(b.Ed2k),
+				Aich:        toSqlNull(b.AICH),
+				Tth:         toSqlNull(b.TTH),
+				Cidv0:       toSqlNull(b.CIDv0),
+				Cidv1:       toSqlNull(b.CIDv1),
+				GitSha1:     toSqlNull(b.GitSHA1),
+				GitSha256:   toSqlNull(b.GitSHA256),
+				Ssdeep:      toSqlNull(b.SSDeep),
+				Tlsh:        toSqlNull(b.TLSH),
+				Size:        b.Bytes,
+			})
+			if err != nil {
+				printError(err.Error())
+				return "", false
+			}
+		}
+
 		if count >= 1000 {
 			count = 0
 
@@ -567,10 +914,20 @@ func toSqlite(input chan Result) (string, bool) {
 func printHashes() {
 	fmt.Printf("      CRC32 (%s)\n", HashNames.CRC32)
 	fmt.Printf("   xxHash64 (%s)\n", HashNames.XxHash64)
+	fmt.Printf("     CRC32C (%s)\n", HashNames.CRC32C)
+	fmt.Printf(" CRC64-ECMA (%s)\n", HashNames.CRC64ECMA)
+	fmt.Printf("  CRC64-ISO (%s)\n", HashNames.CRC64ISO)
+	fmt.Printf("    Adler32 (%s)\n", HashNames.Adler32)
+	fmt.Printf("       XXH3 (%s)\n", HashNames.XXH3)
+	fmt.Printf("     XXH128 (%s)\n", HashNames.XXH128)
+	fmt.Printf("    Murmur3 (%s)\n", HashNames.Murmur3)
+	fmt.Printf("    SipHash (%s)\n", HashNames.SipHash)
+	fmt.Printf("HighwayHash (%s)\n", HashNames.HighwayHash)
 	fmt.Printf("        MD4 (%s)\n", HashNames.MD4)
 	fmt.Printf("        MD5 (%s)\n", HashNames.MD5)
 	fmt.Printf("       SHA1 (%s)\n", HashNames.SHA1)
 	fmt.Printf("     SHA256 (%s)\n", HashNames.SHA256)
+	fmt.Printf("     SHA384 (%s)\n", HashNames.SHA384)
 	fmt.Printf("     SHA512 (%s)\n", HashNames.SHA512)
 	fmt.Printf("Blake2b-256 (%s)\n", HashNames.Blake2b256)
 	fmt.Printf("Blake2b-512 (%s)\n", HashNames.Blake2b512)
@@ -579,7 +936,19 @@ func printHashes() {
 	fmt.Printf("   SHA3-256 (%s)\n", HashNames.Sha3256)
 	fmt.Printf("   SHA3-384 (%s)\n", HashNames.Sha3384)
 	fmt.Printf("   SHA3-512 (%s)\n", HashNames.Sha3512)
+	fmt.Printf("   SHAKE128 (%s)\n", HashNames.Shake128)
+	fmt.Printf("   SHAKE256 (%s)\n", HashNames.Shake256)
+	fmt.Printf("  cSHAKE128 (%s)\n", HashNames.CShake128)
+	fmt.Printf("  cSHAKE256 (%s)\n", HashNames.CShake256)
 	fmt.Printf("       ed2k (%s)\n", HashNames.Ed2k)
+	fmt.Printf("       AICH (%s)\n", HashNames.AICH)
+	fmt.Printf("        TTH (%s)\n", HashNames.TTH)
+	fmt.Printf("      CIDv0 (%s)\n", HashNames.CIDv0)
+	fmt.Printf("      CIDv1 (%s)\n", HashNames.CIDv1)
+	fmt.Printf("   Git-SHA1 (%s)\n", HashNames.GitSHA1)
+	fmt.Printf(" Git-SHA256 (%s)\n", HashNames.GitSHA256)
+	fmt.Printf("     ssdeep (%s)\n", HashNames.SSDeep)
+	fmt.Printf("       TLSH (%s)\n", HashNames.TLSH)
 }
 
 func contains(list []string, v string) bool {
@@ -609,6 +978,13 @@ pragma foreign_keys = on;`)
 	}
 
 	_, err = db.Exec(assets.Migrations)
+	if err != nil {
+		_ = db.Close()
+		printError("migrations issue " + err.Error())
+		return db, err
+	}
+
+	err = upgradeSqliteDb(db)
 	if err != nil {
 		_ = db.Close()
 		printError("migrations issue " + err.Error())
@@ -616,3 +992,84 @@ pragma foreign_keys = on;`)
 
 	return db, err
 }
+
+// sqliteTables are the tables created by the migrations
+var sqliteTables = []string{"file_hashes", "file_blocks"}
+
+// sqliteColumn is a column of a table as reported by table_info
+type sqliteColumn struct {
+	name    string
+	kind    string
+	notNull bool
+}
+
+// upgradeSqliteDb adds any columns missing from a database written by an
+// older version of hashit, as the migrations only create tables which do not exist
+func upgradeSqliteDb(db *sql.DB) error {
+	for _, table := range sqliteTables {
+		missing, err := sqliteMissingColumns(db, table)
+		if err != nil {
+			return err
+		}
+		for _, c := range missing {
+			if c.notNull {
+				return fmt.Errorf("unable to add column %s to %s as it cannot be null", c.name, table)
+			}
+			if _, err := db.Exec(fmt.Sprintf("alter table %s add column %s %s", table, c.name, c.kind)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sqliteMissingColumns returns the columns the migrations give the table which
+// it does not have in the database
+func sqliteMissingColumns(db *sql.DB, table string) ([]sqliteColumn, error) {
+	schema, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer schema.Close()
+	schema.SetMaxOpenConns(1) // every connection has its own in memory database
+	if _, err := schema.Exec(assets.Migrations); err != nil {
+		return nil, err
+	}
+
+	expected, err := sqliteTableColumns(schema, table)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := sqliteTableColumns(db, table)
+	if err != nil {
+		return nil, err
+	}
+	have := map[string]bool{}
+	for _, c := range existing {
+		have[c.name] = true
+	}
+	var missing []sqliteColumn
+	for _, c := range expected {
+		if !have[c.name] {
+			missing = append(missing, c)
+		}
+	}
+	return missing, nil
+}
+
+func sqliteTableColumns(db *sql.DB, table string) ([]sqliteColumn, error) {
+	rows, err := db.Query("select name, type, \"notnull\" from pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []sqliteColumn
+	for rows.Next() {
+		var c sqliteColumn
+		if err := rows.Scan(&c.name, &c.kind, &c.notNull); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,257 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gitBlobState calculates the ID git gives a file, which is the hash of the
+// content prefixed with a "blob <size>\0" header. The size needs to be known
+// before any content is written, so where it is not such as for standard input
+// the content is held until summed.
+type gitBlobState struct {
+	newHash func() hash.Hash
+	h       hash.Hash
+	size    int64
+	content []byte
+}
+
+func newGitSHA1(size int64) *gitBlobState {
+	return newGitBlob(sha1.New, size)
+}
+
+func newGitSHA256(size int64) *gitBlobState {
+	return newGitBlob(sha256.New, size)
+}
+
+func newGitBlob(newHash func() hash.Hash, size int64) *gitBlobState {
+	g := &gitBlobState{newHash: newHash, size: size}
+	g.Reset()
+	return g
+}
+
+func gitObjectHeader(kind string, size int64) []byte {
+	return []byte(kind + " " + strconv.FormatInt(size, 10) + "\x00")
+}
+
+func (g *gitBlobState) Write(p []byte) (int, error) {
+	if g.size < 0 {
+		g.content = append(g.content, p...)
+		return len(p), nil
+	}
+	return g.h.Write(p)
+}
+
+func (g *gitBlobState) Sum(b []byte) []byte {
+	if g.size < 0 {
+		return append(b, gitObjectID(g.newHash, "blob", g.content)...)
+	}
+	return g.h.Sum(b)
+}
+
+func (g *gitBlobState) Reset() {
+	g.h = g.newHash()
+	g.content = nil
+	if g.size >= 0 {
+		_, _ = g.h.Write(gitObjectHeader("blob", g.size))
+	}
+}
+
+func (g *gitBlobState) Size() int {
+	return g.h.Size()
+}
+
+func (g *gitBlobState) BlockSize() int {
+	return g.h.BlockSize()
+}
+
+// gitObjectID returns the ID of a git object of the given kind
+func gitObjectID(newHash func() hash.Hash, kind string, content []byte) []byte {
+	h := newHash()
+	_, _ = h.Write(gitObjectHeader(kind, int64(len(content))))
+	_, _ = h.Write(content)
+	return h.Sum(nil)
+}
+
+// gitTreeEntry is a file or directory within a git tree
+type gitTreeEntry struct {
+	mode string
+	name string
+	id   []byte
+}
+
+// gitTreeContent encodes the entries as a git tree object, which git sorts
+// as though directory names end with a slash
+func gitTreeContent(entries []gitTreeEntry) []byte {
+	sortName := func(e gitTreeEntry) string {
+		if e.mode == "40000" {
+			return e.name + "/"
+		}
+		return e.name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
+	})
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.WriteString(e.mode + " " + e.name + "\x00")
+		buf.Write(e.id)
+	}
+	return buf.Bytes()
+}
+
+// gitFileMode returns the mode git records for the file and if it is a
+// symlink the target, as git stores the target rather than the content
+func gitFileMode(file string) (string, string) {
+	fi, err := os.Lstat(file)
+	if err != nil {
+		return "100644", ""
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(file)
+		if err == nil {
+			return "120000", target
+		}
+	}
+	if fi.Mode()&0111 != 0 {
+		return "100755", ""
+	}
+	return "100644", ""
+}
+
+// gitSymlink replaces the blob IDs of a symlink with those of its target
+// path, as file processing follows the link and hashes the content
+func gitSymlink(res Result) Result {
+	mode, target := gitFileMode(res.File)
+	if mode != "120000" {
+		return res
+	}
+
+	if readHash(HashNames.GitSHA1) {
+		res.GitSHA1 = encodeDigest(gitObjectID(sha1.New, "blob", []byte(target)))
+	}
+	if readHash(HashNames.GitSHA256) {
+		res.GitSHA256 = encodeDigest(gitObjectID(sha256.New, "blob", []byte(target)))
+	}
+	return res
+}
+
+// gitDir holds the results of the files found under a directory while
+// building the trees
+type gitDir struct {
+	files map[string]Result
+	dirs  map[string]*gitDir
+}
+
+func newGitDir() *gitDir {
+	return &gitDir{files: map[string]Result{}, dirs: map[string]*gitDir{}}
+}
+
+// treeResults passes through every result and once all files have been
+// processed adds a result for each directory holding its git tree IDs.
+// Directories with nothing in them are skipped as git does not track them.
+func treeResults(input chan Result) chan Result {
+	output := make(chan Result, FileListQueueSize)
+
+	go func() {
+		var results []Result
+		for res := range input {
+			res = gitSymlink(res)
+			results = append(results, res)
+			output <- res
+		}
+
+		for _, root := range DirFilePaths {
+			root = filepath.Clean(root)
+			if fi, err := os.Stat(root); err != nil || !fi.IsDir() {
+				continue
+			}
+
+			for _, res := range gitTrees(root, results) {
+				output <- res
+			}
+		}
+		close(output)
+	}()
+
+	return output
+}
+
+// gitTrees returns a result for every directory under the root containing
+// files, children before their parents so the root is always last
+func gitTrees(root string, results []Result) []Result {
+	top := newGitDir()
+	for _, res := range results {
+		rel, err := filepath.Rel(root, res.File)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		// the repository itself is never part of a tree
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if contains(parts, ".git") {
+			continue
+		}
+
+		dir := top
+		for _, p := range parts[:len(parts)-1] {
+			if dir.dirs[p] == nil {
+				dir.dirs[p] = newGitDir()
+			}
+			dir = dir.dirs[p]
+		}
+		dir.files[parts[len(parts)-1]] = res
+	}
+
+	var trees []Result
+	top.build(root, &trees)
+	return trees
+}
+
+// build calculates the trees below and then for this directory, returning
+// the result for the directory
+func (d *gitDir) build(path string, trees *[]Result) Result {
+	var sha1Entries, sha256Entries []gitTreeEntry
+	tree := Result{File: path + string(filepath.Separator)}
+
+	// sorted so the directories are always output in the same order
+	names := make([]string, 0, len(d.dirs))
+	for name := range d.dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		res := d.dirs[name].build(filepath.Join(path, name), trees)
+		tree.Bytes += res.Bytes
+		sha1Entries = append(sha1Entries, gitTreeEntry{mode: "40000", name: name, id: decodeEncoded(res.GitSHA1)})
+		sha256Entries = append(sha256Entries, gitTreeEntry{mode: "40000", name: name, id: decodeEncoded(res.GitSHA256)})
+	}
+
+	for name, res := range d.files {
+		mode, _ := gitFileMode(res.File)
+		tree.Bytes += res.Bytes
+		sha1Entries = append(sha1Entries, gitTreeEntry{mode: mode, name: name, id: decodeEncoded(res.GitSHA1)})
+		sha256Entries = append(sha256Entries, gitTreeEntry{mode: mode, name: name, id: decodeEncoded(res.GitSHA256)})
+	}
+
+	if readHash(HashNames.GitSHA1) {
+		tree.GitSHA1 = encodeDigest(gitObjectID(sha1.New, "tree", gitTreeContent(sha1Entries)))
+	}
+	if readHash(HashNames.GitSHA256) {
+		tree.GitSHA256 = encodeDigest(gitObjectID(sha256.New, "tree", gitTreeContent(sha256Entries)))
+	}
+
+	*trees = append(*trees, tree)
+	return tree
+}
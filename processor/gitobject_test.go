@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitBlob(t *testing.T) {
+	content := []byte("hello\n")
+	expected := "ce013625030ba8dba906f756967f9e9ca394464a"
+
+	// the size is known for files but not for standard input
+	for _, size := range []int64{int64(len(content)), -1} {
+		d := newGitSHA1(size)
+		_, _ = d.Write(content)
+		if got := hex.EncodeToString(d.Sum(nil)); got != expected {
+			t.Errorf("Expected %s got %s for size %d", expected, got, size)
+		}
+	}
+}
+
+func TestGitTrees(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"git-sha1"}
+	root := t.TempDir()
+	files := map[string]string{
+		"a.txt":    "hello\n",
+		"sub/b":    "x",
+		"sub-file": "y",
+		"sub.d/c":  "z\n",
+	}
+
+	var results []Result
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		_ = os.MkdirAll(filepath.Dir(p), 0755)
+		_ = os.WriteFile(p, []byte(content), 0644)
+
+		d := newGitSHA1(int64(len(content)))
+		_, _ = d.Write([]byte(content))
+		results = append(results, Result{File: p, Bytes: int64(len(content)), GitSHA1: hex.EncodeToString(d.Sum(nil))})
+	}
+
+	trees := gitTrees(root, results)
+	if len(trees) != 3 {
+		t.Fatalf("Expected 3 trees got %d", len(trees))
+	}
+
+	// git sorts sub as sub/ so it goes after sub-file and sub.d
+	expected := "997a576f3b4278b0d62d0ed858305e92d3ecc907"
+	if trees[2].GitSHA1 != expected {
+		t.Errorf("Expected %s got %s", expected, trees[2].GitSHA1)
+	}
+	if trees[2].Bytes != 10 {
+		t.Errorf("Expected 10 bytes got %d", trees[2].Bytes)
+	}
+}
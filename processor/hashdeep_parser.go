@@ -3,6 +3,11 @@ package processor
 import (
 	"bufio"
 	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -10,10 +15,12 @@ import (
 // but is designed to work with other formats in the future
 type AuditRecord struct {
 	Size     string
-	MD5      string
-	SHA256   string
+	Hashes   map[string]string // hash name to digest for every supported hash in the audit file
 	Filename string
 	Matched  bool
+	Offset   int64         // first byte of the block when this is a piecewise block
+	End      int64         // last byte of the block when this is a piecewise block
+	Blocks   []AuditRecord // piecewise blocks for the file in offset order
 }
 
 // Auditor parses and holds a audit file which can then be used for audit purposes
@@ -21,18 +28,36 @@ type AuditRecord struct {
 type Auditor struct {
 	fileLookup map[string]AuditRecord   // filename optimised lookup
 	md5Lookup  map[string][]AuditRecord // md5 optimised lookup
+	hashes     []string                 // supported hashes found in the audit file header
+	lengths    map[string]int           // digest length in bits of any extendable-output hashes
+	pieceSize  int64                    // size of the blocks if the audit file is piecewise
+	absolute   bool                     // file names are absolute paths as AIDE records them
 }
 
-// TODO modify to accept multiple types
+// NewAuditor parses the audit file which may be hashdeep or DFXML output or an AIDE database
 func NewAuditor(input string) (*Auditor, error) {
-	hdl := Auditor{}
+	hdl := Auditor{
+		lengths: map[string]int{},
+	}
+
+	parse := hdl.parseHashdeepFile
+	switch trimmed := strings.TrimSpace(input); {
+	case strings.HasPrefix(trimmed, "<"):
+		parse = hdl.parseDFXMLFile
+	case strings.HasPrefix(trimmed, "@@begin_db"):
+		parse = hdl.parseAIDEFile
+	}
 
-	file, err := hdl.parseHashdeepFile(input)
+	file, err := parse(input)
 	if err != nil {
 		return nil, err
 	}
 	hdl.fileLookup = file
 
+	if len(hdl.hashes) == 0 {
+		return nil, errors.New("audit file does not contain any supported hashes")
+	}
+
 	return &hdl, nil
 }
 
@@ -50,15 +75,43 @@ func (hdl *Auditor) Count() int {
 	return len(hdl.fileLookup)
 }
 
-func (hdl *Auditor) Find(file, md5, sha256 string) FileStatus {
-	r, ok := hdl.fileLookup[file]
+// Hashes returns the hashes the audit file contains which are the ones
+// that need to be calculated in order to audit against it
+func (hdl *Auditor) Hashes() []string {
+	return hdl.hashes
+}
+
+// Lengths returns the digest lengths in bits of any extendable-output hashes
+// in the audit file so they can be calculated at the same length
+func (hdl *Auditor) Lengths() map[string]int {
+	return hdl.lengths
+}
+
+// PieceSize returns the block size used when the audit file was created with
+// piecewise hashing, or 0 if it contains whole file hashes
+func (hdl *Auditor) PieceSize() int64 {
+	return hdl.pieceSize
+}
+
+// lookupName is the name the file is recorded under in the audit file
+func (hdl *Auditor) lookupName(file string) string {
+	if hdl.absolute {
+		if abs, err := filepath.Abs(file); err == nil {
+			return abs
+		}
+	}
+	return file
+}
+
+func (hdl *Auditor) Find(res Result) FileStatus {
+	name := hdl.lookupName(res.File)
+	r, ok := hdl.fileLookup[name]
 	if ok {
 		r.Matched = true
-		hdl.fileLookup[file] = r
+		hdl.fileLookup[name] = r
 
 		// ok file exists, check if the hash's match
-		if r.MD5 == md5 && r.SHA256 == sha256 {
-
+		if hdl.RecordKey(r) == hdl.recordedKey(r, res) {
 			return FileMatched
 		}
 
@@ -69,9 +122,10 @@ func (hdl *Auditor) Find(file, md5, sha256 string) FileStatus {
 	return FileNew
 }
 
-func (hdl *Auditor) FindByHash(md5, sha256 string) FileStatus {
+func (hdl *Auditor) FindByHash(res Result) FileStatus {
+	key := hdl.ResultKey(res)
 	for _, r := range hdl.fileLookup {
-		if r.MD5 == md5 && r.SHA256 == sha256 {
+		if hdl.RecordKey(r) == key {
 			return FileMoved
 		}
 	}
@@ -79,6 +133,101 @@ func (hdl *Auditor) FindByHash(md5, sha256 string) FileStatus {
 	return FileNew
 }
 
+// RecordKey combines all of the audited hashes of a record into a single
+// value which can be used to compare or look up files by content. Piecewise
+// records are keyed using the hashes of every block.
+func (hdl *Auditor) RecordKey(r AuditRecord) string {
+	if len(r.Blocks) == 0 {
+		return hdl.hashKey(func(h string) string { return r.Hashes[h] })
+	}
+
+	var sb strings.Builder
+	for _, b := range r.Blocks {
+		sb.WriteString(hdl.RecordKey(b))
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+// ResultKey is the same as RecordKey but for a result we have processed
+func (hdl *Auditor) ResultKey(res Result) string {
+	if len(res.Blocks) == 0 {
+		return hdl.hashKey(res.Digest)
+	}
+
+	var sb strings.Builder
+	for _, b := range res.Blocks {
+		sb.WriteString(hdl.ResultKey(b.Result))
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+// recordedKey is the ResultKey using only the hashes the record has, as an
+// AIDE rule group may not include every hash in the database
+func (hdl *Auditor) recordedKey(r AuditRecord, res Result) string {
+	if len(r.Blocks) != 0 {
+		return hdl.ResultKey(res)
+	}
+
+	return hdl.hashKey(func(h string) string {
+		if _, ok := r.Hashes[h]; !ok {
+			return ""
+		}
+		return res.Digest(h)
+	})
+}
+
+func (hdl *Auditor) hashKey(digest func(string) string) string {
+	var sb strings.Builder
+	for _, h := range hdl.hashes {
+		sb.WriteString(digest(h))
+		sb.WriteString(",")
+	}
+	return sb.String()
+}
+
+// ChangedRanges compares the blocks of a piecewise record against those of the result
+// returning the byte ranges which differ, with neighbouring changed blocks merged
+func (hdl *Auditor) ChangedRanges(res Result) [][2]int64 {
+	r, ok := hdl.fileLookup[hdl.lookupName(res.File)]
+	if !ok || len(r.Blocks) == 0 {
+		return nil
+	}
+
+	var ranges [][2]int64
+	add := func(start, end int64) {
+		if n := len(ranges); n != 0 && ranges[n-1][1]+1 >= start {
+			ranges[n-1][1] = max(ranges[n-1][1], end)
+			return
+		}
+		ranges = append(ranges, [2]int64{start, end})
+	}
+
+	expected := map[int64]AuditRecord{}
+	for _, b := range r.Blocks {
+		expected[b.Offset] = b
+	}
+
+	seen := map[int64]bool{}
+	for _, b := range res.Blocks {
+		seen[b.Offset] = true
+		e, ok := expected[b.Offset]
+		if !ok || e.End != b.End() || hdl.RecordKey(e) != hdl.ResultKey(b.Result) {
+			add(b.Offset, max(b.End(), e.End))
+		}
+	}
+
+	// anything left over was removed from the end of the file
+	for _, b := range r.Blocks {
+		if !seen[b.Offset] {
+			add(b.Offset, b.End)
+		}
+	}
+
+	return ranges
+}
+
 func (hdl *Auditor) GetUnmatched() []AuditRecord {
 	unmatched := []AuditRecord{}
 	for _, r := range hdl.fileLookup {
@@ -89,6 +238,72 @@ func (hdl *Auditor) GetUnmatched() []AuditRecord {
 	return unmatched
 }
 
+// AuditMove is a file from the audit which was found under another name
+type AuditMove struct {
+	From AuditRecord
+	To   Result
+}
+
+// Moves matches the files which were not in the audit against the records
+// which were not found by content, returning the files which were moved along
+// with those which are genuinely new and the records which are missing
+func (hdl *Auditor) Moves(newFiles []Result) ([]AuditMove, []Result, []AuditRecord) {
+	unmatched := hdl.GetUnmatched()
+	sort.Slice(unmatched, func(i, j int) bool {
+		return unmatched[i].Filename < unmatched[j].Filename
+	})
+
+	// records with the same content are kept in order so each can only be moved once
+	unmatchedByHash := map[string][]AuditRecord{}
+	for _, um := range unmatched {
+		key := hdl.RecordKey(um)
+		unmatchedByHash[key] = append(unmatchedByHash[key], um)
+	}
+
+	var moves []AuditMove
+	var genuinelyNew []Result
+	for _, res := range newFiles {
+		key := hdl.ResultKey(res)
+		if records := unmatchedByHash[key]; len(records) != 0 {
+			moves = append(moves, AuditMove{From: records[0], To: res})
+			unmatchedByHash[key] = records[1:]
+		} else {
+			genuinelyNew = append(genuinelyNew, res)
+		}
+	}
+
+	var missing []AuditRecord
+	for _, records := range unmatchedByHash {
+		missing = append(missing, records...)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].Filename < missing[j].Filename
+	})
+
+	return moves, genuinelyNew, missing
+}
+
+// normalizeDigest decodes a digest written using any of the supported encodings
+// returning it as lowercase hex, which is how results are compared when auditing
+func (hdl *Auditor) normalizeDigest(name string, digest string) string {
+	size := digestSizes[name]
+	if bits := hdl.lengths[name]; bits != 0 {
+		size = bits / 8
+	}
+
+	b, ok := decodeDigest(digest, size)
+	if !ok {
+		return strings.ToLower(digest)
+	}
+
+	// without a length in the header work it out from the digest itself
+	if _, ok := defaultHashLengths[name]; ok && hdl.lengths[name] == 0 {
+		hdl.lengths[name] = len(b) * 8
+	}
+
+	return hex.EncodeToString(b)
+}
+
 // parseHashdeepFile accepts a hashdeep format in and builds the internal
 // audit processor on it
 func (hdl *Auditor) parseHashdeepFile(input string) (map[string]AuditRecord, error) {
@@ -112,6 +327,23 @@ func (hdl *Auditor) parseHashdeepFile(input string) (map[string]AuditRecord, err
 			if strings.Contains(line, "size") {
 				header = strings.Split(strings.TrimPrefix(line, "%%%% "), ",")
 				csvStarted = true
+
+				// hashdeep names its hashes the same way we do so any we know about can be audited
+				// and extendable-output hashes may have their length in the header such as blake3:512
+				hdl.hashes = []string{}
+				for i, field := range header {
+					name, bits, err := parseHashLength(field)
+					if err != nil {
+						continue
+					}
+					if HashNames.Digest(name) == name {
+						header[i] = name
+						hdl.hashes = append(hdl.hashes, name)
+						if bits != 0 {
+							hdl.lengths[name] = bits
+						}
+					}
+				}
 			}
 			continue
 		}
@@ -132,7 +364,7 @@ func (hdl *Auditor) parseHashdeepFile(input string) (map[string]AuditRecord, err
 			}
 
 			// Map record to AuditRecord based on header
-			var fh AuditRecord
+			fh := AuditRecord{Hashes: map[string]string{}}
 			for i, field := range header {
 				if i >= len(record) {
 					break
@@ -140,15 +372,33 @@ func (hdl *Auditor) parseHashdeepFile(input string) (map[string]AuditRecord, err
 				switch field {
 				case "size":
 					fh.Size = record[i]
-				case "md5":
-					fh.MD5 = record[i]
-				case "sha256":
-					fh.SHA256 = record[i]
 				case "filename":
 					fh.Filename = record[i]
+				default:
+					if HashNames.Digest(field) == field {
+						fh.Hashes[field] = record[i]
+						if !textHashes[field] && record[i] != "" {
+							fh.Hashes[field] = hdl.normalizeDigest(field, record[i])
+						}
+					}
 				}
 			}
 
+			// piecewise blocks are collected under the file they belong to, only when
+			// asked for as a file may be named like a block
+			if file, start, end, ok := parsePieceFilename(fh.Filename); ok && PiecewiseInput != "" {
+				fh.Offset = start
+				fh.End = end
+				hdl.pieceSize = max(hdl.pieceSize, end-start+1)
+
+				parent := auditLookup[file]
+				parent.Filename = file
+				parent.Blocks = append(parent.Blocks, fh)
+				parent.Size = strconv.FormatInt(end+1, 10)
+				auditLookup[file] = parent
+				continue
+			}
+
 			auditLookup[fh.Filename] = fh
 		}
 	}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import "testing"
+
+func TestNewAuditorSupportedHashes(t *testing.T) {
+	input := `%%%% HASHDEEP-1.0
+%%%% size,crc32c,tiger,filename
+## Invoked from: /tmp
+##
+6,353DD8BE,ignored,at/a.txt
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	if len(hdl.Hashes()) != 1 || hdl.Hashes()[0] != "crc32c" {
+		t.Errorf("expected only crc32c got %v", hdl.Hashes())
+	}
+
+	if r := hdl.Find(Result{File: "at/a.txt", CRC32C: "353dd8be"}); r != FileMatched {
+		t.Errorf("expected match got %d", r)
+	}
+
+	if r := hdl.Find(Result{File: "at/b.txt", CRC32C: "353dd8be"}); r != FileNew {
+		t.Errorf("expected new got %d", r)
+	}
+}
+
+func TestNewAuditorNoSupportedHashes(t *testing.T) {
+	input := `%%%% HASHDEEP-1.0
+%%%% size,tiger,filename
+6,abc,at/a.txt
+`
+	_, err := NewAuditor(input)
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNewAuditorPiecewise(t *testing.T) {
+	PiecewiseInput = "4"
+	t.Cleanup(func() { PiecewiseInput = "" })
+	input := `%%%% HASHDEEP-1.0
+%%%% size,md5,filename
+4,aaaa,at/a.bin offset 0-3
+4,bbbb,at/a.bin offset 4-7
+2,cccc,at/a.bin offset 8-9
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	if hdl.Count() != 1 {
+		t.Errorf("expected 1 file got %d", hdl.Count())
+	}
+	if hdl.PieceSize() != 4 {
+		t.Errorf("expected piece size 4 got %d", hdl.PieceSize())
+	}
+
+	res := Result{File: "at/a.bin", Blocks: []Block{
+		{Offset: 0, Result: Result{MD5: "aaaa", Bytes: 4}},
+		{Offset: 4, Result: Result{MD5: "dddd", Bytes: 4}},
+		{Offset: 8, Result: Result{MD5: "cccc", Bytes: 1}},
+	}}
+
+	if r := hdl.Find(res); r != FileModified {
+		t.Errorf("expected modified got %d", r)
+	}
+
+	ranges := hdl.ChangedRanges(res)
+	if len(ranges) != 1 || ranges[0] != [2]int64{4, 9} {
+		t.Errorf("expected 4-9 changed got %v", ranges)
+	}
+}
+
+func TestNewAuditorPieceNameWithoutPiecewise(t *testing.T) {
+	input := `%%%% HASHDEEP-1.0
+%%%% size,md5,filename
+6,aaaa,x offset 0-5
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdl.PieceSize() != 0 {
+		t.Errorf("expected no piece size got %d", hdl.PieceSize())
+	}
+	if r := hdl.Find(Result{File: "x offset 0-5", MD5: "aaaa", Bytes: 6}); r != FileMatched {
+		t.Errorf("expected a file named like a block to match got %d", r)
+	}
+}
+
+func TestNewAuditorEncodings(t *testing.T) {
+	input := `%%%% HASHDEEP-1.0
+%%%% size,md5,filename
+6,b1946ac92492d2347c6235b4d2611184,hex.txt
+6,sZRqySSS0jR8YjW00mERhA==,base64.txt
+6,WGKGVSJESLJDI7DCGW2NEYIRQQ,base32.txt
+6,4425hx5d1mc9y39llj4k4nm55i,nix32.txt
+`
+	hdl, err := NewAuditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	for _, f := range []string{"hex.txt", "base64.txt", "base32.txt", "nix32.txt"} {
+		if r := hdl.Find(Result{File: f, MD5: "b1946ac92492d2347c6235b4d2611184"}); r != FileMatched {
+			t.Errorf("expected match for %s got %d", f, r)
+		}
+	}
+}
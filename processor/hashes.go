@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"strconv"
+	"strings"
+
+	"github.com/dchest/siphash"
+	"github.com/glaslos/ssdeep"
+	"github.com/minio/highwayhash"
+	"github.com/zeebo/blake3"
+	"github.com/zeebo/xxh3"
+	"golang.org/x/crypto/sha3"
+)
+
+// Tables are built once as building them is expensive compared to hashing small files
+var (
+	crc32cTable    = crc32.MakeTable(crc32.Castagnoli)
+	crc64ECMATable = crc64.MakeTable(crc64.ECMA)
+	crc64ISOTable  = crc64.MakeTable(crc64.ISO)
+)
+
+func init() {
+	// ssdeep refuses to hash small files by default however the reference
+	// implementation will, so we want to match it
+	ssdeep.Force = true
+}
+
+// SipHashKey is the 16 byte key used for SipHash-2-4, which defaults to all zeros
+var SipHashKey = make([]byte, 16)
+
+// HighwayHashKey is the 32 byte key used for HighwayHash-256, which defaults to all zeros
+var HighwayHashKey = make([]byte, 32)
+
+func newCRC32C() hash.Hash {
+	return crc32.New(crc32cTable)
+}
+
+func newCRC64ECMA() hash.Hash {
+	return crc64.New(crc64ECMATable)
+}
+
+func newCRC64ISO() hash.Hash {
+	return crc64.New(crc64ISOTable)
+}
+
+func newSipHash() hash.Hash {
+	return siphash.New(SipHashKey)
+}
+
+// The key length is checked by parseHashKey before any processing starts
+// so the error here can never happen
+func newHighwayHash() hash.Hash {
+	h, _ := highwayhash.New(HighwayHashKey)
+	return h
+}
+
+// xxh128 wraps the xxh3 hasher so that Sum returns the 128 bit digest
+// rather than the 64 bit one
+type xxh128 struct {
+	*xxh3.Hasher
+}
+
+func newXXH128() hash.Hash {
+	return xxh128{xxh3.New()}
+}
+
+func (x xxh128) Size() int { return 16 }
+
+func (x xxh128) Sum(b []byte) []byte {
+	sum := x.Sum128().Bytes()
+	return append(b, sum[:]...)
+}
+
+// textHashes are those with digests written as something other than lowercase hex,
+// such as base32 or base58, and so must be compared exactly as they are
+var textHashes = map[string]bool{
+	"ssdeep": true,
+	"tlsh":   true,
+	"aich":   true,
+	"tth":    true,
+	"cidv0":  true,
+	"cidv1":  true,
+}
+
+// HashLengths holds the digest length in bits for hashes which can produce
+// output of any length, set using the name:bits syntax such as blake3:512
+var HashLengths = map[string]int{}
+
+// CShakeCustom is the customization string used for cshake128 and cshake256
+var CShakeCustom = ""
+
+// defaultHashLengths are the digest lengths in bits used for the extendable-output
+// functions when no length is supplied. The SHAKE defaults give the full security
+// strength of each function.
+var defaultHashLengths = map[string]int{
+	"blake3":    256,
+	"shake128":  256,
+	"shake256":  512,
+	"cshake128": 256,
+	"cshake256": 512,
+}
+
+// hashLength returns the number of bytes the named hash should output
+func hashLength(name string) int {
+	if l, ok := HashLengths[name]; ok {
+		return l / 8
+	}
+	return defaultHashLengths[name] / 8
+}
+
+// parseHashLength splits a hash supplied as name:bits returning the name and
+// the length in bits, or 0 if no length was supplied
+func parseHashLength(input string) (string, int, error) {
+	name, length, found := strings.Cut(input, ":")
+	if !found {
+		return input, 0, nil
+	}
+
+	if _, ok := defaultHashLengths[name]; !ok {
+		return "", 0, fmt.Errorf("%s does not support a digest length", name)
+	}
+
+	bits, err := strconv.Atoi(length)
+	if err != nil || bits <= 0 || bits%8 != 0 {
+		return "", 0, fmt.Errorf("%s digest length must be a positive multiple of 8 bits", name)
+	}
+
+	return name, bits, nil
+}
+
+func newBlake3() hash.Hash {
+	if hashLength("blake3") == 32 {
+		return blake3.New()
+	}
+	return blake3XOF{Hasher: blake3.New(), size: hashLength("blake3")}
+}
+
+func newShake128() hash.Hash {
+	return shakeXOF{ShakeHash: sha3.NewShake128(), size: hashLength("shake128")}
+}
+
+func newShake256() hash.Hash {
+	return shakeXOF{ShakeHash: sha3.NewShake256(), size: hashLength("shake256")}
+}
+
+func newCShake128() hash.Hash {
+	return shakeXOF{ShakeHash: sha3.NewCShake128(nil, []byte(CShakeCustom)), size: hashLength("cshake128")}
+}
+
+func newCShake256() hash.Hash {
+	return shakeXOF{ShakeHash: sha3.NewCShake256(nil, []byte(CShakeCustom)), size: hashLength("cshake256")}
+}
+
+// blake3XOF reads size bytes from the blake3 output stream when summed
+type blake3XOF struct {
+	*blake3.Hasher
+	size int
+}
+
+func (x blake3XOF) Size() int { return x.size }
+
+func (x blake3XOF) Sum(b []byte) []byte {
+	out := make([]byte, x.size)
+	_, _ = x.Digest().Read(out)
+	return append(b, out...)
+}
+
+// shakeXOF adapts a ShakeHash to hash.Hash reading size bytes when summed
+type shakeXOF struct {
+	sha3.ShakeHash
+	size int
+}
+
+func (x shakeXOF) Size() int { return x.size }
+
+func (x shakeXOF) Sum(b []byte) []byte {
+	out := make([]byte, x.size)
+	// reading moves the state on so read from a copy
+	_, _ = x.Clone().Read(out)
+	return append(b, out...)
+}
+
+// parseHashKey converts the hex representation of a key supplied on the command line
+// into bytes checking that it is the length the hash requires
+func parseHashKey(input string, size int) ([]byte, error) {
+	if input == "" {
+		return make([]byte, size), nil
+	}
+
+	key, err := hex.DecodeString(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != size {
+		return nil, fmt.Errorf("key must be %d bytes but was %d", size, len(key))
+	}
+
+	return key, nil
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inTotoDigests maps our hash names to the names used in an in-toto DigestSet
+var inTotoDigests = []struct {
+	hash   string
+	intoto string
+}{
+	{"md5", "md5"},
+	{"sha1", "sha1"},
+	{"sha256", "sha256"},
+	{"sha384", "sha384"},
+	{"sha512", "sha512"},
+	{"sha3224", "sha3_224"},
+	{"sha3256", "sha3_256"},
+	{"sha3384", "sha3_384"},
+	{"sha3512", "sha3_512"},
+	{"shake128", "shake128"},
+	{"shake256", "shake256"},
+	{"blake2b512", "blake2b"},
+	{"git-sha1", "gitBlob"},
+}
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	inTotoPayloadType   = "application/vnd.in-toto+json"
+)
+
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// toInToto writes an in-toto Statement with every file as a subject, which is
+// wrapped in a signed DSSE envelope when a signing key is supplied
+func toInToto(input chan Result) (string, bool) {
+	// an empty predicate is not valid provenance so must not be signed as if it were
+	if strings.HasPrefix(InTotoPredicateType, "https://slsa.dev/provenance/") && InTotoPredicate == "" {
+		printError("SLSA provenance requires the predicate to be supplied using --predicate")
+		return "", false
+	}
+
+	predicate := json.RawMessage("{}")
+	if InTotoPredicate != "" {
+		b, err := os.ReadFile(InTotoPredicate)
+		if err != nil {
+			printError(fmt.Sprintf("unable to read predicate: %s", err.Error()))
+			return "", false
+		}
+		if !json.Valid(b) {
+			printError(fmt.Sprintf("predicate is not valid JSON: %s", InTotoPredicate))
+			return "", false
+		}
+		predicate = b
+	}
+
+	statement := inTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       []inTotoSubject{},
+		PredicateType: InTotoPredicateType,
+		Predicate:     predicate,
+	}
+	for _, res := range sbomResults(input) {
+		statement.Subject = append(statement.Subject, inTotoResultSubject(res))
+	}
+
+	payload, _ := json.Marshal(statement)
+	if SigningKey == "" {
+		return string(payload) + "\n", true
+	}
+
+	envelope, err := dsseSign(payload, SigningKey)
+	if err != nil {
+		printError(fmt.Sprintf("unable to sign statement: %s", err.Error()))
+		return "", false
+	}
+	out, _ := json.Marshal(envelope)
+	return string(out) + "\n", true
+}
+
+func inTotoResultSubject(res Result) inTotoSubject {
+	subject := inTotoSubject{
+		Name:   filepath.ToSlash(res.File),
+		Digest: map[string]string{},
+	}
+	for _, d := range inTotoDigests {
+		if hasHash(d.hash) {
+			subject.Digest[d.intoto] = hex.EncodeToString(decodeEncoded(res.Digest(d.hash)))
+		}
+	}
+
+	// directories from tree mode have git tree IDs rather than blobs
+	if v, ok := subject.Digest["gitBlob"]; ok && strings.HasSuffix(res.File, string(filepath.Separator)) {
+		delete(subject.Digest, "gitBlob")
+		subject.Digest["gitTree"] = v
+	}
+
+	return subject
+}
+
+// dssePAE is the pre-authentication encoding which is what DSSE signs
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// dsseSign signs the payload using the PEM encoded private key in the file
+// which may be Ed25519, ECDSA or RSA. The key ID is the sha256 of the public key.
+func dsseSign(payload []byte, keyFile string) (dsseEnvelope, error) {
+	signer, err := loadSigningKey(keyFile)
+	if err != nil {
+		return dsseEnvelope{}, err
+	}
+
+	pae := dssePAE(inTotoPayloadType, payload)
+	var sig []byte
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		sig, err = signer.Sign(rand.Reader, pae, crypto.Hash(0))
+	default:
+		digest := sha256.Sum256(pae)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return dsseEnvelope{}, err
+	}
+
+	public, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return dsseEnvelope{}, err
+	}
+	keyID := sha256.Sum256(public)
+
+	return dsseEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []dsseSignature{{
+			KeyID: hex.EncodeToString(keyID[:]),
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		}},
+	}, nil
+}
+
+func loadSigningKey(keyFile string) (crypto.Signer, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDSSESign(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(private)
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+	envelope, err := dsseSign(payload, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, _ := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+	if !ed25519.Verify(public, dssePAE(inTotoPayloadType, payload), sig) {
+		t.Error("Expected signature to verify")
+	}
+}
+
+func TestDSSEPAE(t *testing.T) {
+	expected := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got := string(dssePAE("http://example.com/HelloWorld", []byte("hello world"))); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+func TestToInToto(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256", "sha3256", "blake2b512", "git-sha1"}
+	Encoding = "base64"
+
+	file, err := processReader("dist/app.txt", strings.NewReader("hello\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := Result{File: "dist" + string(filepath.Separator), GitSHA1: encodeDigest([]byte{0xab, 0xcd})}
+
+	input := make(chan Result, 2)
+	input <- tree
+	input <- file
+	close(input)
+	out, ok := toInToto(input)
+	if !ok {
+		t.Fatal("Expected statement")
+	}
+
+	var statement inTotoStatement
+	if err := json.Unmarshal([]byte(out), &statement); err != nil {
+		t.Fatal(err)
+	}
+	if statement.Type != inTotoStatementType || statement.PredicateType != InTotoPredicateType || string(statement.Predicate) != "{}" {
+		t.Errorf("Unexpected statement %s", out)
+	}
+	if len(statement.Subject) != 2 || statement.Subject[0].Name != "dist/" || statement.Subject[1].Name != "dist/app.txt" {
+		t.Fatalf("Expected subjects sorted by name got %v", statement.Subject)
+	}
+
+	// digests are always hex using the in-toto names whatever encoding is used
+	digest := statement.Subject[1].Digest
+	if digest["sha256"] != "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03" ||
+		digest["gitBlob"] != "ce013625030ba8dba906f756967f9e9ca394464a" ||
+		len(digest["sha3_256"]) != 64 || len(digest["blake2b"]) != 128 || len(digest) != 4 {
+		t.Errorf("Unexpected file digests %v", digest)
+	}
+	if d := statement.Subject[0].Digest; d["gitTree"] != "abcd" || len(d) != 4 {
+		t.Errorf("Expected the directory to have a gitTree got %v", d)
+	}
+}
+
+func TestToInTotoProvenanceRequiresPredicate(t *testing.T) {
+	InTotoPredicateType = "https://slsa.dev/provenance/v1"
+	t.Cleanup(func() { InTotoPredicateType = "https://github.com/boyter/hashit/digests/v1" })
+
+	input := make(chan Result)
+	close(input)
+	if _, ok := toInToto(input); ok {
+		t.Error("Expected SLSA provenance without a predicate to fail")
+	}
+}
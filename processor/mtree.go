@@ -0,0 +1,633 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// mtreeDigests maps the mtree digest keywords to our hash names, where
+// rmd160digest has no hash of ours and is calculated separately
+var mtreeDigests = []struct {
+	keyword string
+	hash    string
+}{
+	{"md5digest", "md5"},
+	{"sha1digest", "sha1"},
+	{"sha256digest", "sha256"},
+	{"sha384digest", "sha384"},
+	{"sha512digest", "sha512"},
+	{"rmd160digest", ""},
+}
+
+// mtreeAliases are the other names mtree accepts for the digest keywords
+var mtreeAliases = map[string]string{
+	"md5":             "md5digest",
+	"sha1":            "sha1digest",
+	"sha256":          "sha256digest",
+	"sha384":          "sha384digest",
+	"sha512":          "sha512digest",
+	"rmd160":          "rmd160digest",
+	"ripemd160digest": "rmd160digest",
+}
+
+var mtreeKeywords = []string{"type", "mode", "uid", "gid", "size", "time", "link"}
+
+// mtreeNode is a file or directory found when scanning a tree
+type mtreeNode struct {
+	name     string
+	path     string // path on disk
+	rel      string // slash separated path relative to the root
+	info     os.FileInfo
+	children []*mtreeNode
+}
+
+// mtreeEntry is a single file or directory from an mtree spec
+type mtreeEntry struct {
+	path     string // slash separated path relative to the root
+	keywords map[string]string
+}
+
+// MtreeCreate prints an mtree spec for the directory in the same hierarchical
+// form as mtree -c, using the keywords in MtreeKeywords
+func MtreeCreate(root string) {
+	keywords, err := mtreeKeywordList(MtreeKeywords)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+
+	tree, err := mtreeScan(filepath.Clean(root), ".")
+	if err != nil {
+		printError(fmt.Sprintf("unable to scan %s: %s", root, err.Error()))
+		os.Exit(1)
+	}
+
+	digests, err := mtreeHash(tree.files(), keywords)
+	if err != nil {
+		printError(fmt.Sprintf("unable to hash %s: %s", root, err.Error()))
+		os.Exit(1)
+	}
+
+	var str strings.Builder
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	host, _ := os.Hostname()
+	abs, _ := filepath.Abs(root)
+	str.WriteString(fmt.Sprintf("#\t   user: %s\n", username))
+	str.WriteString(fmt.Sprintf("#\tmachine: %s\n", host))
+	str.WriteString(fmt.Sprintf("#\t   tree: %s\n", abs))
+	str.WriteString(fmt.Sprintf("#\t   date: %s\n", time.Now().Format(time.ANSIC)))
+
+	mtreeWriteDir(&str, tree, 0, keywords, digests)
+	fmt.Print(str.String())
+}
+
+// mtreeWriteDir writes the directory followed by its files and then each
+// directory below it, with .. to return to the parent
+func mtreeWriteDir(str *strings.Builder, dir *mtreeNode, depth int, keywords []string, digests map[string]map[string]string) {
+	indent := strings.Repeat("    ", depth)
+	str.WriteString(fmt.Sprintf("\n# %s\n", mtreeDisplayPath(dir.rel)))
+	str.WriteString(indent + mtreeLine(dir, keywords, digests) + "\n")
+
+	for _, child := range dir.children {
+		if !child.info.IsDir() {
+			str.WriteString(indent + "    " + mtreeLine(child, keywords, digests) + "\n")
+		}
+	}
+	for _, child := range dir.children {
+		if child.info.IsDir() {
+			mtreeWriteDir(str, child, depth+1, keywords, digests)
+		}
+	}
+
+	if depth != 0 {
+		str.WriteString(fmt.Sprintf("# %s\n", mtreeDisplayPath(dir.rel)))
+		str.WriteString(indent + "..\n\n")
+	}
+}
+
+func mtreeLine(node *mtreeNode, keywords []string, digests map[string]map[string]string) string {
+	attrs := mtreeAttributes(node, keywords, digests[node.path])
+	line := []string{mtreeEscape(node.name)}
+	for _, k := range keywords {
+		if v, ok := attrs[k]; ok {
+			line = append(line, k+"="+v)
+		}
+	}
+	return strings.Join(line, " ")
+}
+
+func mtreeDisplayPath(rel string) string {
+	if rel == "." {
+		return "."
+	}
+	return "./" + mtreeEscape(rel)
+}
+
+// MtreeVerify compares the directory against an mtree spec reporting every
+// entry which is missing, extra or changed
+func MtreeVerify(spec string, root string) {
+	var r io.Reader = os.Stdin
+	if spec != "-" {
+		f, err := os.Open(spec)
+		if err != nil {
+			printError(fmt.Sprintf("unable to read spec %s: %s", spec, err.Error()))
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	entries, err := parseMtree(r)
+	if err != nil {
+		printError(fmt.Sprintf("unable to parse spec %s: %s", spec, err.Error()))
+		os.Exit(1)
+	}
+
+	result, valid := mtreeVerify(entries, root)
+	fmt.Print(result)
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+func mtreeVerify(entries []mtreeEntry, root string) (string, bool) {
+	tree, err := mtreeScan(filepath.Clean(root), ".")
+	if err != nil {
+		return fmt.Sprintf("unable to scan %s: %s\n", root, err.Error()), false
+	}
+
+	nodes := map[string]*mtreeNode{}
+	tree.walk(func(n *mtreeNode) { nodes[n.rel] = n })
+
+	// only hash the files and digests the spec asks for
+	var keywords []string
+	var files []string
+	for _, e := range entries {
+		for k := range e.keywords {
+			if !contains(keywords, k) {
+				keywords = append(keywords, k)
+			}
+		}
+		if n, ok := nodes[e.path]; ok && n.info.Mode().IsRegular() {
+			files = append(files, n.path)
+		}
+	}
+	digests, err := mtreeHash(files, keywords)
+	if err != nil {
+		return fmt.Sprintf("unable to hash %s: %s\n", root, err.Error()), false
+	}
+
+	var str strings.Builder
+	valid := true
+	inSpec := map[string]bool{}
+	var ignored []string
+	for _, e := range entries {
+		inSpec[e.path] = true
+		if _, ok := e.keywords["ignore"]; ok {
+			ignored = append(ignored, e.path+"/")
+		}
+
+		n, ok := nodes[e.path]
+		if !ok {
+			if _, optional := e.keywords["optional"]; !optional {
+				str.WriteString(fmt.Sprintf("%s missing\n", mtreeDisplayPath(e.path)))
+				valid = false
+			}
+			continue
+		}
+
+		var changes []string
+		found := mtreeAttributes(n, sortedKeys(e.keywords), digests[n.path])
+		for _, k := range sortedKeys(e.keywords) {
+			f, ok := found[k]
+			if ok && !mtreeEqual(k, e.keywords[k], f) {
+				changes = append(changes, fmt.Sprintf("\t%s expected %s found %s\n", k, e.keywords[k], f))
+			}
+		}
+		if len(changes) != 0 {
+			str.WriteString(fmt.Sprintf("%s changed\n", mtreeDisplayPath(e.path)))
+			str.WriteString(strings.Join(changes, ""))
+			valid = false
+		}
+	}
+
+	tree.walk(func(n *mtreeNode) {
+		if inSpec[n.rel] {
+			return
+		}
+		for _, i := range ignored {
+			if strings.HasPrefix(n.rel, i) {
+				return
+			}
+		}
+		str.WriteString(fmt.Sprintf("extra: %s\n", mtreeDisplayPath(n.rel)))
+		valid = false
+	})
+
+	return str.String(), valid
+}
+
+// mtreeEqual compares the values allowing for modes written without a
+// leading zero and times written to the second
+func mtreeEqual(keyword string, expected string, found string) bool {
+	switch keyword {
+	case "mode":
+		e, err1 := strconv.ParseUint(expected, 8, 32)
+		f, err2 := strconv.ParseUint(found, 8, 32)
+		return err1 == nil && err2 == nil && e == f
+	case "time":
+		es, ens, _ := strings.Cut(expected, ".")
+		fs, fns, _ := strings.Cut(found, ".")
+		if es != fs {
+			return false
+		}
+		e, _ := strconv.ParseInt(ens, 10, 64)
+		f, _ := strconv.ParseInt(fns, 10, 64)
+		return e == 0 || e == f
+	case "link":
+		return mtreeUnescape(expected) == mtreeUnescape(found)
+	}
+	if strings.HasSuffix(keyword, "digest") {
+		return strings.EqualFold(expected, found)
+	}
+	return expected == found
+}
+
+// mtreeKeywordList checks the keywords are ones we can write, converting
+// any digest aliases to their full names
+func mtreeKeywordList(keywords []string) ([]string, error) {
+	var list []string
+	for _, k := range keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if alias, ok := mtreeAliases[k]; ok {
+			k = alias
+		}
+		if !contains(mtreeKeywords, k) && mtreeDigestHash(k) == "-" {
+			return nil, fmt.Errorf("unsupported mtree keyword %s", k)
+		}
+		list = append(list, k)
+	}
+	return list, nil
+}
+
+// mtreeDigestHash returns our hash name for the digest keyword or - if it is not one
+func mtreeDigestHash(keyword string) string {
+	for _, d := range mtreeDigests {
+		if d.keyword == keyword {
+			return d.hash
+		}
+	}
+	return "-"
+}
+
+// mtreeScan reads the tree below path including everything the
+// ignore rules would skip, sorting entries by name as mtree does
+func mtreeScan(p string, rel string) (*mtreeNode, error) {
+	fi, err := os.Lstat(p)
+	if err != nil {
+		return nil, err
+	}
+	node := &mtreeNode{name: path.Base(rel), path: p, rel: rel, info: fi}
+
+	if !fi.IsDir() {
+		return node, nil
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	for _, e := range entries {
+		childRel := e.Name()
+		if rel != "." {
+			childRel = rel + "/" + e.Name()
+		}
+		child, err := mtreeScan(filepath.Join(p, e.Name()), childRel)
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, child)
+	}
+	return node, nil
+}
+
+func (n *mtreeNode) walk(f func(*mtreeNode)) {
+	f(n)
+	for _, c := range n.children {
+		c.walk(f)
+	}
+}
+
+// files returns the path of every regular file in the tree
+func (n *mtreeNode) files() []string {
+	var files []string
+	n.walk(func(c *mtreeNode) {
+		if c.info.Mode().IsRegular() {
+			files = append(files, c.path)
+		}
+	})
+	return files
+}
+
+// mtreeHash calculates the digest keywords for each file returning them by path
+func mtreeHash(files []string, keywords []string) (map[string]map[string]string, error) {
+	digests := map[string]map[string]string{}
+	for _, f := range files {
+		digests[f] = map[string]string{}
+	}
+
+	Hash = []string{}
+	for _, k := range keywords {
+		if h := mtreeDigestHash(k); h != "-" && h != "" {
+			Hash = append(Hash, h)
+		}
+	}
+	Encoding = "hex"
+
+	if len(Hash) != 0 && len(files) != 0 {
+		queue := make(chan string, len(files))
+		for _, f := range files {
+			queue <- f
+		}
+		close(queue)
+
+		results, err := hashFiles(queue)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range results {
+			for _, d := range mtreeDigests {
+				if d.hash != "" && hasHash(d.hash) {
+					digests[res.File][d.keyword] = res.Digest(d.hash)
+				}
+			}
+		}
+	}
+
+	if contains(keywords, "rmd160digest") {
+		for _, f := range files {
+			sum, err := rmd160File(f)
+			if err != nil {
+				return nil, err
+			}
+			digests[f]["rmd160digest"] = sum
+		}
+	}
+
+	return digests, nil
+}
+
+func rmd160File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := ripemd160.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// mtreeAttributes returns the value of each keyword which applies to the node
+func mtreeAttributes(node *mtreeNode, keywords []string, digests map[string]string) map[string]string {
+	fi := node.info
+	attrs := map[string]string{}
+	for _, k := range keywords {
+		if alias, ok := mtreeAliases[k]; ok {
+			k = alias
+		}
+
+		switch k {
+		case "type":
+			attrs[k] = mtreeType(fi.Mode())
+		case "mode":
+			attrs[k] = fmt.Sprintf("%#o", mtreeMode(fi.Mode()))
+		case "uid", "gid":
+			if uid, gid, ok := fileOwner(fi); ok {
+				attrs["uid"] = strconv.Itoa(uid)
+				attrs["gid"] = strconv.Itoa(gid)
+			}
+		case "size":
+			if fi.Mode().IsRegular() {
+				attrs[k] = strconv.FormatInt(fi.Size(), 10)
+			}
+		case "time":
+			attrs[k] = fmt.Sprintf("%d.%09d", fi.ModTime().Unix(), fi.ModTime().Nanosecond())
+		case "link":
+			if fi.Mode()&os.ModeSymlink != 0 {
+				if target, err := os.Readlink(node.path); err == nil {
+					attrs[k] = mtreeEscape(target)
+				}
+			}
+		default:
+			if v, ok := digests[k]; ok {
+				attrs[k] = v
+			}
+		}
+	}
+
+	return attrs
+}
+
+func mtreeType(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "link"
+	case mode&os.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeCharDevice != 0:
+		return "char"
+	case mode&os.ModeDevice != 0:
+		return "block"
+	}
+	return "file"
+}
+
+// mtreeMode converts to the unix permission bits including setuid, setgid and sticky
+func mtreeMode(mode os.FileMode) uint32 {
+	m := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		m |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		m |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		m |= 0o1000
+	}
+	return m
+}
+
+// parseMtree reads an mtree spec in either the hierarchical form written by
+// mtree -c or the full path form written by libarchive, applying any /set
+// defaults to the entries
+func parseMtree(r io.Reader) ([]mtreeEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var entries []mtreeEntry
+	set := map[string]string{}
+	cwd := ""
+	line := ""
+	for scanner.Scan() {
+		line += scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			line = strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+
+		fields := strings.Fields(line)
+		line = ""
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "/set":
+			for k, v := range mtreeParseKeywords(fields[1:]) {
+				set[k] = v
+			}
+			continue
+		case "/unset":
+			for _, k := range fields[1:] {
+				if k == "all" {
+					set = map[string]string{}
+				}
+				delete(set, k)
+			}
+			continue
+		case "..":
+			if cwd == "" {
+				return nil, errors.New(".. above the root of the spec")
+			}
+			cwd = path.Dir(cwd)
+			if cwd == "." {
+				cwd = ""
+			}
+			continue
+		}
+
+		keywords := map[string]string{}
+		for k, v := range set {
+			keywords[k] = v
+		}
+		for k, v := range mtreeParseKeywords(fields[1:]) {
+			keywords[k] = v
+		}
+
+		name := mtreeUnescape(fields[0])
+		var p string
+		if strings.Contains(name, "/") {
+			// full paths do not change the current directory
+			p = path.Clean(name)
+		} else {
+			p = path.Clean(path.Join(cwd, name))
+			if keywords["type"] == "dir" && p != "." {
+				cwd = p
+			}
+		}
+
+		entries = append(entries, mtreeEntry{path: p, keywords: keywords})
+	}
+
+	return entries, scanner.Err()
+}
+
+// mtreeParseKeywords splits keyword=value pairs, where keywords such as
+// optional have no value, using the full names for any digest aliases
+func mtreeParseKeywords(fields []string) map[string]string {
+	keywords := map[string]string{}
+	for _, f := range fields {
+		k, v, _ := strings.Cut(f, "=")
+		if alias, ok := mtreeAliases[k]; ok {
+			k = alias
+		}
+		keywords[k] = v
+	}
+	return keywords
+}
+
+// mtreeEscape encodes names the same way as strsvis with VIS_WHITE, VIS_OCTAL
+// and VIS_GLOB which is what mtree uses
+func mtreeEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`\#*?[`, c) != -1 {
+			sb.WriteString(fmt.Sprintf("\\%03o", c))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// mtreeUnescape reverses mtreeEscape as well as the backslash escapes unvis accepts
+func mtreeUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		if i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+
+		i++
+		switch s[i] {
+		case 's':
+			sb.WriteByte(' ')
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
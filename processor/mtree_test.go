@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMtree(t *testing.T) {
+	spec := `#	   user: root
+
+/set type=file uid=0 gid=0 mode=0644
+. type=dir mode=0755
+    a.txt size=6 \
+        sha256=5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03
+sub\040dir type=dir mode=0755
+    b.txt size=6
+# ./sub\040dir
+..
+./c/d.txt size=1
+e.txt optional
+`
+	entries, err := parseMtree(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	expected := []string{".", "a.txt", "sub dir", "sub dir/b.txt", "c/d.txt", "e.txt"}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries got %d", len(expected), len(entries))
+	}
+	for i, e := range entries {
+		if e.path != expected[i] {
+			t.Errorf("expected %s got %s", expected[i], e.path)
+		}
+	}
+
+	if entries[1].keywords["type"] != "file" || entries[1].keywords["mode"] != "0644" {
+		t.Errorf("expected /set defaults got %v", entries[1].keywords)
+	}
+	if entries[1].keywords["sha256digest"] == "" {
+		t.Errorf("expected sha256digest from the continued line got %v", entries[1].keywords)
+	}
+	if _, ok := entries[5].keywords["optional"]; !ok {
+		t.Errorf("expected optional got %v", entries[5].keywords)
+	}
+}
+
+func TestMtreeEscape(t *testing.T) {
+	for _, name := range []string{"a.txt", "sub dir", "b*.txt", "tab\there", `back\slash`, "#hash", "é"} {
+		escaped := mtreeEscape(name)
+		if strings.ContainsAny(escaped, " \t*#") {
+			t.Errorf("expected %q to be escaped got %q", name, escaped)
+		}
+		if got := mtreeUnescape(escaped); got != name {
+			t.Errorf("expected %q got %q", name, got)
+		}
+	}
+}
+
+func TestMtreeEqual(t *testing.T) {
+	if !mtreeEqual("mode", "644", "0644") {
+		t.Error("expected modes to match")
+	}
+	if !mtreeEqual("time", "1700000000.0", "1700000000.123456789") {
+		t.Error("expected time to the second to match")
+	}
+	if mtreeEqual("time", "1700000000.5", "1700000000.123456789") {
+		t.Error("expected different nanoseconds not to match")
+	}
+}
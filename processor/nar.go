@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// NarHash prints the sha256 of the Nix archive (NAR) serialisation of each
+// path, which is the hash Nix records for store paths and fixed output
+// derivations. It is printed as SRI unless NarBase32 is set.
+func NarHash(paths []string) {
+	for _, path := range paths {
+		h := sha256.New()
+		if err := writeNar(h, filepath.Clean(path)); err != nil {
+			printError(fmt.Sprintf("unable to hash %s: %s", path, err.Error()))
+			os.Exit(1)
+		}
+
+		sum := h.Sum(nil)
+		if NarBase32 {
+			fmt.Printf("sha256:%s  %s\n", nix32Encode(sum), path)
+		} else {
+			fmt.Printf("sha256-%s  %s\n", base64.StdEncoding.EncodeToString(sum), path)
+		}
+	}
+}
+
+// writeNar writes the NAR serialisation of the path. Unlike hashing this does
+// not use the ignore rules as a NAR always holds everything below the path,
+// including empty directories.
+func writeNar(w io.Writer, path string) error {
+	narString(w, "nix-archive-1")
+	return writeNarNode(w, path)
+}
+
+func writeNarNode(w io.Writer, path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	narString(w, "(")
+	narString(w, "type")
+
+	switch {
+	case fi.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		narString(w, "symlink")
+		narString(w, "target")
+		narString(w, target)
+	case fi.IsDir():
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		// NAR entries are sorted by the bytes of the name
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Name() < entries[j].Name()
+		})
+
+		narString(w, "directory")
+		for _, e := range entries {
+			narString(w, "entry")
+			narString(w, "(")
+			narString(w, "name")
+			narString(w, e.Name())
+			narString(w, "node")
+			if err := writeNarNode(w, filepath.Join(path, e.Name())); err != nil {
+				return err
+			}
+			narString(w, ")")
+		}
+	case fi.Mode().IsRegular():
+		narString(w, "regular")
+		if fi.Mode()&0111 != 0 {
+			narString(w, "executable")
+			narString(w, "")
+		}
+		narString(w, "contents")
+		if err := narContents(w, path, fi.Size()); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s is not a regular file, directory or symlink", path)
+	}
+
+	narString(w, ")")
+	return nil
+}
+
+// narString writes the length, content and then padding to a multiple of 8 bytes
+func narString(w io.Writer, s string) {
+	_ = binary.Write(w, binary.LittleEndian, uint64(len(s)))
+	_, _ = io.WriteString(w, s)
+	narPadding(w, int64(len(s)))
+}
+
+func narPadding(w io.Writer, size int64) {
+	if pad := (8 - size%8) % 8; pad != 0 {
+		_, _ = w.Write(make([]byte, pad))
+	}
+}
+
+// narContents streams the file in the same form as narString
+func narContents(w io.Writer, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_ = binary.Write(w, binary.LittleEndian, uint64(size))
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("%s changed size while being read", path)
+	}
+	narPadding(w, size)
+	return nil
+}
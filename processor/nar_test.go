@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNix32Encode(t *testing.T) {
+	sum := sha256.Sum256(nil)
+	expected := "0mdqa9w1p6cmli6976v4wi0sw9r4p5prkj7lzfd1877wk11c9c73"
+	if got := nix32Encode(sum[:]); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+func TestWriteNarFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hello")
+	_ = os.WriteFile(file, []byte("hello"), 0644)
+
+	var expected bytes.Buffer
+	for _, s := range []string{"nix-archive-1", "(", "type", "regular", "contents", "hello", ")"} {
+		narString(&expected, s)
+	}
+
+	var got bytes.Buffer
+	if err := writeNar(&got, file); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), expected.Bytes()) {
+		t.Errorf("Expected %x got %x", expected.Bytes(), got.Bytes())
+	}
+}
+
+func TestGoModulePrefix(t *testing.T) {
+	got := goModulePrefix("/home/user/go/pkg/mod/github.com/!burnt!sushi/toml@v1.2.0")
+	if got != "github.com/BurntSushi/toml@v1.2.0" {
+		t.Errorf("Expected github.com/BurntSushi/toml@v1.2.0 got %s", got)
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+)
+
+func TestTigerSum(t *testing.T) {
+	expected := "3293ac630c13f0245f92bbb1766e16167a4e58492dde73f3"
+	if got := hex.EncodeToString(tigerSum(nil)); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+func TestTextHashes(t *testing.T) {
+	var tests = []struct {
+		name     string
+		hasher   hash.Hash
+		input    string
+		expected string
+	}{
+		{"tth empty", newTTH(), "", "LWPNACQDBZRYXW3VHJVCJ64QBZNGHOHHHZWCLNQ"},
+		{"tth 1024", newTTH(), strings.Repeat("A", 1024), "L66Q4YVNAFWVS23X2HJIRA5ZJ7WXR3F26RSASFA"},
+		{"aich empty", newAICH(), "", "3I42H3S6NNFQ2MSVX7XZKYAYSCX5QBYJ"},
+		{"cidv0 empty", newCIDv0(), "", "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"},
+		{"cidv0", newCIDv0(), "hello world\n", "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"},
+		{"cidv1 empty", newCIDv1(), "", "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _ = tc.hasher.Write([]byte(tc.input))
+			if got := string(tc.hasher.Sum(nil)); got != tc.expected {
+				t.Errorf("Expected %s got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResultURIs(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"ed2k", "tth"}
+	res := Result{
+		File:  "dir/a b.txt",
+		Bytes: 6,
+		Ed2k:  "63481c78ae04c201fa01ea9d2b1db56d",
+		TTH:   "DCTZG7V3JMT2JDJ6VOZU4YISNZVETHE76V2GONI",
+	}
+
+	expected := "ed2k://|file|a%20b.txt|6|63481c78ae04c201fa01ea9d2b1db56d|/\n" +
+		"magnet:?xt=urn:tree:tiger:DCTZG7V3JMT2JDJ6VOZU4YISNZVETHE76V2GONI&xl=6&dn=a+b.txt\n"
+	if got := resultURIs(res); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
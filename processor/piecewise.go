@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Block holds the hashes for a single piece of a file when using piecewise hashing
+// where Offset is the position of the first byte of the piece in the file
+type Block struct {
+	Offset int64
+	Result
+}
+
+// End is the position of the last byte of the block in the file
+func (b Block) End() int64 {
+	if b.Bytes == 0 {
+		return b.Offset
+	}
+	return b.Offset + b.Bytes - 1
+}
+
+// piecewiseHasher collects written bytes into blocks of the piecewise size
+// hashing each block using the same digests as the file itself
+type piecewiseHasher struct {
+	filename string
+	size     int
+	offset   int64
+	buf      []byte
+	blocks   []Block
+	err      error
+}
+
+// blockHash returns true for the hashes calculated for each block, which are the
+// enabled digests leaving out fuzzy hashes, trees and content identifiers
+func blockHash(name string) bool {
+	return readHash(name) && !textHashes[name]
+}
+
+func newPiecewiseHasher(filename string, size int64) *piecewiseHasher {
+	return &piecewiseHasher{
+		filename: filename,
+		size:     int(size),
+	}
+}
+
+func (p *piecewiseHasher) Write(b []byte) (int, error) {
+	written := len(b)
+	for len(b) > 0 {
+		take := min(p.size-len(p.buf), len(b))
+		p.buf = append(p.buf, b[:take]...)
+		b = b[take:]
+
+		if len(p.buf) == p.size {
+			p.flush()
+		}
+	}
+	return written, p.err
+}
+
+func (p *piecewiseHasher) flush() {
+	if len(p.buf) == 0 || p.err != nil {
+		return
+	}
+
+	r, err := processReadFileHashes(p.filename, &p.buf, blockHash)
+	if err != nil {
+		p.err = fmt.Errorf("hashing block at offset %d: %s", p.offset, err.Error())
+		return
+	}
+	r.File = p.filename
+	r.Bytes = int64(len(p.buf))
+	p.blocks = append(p.blocks, Block{Offset: p.offset, Result: r})
+
+	p.offset += int64(len(p.buf))
+	p.buf = p.buf[:0]
+}
+
+// Blocks hashes anything remaining as the final block and returns them all
+func (p *piecewiseHasher) Blocks() ([]Block, error) {
+	p.flush()
+	return p.blocks, p.err
+}
+
+// piecewiseBlocks splits content already in memory into blocks and hashes them
+func piecewiseBlocks(filename string, content []byte) ([]Block, error) {
+	p := newPiecewiseHasher(filename, Piecewise)
+	for start := 0; start < len(content); start += p.size {
+		end := min(start+p.size, len(content))
+		piece := content[start:end]
+		r, err := processReadFileHashes(filename, &piece, blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("hashing block at offset %d: %s", start, err.Error())
+		}
+		r.File = filename
+		r.Bytes = int64(len(piece))
+		p.blocks = append(p.blocks, Block{Offset: int64(start), Result: r})
+	}
+	return p.blocks, nil
+}
+
+// parseSize converts sizes such as 4096, 64k or 1m into bytes using
+// powers of 1024 the same way hashdeep does for its piecewise option
+func parseSize(input string) (int64, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	multiplier := int64(1)
+	for i, suffix := range []string{"k", "m", "g", "t"} {
+		if strings.HasSuffix(input, suffix) {
+			input = strings.TrimSuffix(input, suffix)
+			multiplier = 1 << (10 * (i + 1))
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", input)
+	}
+	if size <= 0 {
+		return 0, errors.New("size must be greater than 0")
+	}
+
+	return size * multiplier, nil
+}
+
+// hashdeep writes pieces as the filename followed by the range of bytes it covers
+var pieceRegex = regexp.MustCompile(`^(.*) offset (\d+)-(\d+)$`)
+
+func pieceFilename(file string, b Block) string {
+	return fmt.Sprintf("%s offset %d-%d", file, b.Offset, b.End())
+}
+
+// parsePieceFilename splits a hashdeep piecewise filename into the file and the
+// first and last byte of the block
+func parsePieceFilename(input string) (string, int64, int64, bool) {
+	m := pieceRegex.FindStringSubmatch(input)
+	if m == nil {
+		return input, 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return input, 0, 0, false
+	}
+	end, err := strconv.ParseInt(m[3], 10, 64)
+	if err != nil || end < start {
+		return input, 0, 0, false
+	}
+
+	return m[1], start, end, true
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	var cases = []struct {
+		input string
+		want  int64
+	}{
+		{"4096", 4096},
+		{"64k", 65536},
+		{"1M", 1048576},
+		{"2g", 2147483648},
+	}
+
+	for _, c := range cases {
+		got, err := parseSize(c.input)
+		if err != nil || got != c.want {
+			t.Errorf("%s expected %d got %d %v", c.input, c.want, got, err)
+		}
+	}
+
+	for _, input := range []string{"", "0", "-1k", "1x"} {
+		if _, err := parseSize(input); err == nil {
+			t.Errorf("%s expected error", input)
+		}
+	}
+}
+
+func TestPiecewiseHasherMatchesBlocks(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5"}
+	Piecewise = 10
+	t.Cleanup(func() { Piecewise = 0 })
+
+	content := bytes.Repeat([]byte("0123456789abc"), 5)
+
+	// write in uneven chunks to check they are split on the block size
+	p := newPiecewiseHasher("test", Piecewise)
+	for i := 0; i < len(content); i += 7 {
+		_, _ = p.Write(content[i:min(i+7, len(content))])
+	}
+
+	streamed, err := p.Blocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := piecewiseBlocks("test", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(blocks) != 7 || len(streamed) != len(blocks) {
+		t.Fatalf("expected 7 blocks got %d and %d", len(blocks), len(streamed))
+	}
+
+	for i := range blocks {
+		if blocks[i].Offset != streamed[i].Offset || blocks[i].MD5 != streamed[i].MD5 || blocks[i].Bytes != streamed[i].Bytes {
+			t.Errorf("block %d differs %v %v", i, blocks[i], streamed[i])
+		}
+	}
+
+	if last := blocks[6]; last.Offset != 60 || last.End() != 64 {
+		t.Errorf("expected last block 60-64 got %d-%d", last.Offset, last.End())
+	}
+}
+
+func TestHashDeepPiecewiseAuditRoundTrip(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"xxh3", "blake3"}
+	HashLengths["blake3"] = 512
+	Piecewise = 10
+	PiecewiseInput = "10"
+	t.Cleanup(func() { Piecewise, PiecewiseInput = 0, "" })
+
+	res, err := processReader("test", bytes.NewReader(bytes.Repeat([]byte("0123456789abc"), 5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := make(chan Result, 1)
+	input <- res
+	close(input)
+	out := toHashDeep(input)
+
+	if !bytes.Contains([]byte(out), []byte("%%%% size,xxh3,blake3:512,filename\n")) {
+		t.Errorf("expected the enabled hashes in the header got\n%s", out)
+	}
+
+	hdl, err := NewAuditor(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdl.PieceSize() != 10 || hdl.Lengths()["blake3"] != 512 || len(hdl.Hashes()) != 2 {
+		t.Errorf("unexpected audit file %d %v %v", hdl.PieceSize(), hdl.Lengths(), hdl.Hashes())
+	}
+	if status := hdl.Find(res); status != FileMatched {
+		t.Errorf("expected the file to match its own audit got %v", status)
+	}
+}
+
+func TestPiecewiseBlockHashes(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"md5", "tlsh", "cidv1"}
+	Piecewise = 10
+	t.Cleanup(func() { Piecewise = 0 })
+
+	// fuzzy hashes and content identifiers are only calculated for the whole file
+	blocks, err := piecewiseBlocks("test", bytes.Repeat([]byte("0123456789abc"), 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range blocks {
+		if b.MD5 == "" || b.TLSH != "" || b.CIDv1 != "" {
+			t.Errorf("expected only md5 for block %d got %v", b.Offset, b.Result)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"text/template"
 
 	"github.com/gosuri/uiprogress"
 )
@@ -59,6 +60,123 @@ var FileOutput = ""
 // AuditFile sets the file that we want to audit against similar to hashdeep
 var AuditFile = ""
 
+// SipHashKeyInput is the hex encoded key for siphash
+var SipHashKeyInput = ""
+
+// HighwayHashKeyInput is the hex encoded key for highwayhash
+var HighwayHashKeyInput = ""
+
+// PiecewiseInput is the block size for piecewise hashing such as 1m
+var PiecewiseInput = ""
+
+// Piecewise is the block size in bytes for piecewise hashing, or 0 to hash whole files only
+var Piecewise int64 = 0
+
+// TorrentVersion is the BitTorrent metainfo version to create which is one of v1, v2 or hybrid
+var TorrentVersion = "hybrid"
+
+// TorrentPieceLength is the piece length for created torrents such as 256k, chosen from the size if empty
+var TorrentPieceLength = ""
+
+// TorrentTrackers are the announce URLs written to created torrents
+var TorrentTrackers = []string{}
+
+// TorrentComment is the comment written to created torrents
+var TorrentComment = ""
+
+// TorrentPrivate marks created torrents as private
+var TorrentPrivate = false
+
+// Tree adds a result for each directory with its git tree IDs
+var Tree = false
+
+// DirHashPrefix is the module@version prefix for Go dirhash, taken from the module cache path when empty
+var DirHashPrefix = ""
+
+// ConvertFrom is the format of the manifest read by convert, detected from its contents when empty
+var ConvertFrom = ""
+
+// ConvertTo is the output format convert writes the manifest as
+var ConvertTo = ""
+
+// SumHashes are the hashes of sum manifest lines without a hash name, set from --hash when converting or diffing
+var SumHashes []string
+
+// StoreInto is the directory files are stored in by their digest
+var StoreInto = ""
+
+// StoreLink hardlinks files into the store rather than copying them
+var StoreLink = false
+
+// DedupeMethod is how duplicates are replaced which is one of reflink, hardlink or auto
+var DedupeMethod = "reflink"
+
+// DedupeDryRun reports the duplicates and bytes which could be reclaimed without changing anything
+var DedupeDryRun = false
+
+// DedupeJournal is the file each replaced duplicate is recorded in so it can be undone
+var DedupeJournal = "hashit-dedupe.journal"
+
+// DedupeUndo restores every file recorded in the journal to its own copy
+var DedupeUndo = false
+
+// MtreeKeywords are the keywords written for each entry when creating an mtree spec
+var MtreeKeywords = []string{}
+
+// NarBase32 prints NAR hashes using the Nix base32 encoding rather than SRI
+var NarBase32 = false
+
+// Encoding sets how digests are written which is one of hex, base64, base64url, base32 or nix32
+var Encoding = "hex"
+
+// InTotoPredicateType is the predicateType of in-toto statements, which by default says only the subject digests are attested
+var InTotoPredicateType = "https://github.com/boyter/hashit/digests/v1"
+
+// InTotoPredicate is a JSON file used as the predicate of in-toto statements, which is empty if not set
+var InTotoPredicate = ""
+
+// SigningKey is a PEM encoded private key used to sign in-toto statements in a DSSE envelope
+var SigningKey = ""
+
+// Template is a Go text/template executed for each result in place of the format
+var Template = ""
+
+// TemplateFile is a file holding the template, which takes precedence over Template
+var TemplateFile = ""
+
+// Columns are the columns written by the csv and tsv formats, which may be file, bytes, mtime or any hash
+var Columns = []string{}
+
+// WriteSums is the kind of checksum file to write into each directory such as SHA256SUMS or sfv
+var WriteSums = ""
+
+// VerifySums finds every checksum file below the paths and checks the files they list
+var VerifySums = false
+
+// VerifyCAS finds every blob below the paths named by its digest and checks its content matches
+var VerifyCAS = false
+
+// auditor holds the parsed audit file when auditing
+var auditor *Auditor
+
+// OutputFormats are extra outputs written in the same pass given as FORMAT=PATH
+var OutputFormats []string
+
+// readHashes are the hashes calculated for the main output and every extra output, or nil when only Hash is used
+var readHashes []string
+
+// outputTemplate holds the parsed template when using --template or --template-file
+var outputTemplate *template.Template
+
+// SimilarTo is a file, ssdeep or TLSH hash that files are compared against for similarity
+var SimilarTo = ""
+
+// Threshold is the ssdeep score files must be above to be reported as similar
+var Threshold = 0
+
+// TLSHThreshold is the TLSH distance files must be at or below to be reported as similar
+var TLSHThreshold = 100
+
 // DirFilePaths is not set via flags but by arguments following the flags for file or directory to process
 var DirFilePaths = []string{}
 
@@ -96,21 +214,84 @@ var NoThreads = runtime.NumCPU()
 
 // String mapping for hash names
 var HashNames = Result{
-	CRC32:      "crc32",
-	XxHash64:   "xxhash64",
-	MD4:        "md4",
-	MD5:        "md5",
-	SHA1:       "sha1",
-	SHA256:     "sha256",
-	SHA512:     "sha512",
-	Blake2b256: "blake2b256",
-	Blake2b512: "blake2b512",
-	Blake3:     "blake3",
-	Sha3224:    "sha3224",
-	Sha3256:    "sha3256",
-	Sha3384:    "sha3384",
-	Sha3512:    "sha3512",
-	Ed2k:       "ed2k",
+	CRC32:       "crc32",
+	XxHash64:    "xxhash64",
+	CRC32C:      "crc32c",
+	CRC64ECMA:   "crc64ecma",
+	CRC64ISO:    "crc64iso",
+	Adler32:     "adler32",
+	XXH3:        "xxh3",
+	XXH128:      "xxh128",
+	Murmur3:     "murmur3",
+	SipHash:     "siphash",
+	HighwayHash: "highwayhash",
+	MD4:         "md4",
+	MD5:         "md5",
+	SHA1:        "sha1",
+	SHA256:      "sha256",
+	SHA384:      "sha384",
+	SHA512:      "sha512",
+	Blake2b256:  "blake2b256",
+	Blake2b512:  "blake2b512",
+	Blake3:      "blake3",
+	Sha3224:     "sha3224",
+	Sha3256:     "sha3256",
+	Sha3384:     "sha3384",
+	Sha3512:     "sha3512",
+	Shake128:    "shake128",
+	Shake256:    "shake256",
+	CShake128:   "cshake128",
+	CShake256:   "cshake256",
+	Ed2k:        "ed2k",
+	AICH:        "aich",
+	TTH:         "tth",
+	CIDv0:       "cidv0",
+	CIDv1:       "cidv1",
+	GitSHA1:     "git-sha1",
+	GitSHA256:   "git-sha256",
+	SSDeep:      "ssdeep",
+	TLSH:        "tlsh",
+}
+
+// allHashes is every hash in the order they are listed by --hashes
+var allHashes = []string{
+	HashNames.CRC32,
+	HashNames.XxHash64,
+	HashNames.CRC32C,
+	HashNames.CRC64ECMA,
+	HashNames.CRC64ISO,
+	HashNames.Adler32,
+	HashNames.XXH3,
+	HashNames.XXH128,
+	HashNames.Murmur3,
+	HashNames.SipHash,
+	HashNames.HighwayHash,
+	HashNames.MD4,
+	HashNames.MD5,
+	HashNames.SHA1,
+	HashNames.SHA256,
+	HashNames.SHA384,
+	HashNames.SHA512,
+	HashNames.Blake2b256,
+	HashNames.Blake2b512,
+	HashNames.Blake3,
+	HashNames.Sha3224,
+	HashNames.Sha3256,
+	HashNames.Sha3384,
+	HashNames.Sha3512,
+	HashNames.Shake128,
+	HashNames.Shake256,
+	HashNames.CShake128,
+	HashNames.CShake256,
+	HashNames.Ed2k,
+	HashNames.AICH,
+	HashNames.TTH,
+	HashNames.CIDv0,
+	HashNames.CIDv1,
+	HashNames.GitSHA1,
+	HashNames.GitSHA256,
+	HashNames.SSDeep,
+	HashNames.TLSH,
 }
 
 // Process is the main entry point of the command line it sets everything up and starts running
@@ -141,24 +322,186 @@ func Process() {
 		Recursive = true
 	}
 
-	// Clean up hashes by setting all input to lowercase
-	Hash = formatHashInput()
+	err := prepareHashes()
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+
+	// Checking checksum files replaces hashing as the files to hash come from the checksum files
+	if VerifySums {
+		out, ok := verifySums(DirFilePaths)
+		fmt.Print(out)
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Content addressed blobs are named by their digest which says which hash to use
+	if VerifyCAS {
+		out, ok := verifyCAS(DirFilePaths)
+		fmt.Print(out)
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Where audit file is set we only want to process the hashes that hashdeep supports for this
-	// NB we will need to expand this in the future when supporting our own format
+	// Where audit file is set we only want to process the hashes that the audit file contains,
+	// and as the audit file digests are decoded to hex the results need to be hex as well
 	if AuditFile != "" {
-		Hash = []string{"md5", "sha256"}
+		Encoding = "hex"
+		var err error
+		auditor, err = loadAuditor(AuditFile)
+		if err != nil {
+			printError(fmt.Sprintf("unable to load audit file: %s %s", AuditFile, err.Error()))
+			os.Exit(1)
+		}
+		Hash = auditor.Hashes()
+		for name, bits := range auditor.Lengths() {
+			HashLengths[name] = bits
+		}
+	}
+
+	// Piecewise hashing splits files into blocks, however an audit must use the
+	// same blocks as the audit file so it takes precedence
+	if PiecewiseInput != "" {
+		Piecewise, err = parseSize(PiecewiseInput)
+		if err != nil {
+			printError(fmt.Sprintf("invalid piecewise size: %s", err.Error()))
+			os.Exit(1)
+		}
+	}
+	if auditor != nil {
+		Piecewise = auditor.PieceSize()
+	}
+
+	// When looking for similar files only the fuzzy hashes are required
+	if SimilarTo != "" {
+		similarTargets, err = loadSimilarTargets(SimilarTo)
+		if err != nil {
+			printError(fmt.Sprintf("unable to load similar to: %s %s", SimilarTo, err.Error()))
+			os.Exit(1)
+		}
+		Hash = similarHashes(similarTargets)
 	}
 
 	// If format is set to hashdeep
 	if strings.ToLower(Format) == "hashdeep" {
-		// if hashdeep, we need to ensure md5 is set or sha256, or both, and if neither are set both
-		if !hasHash("md5") || !hasHash("sha256") {
+		// if hashdeep, we need at least one hash which can be written, and if there are none md5 and sha256
+		if len(enabledHashes()) == 0 {
 			printError("hashdeep format without supported hash - setting md5 and sha256")
 			Hash = []string{"md5", "sha256"}
 		}
 	}
 
+	// SPDX requires SHA1 for every file which is also used for the package verification code
+	if spdxFormat(Format) && !hasHash(HashNames.SHA1) {
+		Hash = append(Hash, HashNames.SHA1)
+	}
+
+	// Links need at least one hash they can be built from
+	if strings.ToLower(Format) == "uri" {
+		found := false
+		for _, h := range uriHashes {
+			found = found || hasHash(h)
+		}
+		if !found {
+			Hash = uriHashes
+		}
+	}
+
+	// Directories only have git tree IDs so tree mode is limited to the git hashes
+	if Tree {
+		var gitHashes []string
+		for _, h := range []string{HashNames.GitSHA1, HashNames.GitSHA256} {
+			if hasHash(h) {
+				gitHashes = append(gitHashes, h)
+			}
+		}
+		if len(gitHashes) == 0 {
+			gitHashes = []string{HashNames.GitSHA1}
+		}
+		if len(gitHashes) != len(Hash) {
+			printError(fmt.Sprintf("tree mode only supports git hashes - setting %s", strings.Join(gitHashes, ",")))
+		}
+		Hash = gitHashes
+	}
+
+	// Integrity strings need at least one of the hashes SRI supports
+	if strings.ToLower(Format) == "sri" {
+		found := false
+		for _, h := range sriHashes {
+			found = found || hasHash(h)
+		}
+		if !found {
+			Hash = []string{HashNames.SHA384}
+		}
+	}
+
+	// in-toto subjects need at least one digest, with sha256 being the one most tools expect
+	if strings.ToLower(Format) == "in-toto" {
+		found := false
+		for _, d := range inTotoDigests {
+			found = found || hasHash(d.hash)
+		}
+		if !found {
+			Hash = []string{HashNames.SHA256}
+		}
+	}
+
+	// Checksum files are written using a single hash in hex
+	if WriteSums != "" {
+		hash := sumsHash(WriteSums)
+		if hash == "" {
+			var kinds []string
+			for _, s := range sumsFiles {
+				kinds = append(kinds, strings.TrimPrefix(s.name, "."))
+			}
+			printError(fmt.Sprintf("unknown checksum file %s, must be one of %s", WriteSums, strings.Join(kinds, ", ")))
+			os.Exit(1)
+		}
+		Hash = []string{hash}
+		Encoding = "hex"
+	}
+
+	// Any hashes used as columns need to be calculated
+	if tabularFormat(Format) {
+		if err := checkColumns(); err != nil {
+			printError(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// Every extra output needs its hashes calculated as well, however they are
+	// only written to that output so the main output is unchanged
+	sinks, err := parseOutputSinks(OutputFormats)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	if len(sinks) != 0 {
+		lists := [][]string{Hash}
+		for i := range sinks {
+			sinks[i].hashes, err = sinkHashes(sinks[i].format, Hash)
+			if err != nil {
+				printError(err.Error())
+				os.Exit(1)
+			}
+			lists = append(lists, sinks[i].hashes)
+		}
+		readHashes = unionHashes(lists...)
+	}
+
+	if Template != "" || TemplateFile != "" {
+		outputTemplate, err = loadTemplate()
+		if err != nil {
+			printError(fmt.Sprintf("unable to load template: %s", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// Results ready to be printed
 	fileSummaryQueue := make(chan Result, FileListQueueSize)
 
@@ -241,7 +584,14 @@ func Process() {
 		}()
 	}
 
-	result, valid := fileSummarize(fileSummaryQueue)
+	summaries := fileSummaryQueue
+	if Tree && !StandardInput {
+		summaries = treeResults(fileSummaryQueue)
+	}
+
+	summaries, sinksDone := startOutputSinks(sinks, summaries)
+	result, valid := fileSummarize(summaries)
+	sinksValid := sinksDone()
 
 	if FileOutput == "" {
 		fmt.Print(result)
@@ -252,18 +602,86 @@ func Process() {
 		// we don't write out sqlite
 		if strings.ToLower(Format) != "sqlite" {
 			_ = os.WriteFile(FileOutput, []byte(result), 0600)
+		} else if !valid {
+			// the error writing to the database has already been reported
+			os.Exit(1)
 		}
 		fmt.Println("results written to " + FileOutput)
+		if !valid {
+			os.Exit(1)
+		}
+	}
+
+	if !sinksValid {
+		os.Exit(1)
+	}
+}
+
+// prepareHashes cleans up the hashes by setting all input to lowercase and pulling
+// out any digest lengths, then checks the encoding and keys so files can be hashed
+func prepareHashes() error {
+	var err error
+	Hash, err = formatHashInput()
+	if err != nil {
+		return err
+	}
+
+	if !contains(encodings, strings.ToLower(Encoding)) {
+		return fmt.Errorf("unknown encoding %s, must be one of %s", Encoding, strings.Join(encodings, ", "))
 	}
+
+	// Keyed hashes default to a zero key but can be supplied as hex
+	SipHashKey, err = parseHashKey(SipHashKeyInput, 16)
+	if err != nil {
+		return fmt.Errorf("invalid siphash key: %s", err.Error())
+	}
+	HighwayHashKey, err = parseHashKey(HighwayHashKeyInput, 32)
+	if err != nil {
+		return fmt.Errorf("invalid highwayhash key: %s", err.Error())
+	}
+	return nil
 }
 
-// ToLower all of the input hashes so we can match them easily
-func formatHashInput() []string {
+// ToLower all of the input hashes so we can match them easily, and record
+// the digest length of any supplied using the name:bits syntax
+func formatHashInput() ([]string, error) {
 	h := []string{}
 	for _, x := range Hash {
-		h = append(h, strings.ToLower(x))
+		name, bits, err := parseHashLength(strings.ToLower(x))
+		if err != nil {
+			return nil, err
+		}
+		if bits != 0 {
+			HashLengths[name] = bits
+		}
+		h = append(h, name)
+	}
+	return h, nil
+}
+
+// enabledHashes returns every hash being calculated in the order of allHashes
+func enabledHashes() []string {
+	var hashes []string
+	for _, h := range allHashes {
+		if hasHash(h) {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes
+}
+
+// readHash returns true if the hash is calculated when reading files, which
+// includes the hashes needed by any extra outputs as well as those in Hash
+func readHash(hash string) bool {
+	if readHashes == nil {
+		return hasHash(hash)
 	}
-	return h
+	for _, x := range readHashes {
+		if x == "all" || x == hash {
+			return true
+		}
+	}
+	return false
 }
 
 // Check if a hash was supplied to the input so we know if we should calculate it
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package processor
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile makes dst share the blocks of src using the FICLONE ioctl, which
+// only works within a filesystem supporting reflinks such as btrfs or XFS
+func cloneFile(dst *os.File, src *os.File) error {
+	return unix.IoctlFileClone(int(dst.Fd()), int(src.Fd()))
+}
@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package processor
+
+import (
+	"errors"
+	"os"
+)
+
+// cloneFile is unsupported where there is no FICLONE ioctl
+func cloneFile(dst *os.File, src *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sbomAlgorithm maps our hash names to those used by SPDX and CycloneDX where
+// an empty name means the format has no name for the hash
+type sbomAlgorithm struct {
+	hash      string
+	spdx      string
+	cyclonedx string
+}
+
+var sbomAlgorithms = []sbomAlgorithm{
+	{hash: "adler32", spdx: "ADLER32"},
+	{hash: "md4", spdx: "MD4"},
+	{hash: "md5", spdx: "MD5", cyclonedx: "MD5"},
+	{hash: "sha1", spdx: "SHA1", cyclonedx: "SHA-1"},
+	{hash: "sha256", spdx: "SHA256", cyclonedx: "SHA-256"},
+	{hash: "sha384", spdx: "SHA384", cyclonedx: "SHA-384"},
+	{hash: "sha512", spdx: "SHA512", cyclonedx: "SHA-512"},
+	{hash: "blake2b256", spdx: "BLAKE2b-256", cyclonedx: "BLAKE2b-256"},
+	{hash: "blake2b512", spdx: "BLAKE2b-512", cyclonedx: "BLAKE2b-512"},
+	{hash: "blake3", spdx: "BLAKE3", cyclonedx: "BLAKE3"},
+	{hash: "sha3256", spdx: "SHA3-256", cyclonedx: "SHA3-256"},
+	{hash: "sha3384", spdx: "SHA3-384", cyclonedx: "SHA3-384"},
+	{hash: "sha3512", spdx: "SHA3-512", cyclonedx: "SHA3-512"},
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                    string                  `json:"name"`
+	SPDXID                  string                  `json:"SPDXID"`
+	DownloadLocation        string                  `json:"downloadLocation"`
+	FilesAnalyzed           bool                    `json:"filesAnalyzed"`
+	PackageVerificationCode spdxPackageVerification `json:"packageVerificationCode"`
+	LicenseConcluded        string                  `json:"licenseConcluded"`
+	LicenseDeclared         string                  `json:"licenseDeclared"`
+	CopyrightText           string                  `json:"copyrightText"`
+}
+
+type spdxPackageVerification struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+type spdxFile struct {
+	FileName         string         `json:"fileName"`
+	SPDXID           string         `json:"SPDXID"`
+	Checksums        []spdxChecksum `json:"checksums"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	CopyrightText    string         `json:"copyrightText"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type cycloneDXDocument struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type    string          `json:"type"`
+	BOMRef  string          `json:"bom-ref,omitempty"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	Hashes  []cycloneDXHash `json:"hashes,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+// sbomResults collects every result sorted by filename so documents are
+// the same however the files were processed
+func sbomResults(input chan Result) []Result {
+	results := []Result{}
+	for res := range input {
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].File < results[j].File
+	})
+	return results
+}
+
+// sbomRoot is the directory being described, which is the directory
+// supplied when there is only one, otherwise where we are
+func sbomRoot() string {
+	if len(DirFilePaths) == 1 {
+		if fi, err := os.Stat(DirFilePaths[0]); err == nil && fi.IsDir() {
+			return DirFilePaths[0]
+		}
+	}
+	return "."
+}
+
+// sbomName is the name of the package being described
+func sbomName() string {
+	abs, err := filepath.Abs(sbomRoot())
+	if err != nil {
+		return "hashit"
+	}
+	return filepath.Base(abs)
+}
+
+// sbomFileName is the path to the file relative to the package starting
+// with ./ as SPDX requires
+func sbomFileName(file string) string {
+	if rel, err := filepath.Rel(sbomRoot(), file); err == nil && !strings.HasPrefix(rel, "..") {
+		return "./" + filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(file)
+}
+
+// sbomDigest returns the digest as lowercase hex which both formats require
+func sbomDigest(res Result, name string) string {
+	return hex.EncodeToString(decodeEncoded(res.Digest(name)))
+}
+
+func sbomToolVersion() string {
+	version, _, _ := strings.Cut(Version, " ")
+	return version
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// spdxVerificationCode is the SHA1 of the sorted SHA1s of every file in the package
+func spdxVerificationCode(results []Result) string {
+	sums := make([]string, 0, len(results))
+	for _, res := range results {
+		sums = append(sums, sbomDigest(res, HashNames.SHA1))
+	}
+	sort.Strings(sums)
+
+	h := sha1.New()
+	for _, s := range sums {
+		_, _ = h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func spdxChecksums(res Result) []spdxChecksum {
+	checksums := []spdxChecksum{}
+	for _, a := range sbomAlgorithms {
+		if a.spdx != "" && hasHash(a.hash) {
+			checksums = append(checksums, spdxChecksum{Algorithm: a.spdx, Value: sbomDigest(res, a.hash)})
+		}
+	}
+	return checksums
+}
+
+func newSPDXDocument(results []Result) spdxDocument {
+	name := sbomName()
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/hashit-%s-%s", url.PathEscape(name), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: hashit-" + sbomToolVersion()},
+		},
+		Packages: []spdxPackage{{
+			Name:                    name,
+			SPDXID:                  "SPDXRef-Package",
+			DownloadLocation:        "NOASSERTION",
+			FilesAnalyzed:           true,
+			PackageVerificationCode: spdxPackageVerification{Value: spdxVerificationCode(results)},
+			LicenseConcluded:        "NOASSERTION",
+			LicenseDeclared:         "NOASSERTION",
+			CopyrightText:           "NOASSERTION",
+		}},
+		Files: []spdxFile{},
+		Relationships: []spdxRelationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Package"},
+		},
+	}
+
+	for i, res := range results {
+		id := fmt.Sprintf("SPDXRef-File-%d", i+1)
+		doc.Files = append(doc.Files, spdxFile{
+			FileName:         sbomFileName(res.File),
+			SPDXID:           id,
+			Checksums:        spdxChecksums(res),
+			LicenseConcluded: "NOASSERTION",
+			CopyrightText:    "NOASSERTION",
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: "SPDXRef-Package", Type: "CONTAINS", Related: id})
+	}
+
+	return doc
+}
+
+func toSPDXJSON(input chan Result) string {
+	jsonString, _ := json.MarshalIndent(newSPDXDocument(sbomResults(input)), "", "  ")
+	return string(jsonString) + "\n"
+}
+
+// toSPDXTagValue writes the same document as toSPDXJSON using the tag:value format
+func toSPDXTagValue(input chan Result) string {
+	doc := newSPDXDocument(sbomResults(input))
+	var str strings.Builder
+
+	str.WriteString("SPDXVersion: " + doc.SPDXVersion + "\n")
+	str.WriteString("DataLicense: " + doc.DataLicense + "\n")
+	str.WriteString("SPDXID: " + doc.SPDXID + "\n")
+	str.WriteString("DocumentName: " + doc.Name + "\n")
+	str.WriteString("DocumentNamespace: " + doc.DocumentNamespace + "\n")
+	for _, c := range doc.CreationInfo.Creators {
+		str.WriteString("Creator: " + c + "\n")
+	}
+	str.WriteString("Created: " + doc.CreationInfo.Created + "\n")
+
+	for _, p := range doc.Packages {
+		str.WriteString("\n")
+		str.WriteString("PackageName: " + p.Name + "\n")
+		str.WriteString("SPDXID: " + p.SPDXID + "\n")
+		str.WriteString("PackageDownloadLocation: " + p.DownloadLocation + "\n")
+		str.WriteString("FilesAnalyzed: true\n")
+		str.WriteString("PackageVerificationCode: " + p.PackageVerificationCode.Value + "\n")
+		str.WriteString("PackageLicenseConcluded: " + p.LicenseConcluded + "\n")
+		str.WriteString("PackageLicenseDeclared: " + p.LicenseDeclared + "\n")
+		str.WriteString("PackageCopyrightText: " + p.CopyrightText + "\n")
+	}
+
+	for _, r := range doc.Relationships {
+		if r.Type == "DESCRIBES" {
+			str.WriteString("Relationship: " + r.Element + " " + r.Type + " " + r.Related + "\n")
+		}
+	}
+
+	for _, f := range doc.Files {
+		str.WriteString("\n")
+		str.WriteString("FileName: " + f.FileName + "\n")
+		str.WriteString("SPDXID: " + f.SPDXID + "\n")
+		for _, c := range f.Checksums {
+			str.WriteString("FileChecksum: " + c.Algorithm + ": " + c.Value + "\n")
+		}
+		str.WriteString("LicenseConcluded: " + f.LicenseConcluded + "\n")
+		str.WriteString("FileCopyrightText: " + f.CopyrightText + "\n")
+		str.WriteString("Relationship: SPDXRef-Package CONTAINS " + f.SPDXID + "\n")
+	}
+
+	return str.String()
+}
+
+func toCycloneDXJSON(input chan Result) string {
+	results := sbomResults(input)
+	doc := cycloneDXDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: cycloneDXTools{Components: []cycloneDXComponent{
+				{Type: "application", Name: "hashit", Version: sbomToolVersion()},
+			}},
+			Component: cycloneDXComponent{Type: "application", BOMRef: "package", Name: sbomName()},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	for i, res := range results {
+		component := cycloneDXComponent{
+			Type:   "file",
+			BOMRef: fmt.Sprintf("file-%d", i+1),
+			Name:   sbomFileName(res.File),
+		}
+		for _, a := range sbomAlgorithms {
+			if a.cyclonedx != "" && hasHash(a.hash) {
+				component.Hashes = append(component.Hashes, cycloneDXHash{Algorithm: a.cyclonedx, Content: sbomDigest(res, a.hash)})
+			}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+
+	jsonString, _ := json.MarshalIndent(doc, "", "  ")
+	return string(jsonString) + "\n"
+}
+
+// spdxFormat returns true for the SPDX formats which require SHA1 for every file
+func spdxFormat(format string) bool {
+	format = strings.ToLower(format)
+	return format == "spdx-json" || format == "spdx-tv"
+}
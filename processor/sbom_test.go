@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSPDXVerificationCode(t *testing.T) {
+	results := []Result{
+		{File: "b", SHA1: "cde23c275ddc26fc56c501fa56b004111ec9ded7"},
+		{File: "a", SHA1: "72983676a93b087a19b36efd81257a3f5069c077"},
+		{File: "empty", SHA1: "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+	}
+
+	expected := "3b62650284dda62db7eff731adf03a11747db88b"
+	if got := spdxVerificationCode(results); got != expected {
+		t.Errorf("Expected %s got %s", expected, got)
+	}
+}
+
+// sbomInput describes a directory returning results for two files in it, out of
+// order so the formatters have to sort them
+func sbomInput(t *testing.T) chan Result {
+	t.Cleanup(resetState)
+	dir := t.TempDir()
+	DirFilePaths = []string{dir}
+	t.Cleanup(func() { DirFilePaths = []string{} })
+	Hash = []string{"md4", "sha1", "sha256"}
+
+	input := make(chan Result, 2)
+	input <- Result{
+		File:   filepath.Join(dir, "sub", "b.txt"),
+		MD4:    "31d6cfe0d16ae931b73c59d7e0c089c0",
+		SHA1:   "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	input <- Result{
+		File:   filepath.Join(dir, "a.txt"),
+		MD4:    "aa010fbc1d14c795d86ef98c95479d17",
+		SHA1:   "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+		SHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+	}
+	close(input)
+	return input
+}
+
+func TestToSPDXJSON(t *testing.T) {
+	var doc spdxDocument
+	if err := json.Unmarshal([]byte(toSPDXJSON(sbomInput(t))), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || doc.SPDXID != "SPDXRef-DOCUMENT" ||
+		!strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/hashit-") || doc.CreationInfo.Created == "" ||
+		len(doc.CreationInfo.Creators) != 1 || !strings.HasPrefix(doc.CreationInfo.Creators[0], "Tool: hashit-") {
+		t.Errorf("unexpected document fields %+v", doc)
+	}
+	if len(doc.Packages) != 1 || doc.Packages[0].PackageVerificationCode.Value != "f466272f4cf0456ac3277a95abd75961a87fe7fd" {
+		t.Errorf("unexpected package %+v", doc.Packages)
+	}
+
+	if len(doc.Files) != 2 || doc.Files[0].FileName != "./a.txt" || doc.Files[1].FileName != "./sub/b.txt" {
+		t.Fatalf("expected relative sorted file names got %+v", doc.Files)
+	}
+	expected := []spdxChecksum{
+		{Algorithm: "MD4", Value: "aa010fbc1d14c795d86ef98c95479d17"},
+		{Algorithm: "SHA1", Value: "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"},
+		{Algorithm: "SHA256", Value: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+	}
+	if len(doc.Files[0].Checksums) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, doc.Files[0].Checksums)
+	}
+	for i, c := range expected {
+		if doc.Files[0].Checksums[i] != c {
+			t.Errorf("expected %v got %v", c, doc.Files[0].Checksums[i])
+		}
+	}
+
+	// the package describes and contains every file
+	if len(doc.Relationships) != 3 || doc.Relationships[0].Type != "DESCRIBES" || doc.Relationships[2].Related != doc.Files[1].SPDXID {
+		t.Errorf("unexpected relationships %+v", doc.Relationships)
+	}
+}
+
+func TestToSPDXTagValue(t *testing.T) {
+	out := toSPDXTagValue(sbomInput(t))
+
+	for _, expected := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DataLicense: CC0-1.0\n",
+		"SPDXID: SPDXRef-DOCUMENT\n",
+		"DocumentNamespace: https://spdx.org/spdxdocs/hashit-",
+		"Creator: Tool: hashit-",
+		"PackageVerificationCode: f466272f4cf0456ac3277a95abd75961a87fe7fd\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package\n",
+		"FileName: ./a.txt\nSPDXID: SPDXRef-File-1\n" +
+			"FileChecksum: MD4: aa010fbc1d14c795d86ef98c95479d17\n" +
+			"FileChecksum: SHA1: 2aae6c35c94fcfb415dbe95f408b9ce91ee846ed\n" +
+			"FileChecksum: SHA256: b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9\n",
+		"FileName: ./sub/b.txt\n",
+		"Relationship: SPDXRef-Package CONTAINS SPDXRef-File-2\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in\n%s", expected, out)
+		}
+	}
+}
+
+func TestToCycloneDXJSON(t *testing.T) {
+	var doc cycloneDXDocument
+	if err := json.Unmarshal([]byte(toCycloneDXJSON(sbomInput(t))), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") ||
+		doc.Version != 1 || doc.Metadata.Timestamp == "" || len(doc.Metadata.Tools.Components) != 1 {
+		t.Errorf("unexpected document fields %+v", doc)
+	}
+
+	if len(doc.Components) != 2 || doc.Components[0].Name != "./a.txt" || doc.Components[1].Name != "./sub/b.txt" {
+		t.Fatalf("expected relative sorted file names got %+v", doc.Components)
+	}
+	// CycloneDX has no name for MD4 and spells the SHA names with a dash
+	expected := []cycloneDXHash{
+		{Algorithm: "SHA-1", Content: "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"},
+		{Algorithm: "SHA-256", Content: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+	}
+	if len(doc.Components[0].Hashes) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, doc.Components[0].Hashes)
+	}
+	for i, h := range expected {
+		if doc.Components[0].Hashes[i] != h {
+			t.Errorf("expected %v got %v", h, doc.Components[0].Hashes[i])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/glaslos/ssdeep"
+)
+
+var ssdeepRegex = regexp.MustCompile(`^\d+:[^:]*:[^:]*$`)
+
+// similarTargets holds the fuzzy hashes that files are compared against when using --similar-to
+var similarTargets Result
+
+// loadSimilarTargets works out if we were given a fuzzy hash or a file, and
+// if a file calculates its fuzzy hashes so we have something to compare to
+func loadSimilarTargets(input string) (Result, error) {
+	if isTLSH(input) {
+		return Result{File: input, TLSH: strings.ToUpper(input)}, nil
+	}
+
+	if ssdeepRegex.MatchString(input) {
+		return Result{File: input, SSDeep: input}, nil
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+
+	ssdeep_d := ssdeep.New()
+	tlsh_d := newTLSH()
+	if _, err := io.Copy(io.MultiWriter(ssdeep_d, tlsh_d), file); err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		File:   input,
+		SSDeep: string(ssdeep_d.Sum(nil)),
+		TLSH:   string(tlsh_d.Sum(nil)),
+	}
+
+	// too small or too uniform for TLSH so rely on ssdeep alone
+	if res.TLSH == tlshNull {
+		res.TLSH = ""
+	}
+
+	return res, nil
+}
+
+// similarHashes returns the fuzzy hashes which need to be calculated to compare against the targets
+func similarHashes(targets Result) []string {
+	h := []string{}
+	if targets.SSDeep != "" {
+		h = append(h, HashNames.SSDeep)
+	}
+	if targets.TLSH != "" {
+		h = append(h, HashNames.TLSH)
+	}
+	return h
+}
+
+// doSimilar reports every file whose fuzzy hash is close enough to the target. For ssdeep
+// the score must be above the threshold where 100 is identical, and for TLSH the distance
+// must be at or below the threshold where 0 is identical.
+func doSimilar(input chan Result) (string, bool) {
+	var str strings.Builder
+
+	for res := range input {
+		matched := false
+		scores := []string{}
+
+		if similarTargets.SSDeep != "" && res.SSDeep != "" {
+			score, err := ssdeep.Distance(similarTargets.SSDeep, res.SSDeep)
+			if err == nil {
+				scores = append(scores, fmt.Sprintf("ssdeep %d", score))
+				if score > Threshold {
+					matched = true
+				}
+			}
+		}
+
+		if similarTargets.TLSH != "" && res.TLSH != "" && res.TLSH != tlshNull {
+			distance, err := tlshDistance(similarTargets.TLSH, res.TLSH)
+			if err == nil {
+				scores = append(scores, fmt.Sprintf("tlsh %d", distance))
+				if distance <= TLSHThreshold {
+					matched = true
+				}
+			}
+		}
+
+		if matched {
+			str.WriteString(fmt.Sprintf("%s matches %s (%s)\n", res.File, similarTargets.File, strings.Join(scores, ", ")))
+		} else if VeryVerbose {
+			fmt.Printf("%v: No match\n", res.File)
+		}
+
+		if !NoStream && FileOutput == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	return str.String(), true
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// outputSink is an extra format written to its own file alongside the main output
+type outputSink struct {
+	format string
+	path   string
+	hashes []string // hashes written to the output which may differ from the main output
+}
+
+// parseOutputSinks reads the FORMAT=PATH values given to --output-format
+func parseOutputSinks(values []string) ([]outputSink, error) {
+	var sinks []outputSink
+	paths := map[string]bool{}
+	// sqlite always writes to a file so the main output may be the default database
+	if FileOutput != "" {
+		paths[filepath.Clean(FileOutput)] = true
+	} else if strings.ToLower(Format) == "sqlite" {
+		paths["hashit.db"] = true
+	}
+	for _, v := range values {
+		format, path, ok := strings.Cut(v, "=")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !ok || format == "" || path == "" {
+			return nil, fmt.Errorf("invalid output format %q, must be FORMAT=PATH", v)
+		}
+		if !contains(outputFormats, format) {
+			return nil, fmt.Errorf("unknown output format %s, must be one of %s", format, strings.Join(outputFormats, ", "))
+		}
+		if paths[filepath.Clean(path)] {
+			return nil, fmt.Errorf("output %s is used more than once", path)
+		}
+		paths[filepath.Clean(path)] = true
+		sinks = append(sinks, outputSink{format: format, path: path})
+	}
+	return sinks, nil
+}
+
+// sinkHashes returns the hashes with any the format needs added, which unlike
+// --format keeps the existing hashes as the output is written using all of them
+func sinkHashes(format string, hashes []string) ([]string, error) {
+	hashes = append([]string{}, hashes...)
+	has := func(hash string) bool {
+		return contains(hashes, hash) || contains(hashes, "all")
+	}
+	need := func(names ...string) {
+		for _, h := range names {
+			if !has(h) {
+				hashes = append(hashes, h)
+			}
+		}
+	}
+	needAny := func(names []string, fallback string) {
+		for _, h := range names {
+			if has(h) {
+				return
+			}
+		}
+		need(fallback)
+	}
+
+	switch {
+	case format == "hashdeep":
+		need(HashNames.MD5, HashNames.SHA256)
+	case spdxFormat(format):
+		need(HashNames.SHA1)
+	case format == "uri":
+		needAny(uriHashes, uriHashes[0])
+	case format == "sri":
+		needAny(sriHashes, HashNames.SHA384)
+	case format == "in-toto":
+		var digests []string
+		for _, d := range inTotoDigests {
+			digests = append(digests, d.hash)
+		}
+		needAny(digests, HashNames.SHA256)
+	case tabularFormat(format):
+		return hashes, checkColumns()
+	}
+	return hashes, nil
+}
+
+// unionHashes returns every hash in any of the lists which are the hashes that
+// need to be calculated when reading the files for all of the outputs
+func unionHashes(lists ...[]string) []string {
+	var union []string
+	for _, hashes := range lists {
+		for _, h := range hashes {
+			if !contains(union, h) {
+				union = append(union, h)
+			}
+		}
+	}
+	return union
+}
+
+// startOutputSinks copies every result for the sinks as the files are read,
+// returning the channel for the main output and a function which writes each
+// of the sinks once the main output is done reporting if they all worked. The
+// sinks are written one at a time as the formatters use the hashes in Hash,
+// which is set to the hashes of each sink while it is written.
+func startOutputSinks(sinks []outputSink, input chan Result) (chan Result, func() bool) {
+	if len(sinks) == 0 {
+		return input, func() bool { return true }
+	}
+
+	main := make(chan Result, FileListQueueSize)
+	var results []Result
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for res := range input {
+			main <- res
+			results = append(results, res)
+		}
+		close(main)
+	}()
+
+	return main, func() bool {
+		// the main output may have stopped early so drain it for the copies to finish
+		for range main {
+		}
+		wg.Wait()
+		defer func(hashes []string) { Hash = hashes }(Hash)
+
+		valid := true
+		for _, s := range sinks {
+			Hash = s.hashes
+			queue := make(chan Result, FileListQueueSize)
+			go func() {
+				for _, res := range results {
+					queue <- res
+				}
+				close(queue)
+			}()
+			result, ok := formatResults(s.format, s.path, queue)
+			// a sink which gave up early must not block the others
+			for range queue {
+			}
+			if !ok {
+				valid = false
+				continue
+			}
+
+			// sqlite writes to the database as it goes
+			if s.format != "sqlite" {
+				if err := os.WriteFile(s.path, []byte(result), 0600); err != nil {
+					printError(fmt.Sprintf("unable to write %s: %s", s.path, err.Error()))
+					valid = false
+					continue
+				}
+			}
+			printVerbose(fmt.Sprintf("%s results written to %s", s.format, s.path))
+		}
+		return valid
+	}
+}
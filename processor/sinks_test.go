@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputSinks(t *testing.T) {
+	sinks, err := parseOutputSinks([]string{"JSON=out.json", "sum=dir/SHA256SUMS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sinks) != 2 || sinks[0].format != "json" || sinks[0].path != "out.json" || sinks[1].path != "dir/SHA256SUMS" {
+		t.Errorf("unexpected sinks %v", sinks)
+	}
+
+	for _, v := range []string{"json", "=out.json", "json=", "nope=out", "json=a", "json=./a"} {
+		if _, err := parseOutputSinks([]string{"sum=a", v}); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+
+	// the main sqlite output is written to hashit.db when no output is set
+	Format = "sqlite"
+	t.Cleanup(func() { Format = "" })
+	if _, err := parseOutputSinks([]string{"sqlite=hashit.db"}); err == nil {
+		t.Error("expected error for the default sqlite database")
+	}
+}
+
+func TestStartOutputSinks(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	NoStream = true
+	defer func() { NoStream = false }()
+
+	dir := t.TempDir()
+	sinks := []outputSink{
+		{format: "sum", path: filepath.Join(dir, "out.sum")},
+		{format: "hashdeep", path: filepath.Join(dir, "out.hashdeep")},
+	}
+	for i := range sinks {
+		sinks[i].hashes, _ = sinkHashes(sinks[i].format, Hash)
+	}
+
+	input := make(chan Result, 2)
+	input <- Result{File: "a.txt", Bytes: 1, MD5: "0cc175b9c0f1b6a831c399e269772661", SHA256: "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"}
+	input <- Result{File: "b.txt", Bytes: 1, MD5: "92eb5ffee6ae2fec3ad71c777531578f", SHA256: "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"}
+	close(input)
+
+	main, done := startOutputSinks(sinks, input)
+	out, _ := formatResults("sum", "", main)
+	if !done() {
+		t.Fatal("expected sinks to succeed")
+	}
+	// the md5 needed by hashdeep is never written to the other outputs
+	if strings.Count(out, "\n") != 2 || strings.Contains(out, "0cc175b9c0f1b6a831c399e269772661") {
+		t.Errorf("expected only sha256 in the main output got %q", out)
+	}
+	if len(Hash) != 1 || Hash[0] != "sha256" {
+		t.Errorf("expected the hashes to be restored got %v", Hash)
+	}
+
+	sum, _ := os.ReadFile(sinks[0].path)
+	if strings.Count(string(sum), "\n") != 2 || !strings.Contains(string(sum), "a.txt") || !strings.Contains(string(sum), "b.txt") {
+		t.Errorf("sum output unexpected %q", sum)
+	}
+	hashdeep, _ := os.ReadFile(sinks[1].path)
+	if !strings.HasPrefix(string(hashdeep), "%%%% HASHDEEP-1.0") || !strings.Contains(string(hashdeep), "92eb5ffee6ae2fec3ad71c777531578f") {
+		t.Errorf("hashdeep output unexpected %q", hashdeep)
+	}
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// sriHashes are the hashes Subresource Integrity supports, with sha384 used
+// when none of them have been selected
+var sriHashes = []string{"sha256", "sha384", "sha512"}
+
+// toSRI writes the integrity string for each file as used by the integrity
+// attribute of script and link tags, where every selected hash is included
+// separated by spaces. SRI is always base64 whatever the encoding.
+func toSRI(input chan Result, output string) string {
+	var str strings.Builder
+
+	for res := range input {
+		str.WriteString(fmt.Sprintf("%s  %s\n", resultSRI(res), res.File))
+
+		if !NoStream && output == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	return str.String()
+}
+
+func resultSRI(res Result) string {
+	var integrity []string
+	for _, h := range sriHashes {
+		if hasHash(h) {
+			integrity = append(integrity, h+"-"+base64.StdEncoding.EncodeToString(decodeEncoded(res.Digest(h))))
+		}
+	}
+	return strings.Join(integrity, " ")
+}
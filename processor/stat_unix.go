@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package processor
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the numeric user and group which own the file
+func fileOwner(fi os.FileInfo) (int, int, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
+
+// fileInode returns the inode, number of links and number of 512 byte blocks of the file
+func fileInode(fi os.FileInfo) (uint64, uint64, int64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return uint64(st.Ino), uint64(st.Nlink), int64(st.Blocks), true
+}
@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import "os"
+
+// fileOwner returns false as files on Windows have no numeric owner
+func fileOwner(fi os.FileInfo) (int, int, bool) {
+	return 0, 0, false
+}
+
+// fileInode returns false as os.FileInfo on Windows has no inode
+func fileInode(fi os.FileInfo) (uint64, uint64, int64, bool) {
+	return 0, 0, 0, false
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Store copies or hardlinks every file into a content addressed layout below
+// StoreInto such as sha256/ab/cdef..., so identical files are only stored once,
+// writing the results as the manifest of which digest each file has
+func Store(paths []string) {
+	if err := prepareHashes(); err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	// the digest is used as the name of the file so must be hex
+	Encoding = "hex"
+	if len(Hash) == 0 || Hash[0] == "all" || textHashes[Hash[0]] {
+		printError("the first hash is used to name the stored files so must be a hash such as sha256")
+		os.Exit(1)
+	}
+	hashes, err := sinkHashes(strings.ToLower(Format), Hash)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	Hash = hashes
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	store, err := filepath.Abs(StoreInto)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(filepath.Join(store, Hash[0]), 0755); err != nil {
+		printError(fmt.Sprintf("unable to create store %s: %s", StoreInto, err.Error()))
+		os.Exit(1)
+	}
+
+	files := make(chan string, FileListQueueSize)
+	go func() {
+		for _, p := range paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				printError(fmt.Sprintf("file or directory issue: %s %s", p, err.Error()))
+				continue
+			}
+			if !fi.IsDir() {
+				files <- p
+				continue
+			}
+
+			found := make(chan string, FileListQueueSize)
+			go func() {
+				walkDirectoryWithIgnore(p, found)
+				close(found)
+			}()
+			for f := range found {
+				// the store may be inside the directory but is never stored in itself
+				if abs, err := filepath.Abs(f); err == nil && strings.HasPrefix(abs, store+string(filepath.Separator)) {
+					continue
+				}
+				files <- f
+			}
+		}
+		close(files)
+	}()
+
+	results := make(chan Result, FileListQueueSize)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored, duplicates, failed := 0, 0, 0
+	var saved int64
+	for i := 0; i < NoThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				res, added, err := storeFile(store, f)
+				mu.Lock()
+				switch {
+				case err != nil:
+					failed++
+				case added:
+					stored++
+				default:
+					duplicates++
+					saved += res.Bytes
+				}
+				mu.Unlock()
+				if err != nil {
+					printError(err.Error())
+					continue
+				}
+				results <- res
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if strings.ToLower(Format) == "sqlite" && FileOutput == "" {
+		FileOutput = "hashit.db"
+	}
+	result, valid := formatResults(Format, FileOutput, results)
+	if FileOutput == "" {
+		fmt.Print(result)
+	} else {
+		if strings.ToLower(Format) != "sqlite" {
+			_ = os.WriteFile(FileOutput, []byte(result), 0600)
+		}
+		fmt.Println("results written to " + FileOutput)
+	}
+	printVerbose(fmt.Sprintf("stored %d files and %d duplicates saving %d bytes", stored, duplicates, saved))
+
+	if failed != 0 {
+		printError(fmt.Sprintf("%d files failed to store", failed))
+		os.Exit(1)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// storePath returns where content with the digest is kept in the store
+func storePath(store string, hash string, digest string) string {
+	if len(digest) < 3 {
+		return filepath.Join(store, hash, digest)
+	}
+	return filepath.Join(store, hash, digest[:2], digest[2:])
+}
+
+// storeFile adds the file to the store returning false if the content was
+// already there. Copies are written to a temporary file while being hashed and
+// then renamed into place, while links are hashed first as the file is not copied.
+func storeFile(store string, path string) (Result, bool, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return Result{}, false, err
+	}
+	defer in.Close()
+
+	var res Result
+	var tmp *os.File
+	if StoreLink {
+		res, err = processReader(path, in)
+	} else {
+		tmp, err = os.CreateTemp(filepath.Join(store, Hash[0]), ".tmp-")
+		if err != nil {
+			return Result{}, false, err
+		}
+		defer os.Remove(tmp.Name())
+		res, err = processReader(path, io.TeeReader(in, tmp))
+		if err == nil {
+			err = tmp.Sync()
+		}
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return Result{}, false, fmt.Errorf("unable to store %s: %s", path, err.Error())
+	}
+
+	target := storePath(store, Hash[0], res.Digest(Hash[0]))
+	if _, err := os.Stat(target); err == nil {
+		return res, false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return Result{}, false, err
+	}
+
+	if StoreLink {
+		err = os.Link(path, target)
+		if errors.Is(err, os.ErrExist) {
+			return res, false, nil
+		}
+	} else {
+		// stored content never changes so is read only
+		_ = os.Chmod(tmp.Name(), 0444)
+		err = os.Rename(tmp.Name(), target)
+	}
+	if err != nil {
+		return Result{}, false, fmt.Errorf("unable to store %s: %s", path, err.Error())
+	}
+	return res, true, nil
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreFile(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"sha256"}
+	Encoding = "hex"
+	defer func() { StoreLink = false }()
+
+	dir := t.TempDir()
+	store := filepath.Join(dir, "store")
+	_ = os.MkdirAll(filepath.Join(store, "sha256"), 0755)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("hello world"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := filepath.Join(store, "sha256", "b9", "4d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")
+	for _, link := range []bool{false, true} {
+		StoreLink = link
+		_ = os.RemoveAll(filepath.Join(store, "sha256", "b9"))
+
+		res, added, err := storeFile(store, filepath.Join(dir, "a.txt"))
+		if err != nil || !added {
+			t.Fatalf("expected a.txt to be added %v %v", added, err)
+		}
+		if res.File != filepath.Join(dir, "a.txt") {
+			t.Errorf("expected the manifest to hold the original path got %s", res.File)
+		}
+		if b, err := os.ReadFile(expected); err != nil || string(b) != "hello world" {
+			t.Errorf("expected content at %s got %q %v", expected, b, err)
+		}
+
+		_, added, err = storeFile(store, filepath.Join(dir, "b.txt"))
+		if err != nil || added {
+			t.Errorf("expected b.txt to be a duplicate %v %v", added, err)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(store, "sha256"))
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files left in the store got %d entries", len(entries))
+	}
+}
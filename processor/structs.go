@@ -6,22 +6,213 @@ import "time"
 
 // Holds the result after processing the hashes for the file
 type Result struct {
-	File       string
-	CRC32      string `json:",omitempty"`
-	XxHash64   string `json:",omitempty"`
-	MD4        string `json:",omitempty"`
-	MD5        string `json:",omitempty"`
-	SHA1       string `json:",omitempty"`
-	SHA256     string `json:",omitempty"`
-	SHA512     string `json:",omitempty"`
-	Blake2b256 string `json:",omitempty"`
-	Blake2b512 string `json:",omitempty"`
-	Blake3     string `json:",omitempty"`
-	Sha3224    string `json:",omitempty"`
-	Sha3256    string `json:",omitempty"`
-	Sha3384    string `json:",omitempty"`
-	Sha3512    string `json:",omitempty"`
-	Ed2k       string `json:"ed2k,omitempty"`
-	Bytes      int64
-	MTime      *time.Time `json:",omitzero"`
+	File        string
+	CRC32       string `json:",omitempty"`
+	XxHash64    string `json:",omitempty"`
+	CRC32C      string `json:",omitempty"`
+	CRC64ECMA   string `json:",omitempty"`
+	CRC64ISO    string `json:",omitempty"`
+	Adler32     string `json:",omitempty"`
+	XXH3        string `json:",omitempty"`
+	XXH128      string `json:",omitempty"`
+	Murmur3     string `json:",omitempty"`
+	SipHash     string `json:",omitempty"`
+	HighwayHash string `json:",omitempty"`
+	MD4         string `json:",omitempty"`
+	MD5         string `json:",omitempty"`
+	SHA1        string `json:",omitempty"`
+	SHA256      string `json:",omitempty"`
+	SHA384      string `json:",omitempty"`
+	SHA512      string `json:",omitempty"`
+	Blake2b256  string `json:",omitempty"`
+	Blake2b512  string `json:",omitempty"`
+	Blake3      string `json:",omitempty"`
+	Sha3224     string `json:",omitempty"`
+	Sha3256     string `json:",omitempty"`
+	Sha3384     string `json:",omitempty"`
+	Sha3512     string `json:",omitempty"`
+	Shake128    string `json:",omitempty"`
+	Shake256    string `json:",omitempty"`
+	CShake128   string `json:",omitempty"`
+	CShake256   string `json:",omitempty"`
+	Ed2k        string `json:"ed2k,omitempty"`
+	AICH        string `json:",omitempty"`
+	TTH         string `json:",omitempty"`
+	CIDv0       string `json:",omitempty"`
+	CIDv1       string `json:",omitempty"`
+	GitSHA1     string `json:",omitempty"`
+	GitSHA256   string `json:",omitempty"`
+	SSDeep      string `json:",omitempty"`
+	TLSH        string `json:",omitempty"`
+	Bytes       int64
+	MTime       *time.Time `json:",omitzero"`
+	Blocks      []Block    `json:",omitempty"`
+}
+
+// Digest returns the digest for the supplied hash name, or an empty string if
+// the name is not one we know about. Because HashNames is itself a Result
+// calling HashNames.Digest(name) == name checks if a name is supported.
+func (r Result) Digest(name string) string {
+	switch name {
+	case HashNames.CRC32:
+		return r.CRC32
+	case HashNames.XxHash64:
+		return r.XxHash64
+	case HashNames.CRC32C:
+		return r.CRC32C
+	case HashNames.CRC64ECMA:
+		return r.CRC64ECMA
+	case HashNames.CRC64ISO:
+		return r.CRC64ISO
+	case HashNames.Adler32:
+		return r.Adler32
+	case HashNames.XXH3:
+		return r.XXH3
+	case HashNames.XXH128:
+		return r.XXH128
+	case HashNames.Murmur3:
+		return r.Murmur3
+	case HashNames.SipHash:
+		return r.SipHash
+	case HashNames.HighwayHash:
+		return r.HighwayHash
+	case HashNames.MD4:
+		return r.MD4
+	case HashNames.MD5:
+		return r.MD5
+	case HashNames.SHA1:
+		return r.SHA1
+	case HashNames.SHA256:
+		return r.SHA256
+	case HashNames.SHA384:
+		return r.SHA384
+	case HashNames.SHA512:
+		return r.SHA512
+	case HashNames.Blake2b256:
+		return r.Blake2b256
+	case HashNames.Blake2b512:
+		return r.Blake2b512
+	case HashNames.Blake3:
+		return r.Blake3
+	case HashNames.Sha3224:
+		return r.Sha3224
+	case HashNames.Sha3256:
+		return r.Sha3256
+	case HashNames.Sha3384:
+		return r.Sha3384
+	case HashNames.Sha3512:
+		return r.Sha3512
+	case HashNames.Shake128:
+		return r.Shake128
+	case HashNames.Shake256:
+		return r.Shake256
+	case HashNames.CShake128:
+		return r.CShake128
+	case HashNames.CShake256:
+		return r.CShake256
+	case HashNames.Ed2k:
+		return r.Ed2k
+	case HashNames.AICH:
+		return r.AICH
+	case HashNames.TTH:
+		return r.TTH
+	case HashNames.CIDv0:
+		return r.CIDv0
+	case HashNames.CIDv1:
+		return r.CIDv1
+	case HashNames.GitSHA1:
+		return r.GitSHA1
+	case HashNames.GitSHA256:
+		return r.GitSHA256
+	case HashNames.SSDeep:
+		return r.SSDeep
+	case HashNames.TLSH:
+		return r.TLSH
+	}
+
+	return ""
+}
+
+// setDigest sets the digest for the supplied hash name, returning false if the
+// name is not one we know about
+func (r *Result) setDigest(name string, digest string) bool {
+	switch name {
+	case HashNames.CRC32:
+		r.CRC32 = digest
+	case HashNames.XxHash64:
+		r.XxHash64 = digest
+	case HashNames.CRC32C:
+		r.CRC32C = digest
+	case HashNames.CRC64ECMA:
+		r.CRC64ECMA = digest
+	case HashNames.CRC64ISO:
+		r.CRC64ISO = digest
+	case HashNames.Adler32:
+		r.Adler32 = digest
+	case HashNames.XXH3:
+		r.XXH3 = digest
+	case HashNames.XXH128:
+		r.XXH128 = digest
+	case HashNames.Murmur3:
+		r.Murmur3 = digest
+	case HashNames.SipHash:
+		r.SipHash = digest
+	case HashNames.HighwayHash:
+		r.HighwayHash = digest
+	case HashNames.MD4:
+		r.MD4 = digest
+	case HashNames.MD5:
+		r.MD5 = digest
+	case HashNames.SHA1:
+		r.SHA1 = digest
+	case HashNames.SHA256:
+		r.SHA256 = digest
+	case HashNames.SHA384:
+		r.SHA384 = digest
+	case HashNames.SHA512:
+		r.SHA512 = digest
+	case HashNames.Blake2b256:
+		r.Blake2b256 = digest
+	case HashNames.Blake2b512:
+		r.Blake2b512 = digest
+	case HashNames.Blake3:
+		r.Blake3 = digest
+	case HashNames.Sha3224:
+		r.Sha3224 = digest
+	case HashNames.Sha3256:
+		r.Sha3256 = digest
+	case HashNames.Sha3384:
+		r.Sha3384 = digest
+	case HashNames.Sha3512:
+		r.Sha3512 = digest
+	case HashNames.Shake128:
+		r.Shake128 = digest
+	case HashNames.Shake256:
+		r.Shake256 = digest
+	case HashNames.CShake128:
+		r.CShake128 = digest
+	case HashNames.CShake256:
+		r.CShake256 = digest
+	case HashNames.Ed2k:
+		r.Ed2k = digest
+	case HashNames.AICH:
+		r.AICH = digest
+	case HashNames.TTH:
+		r.TTH = digest
+	case HashNames.CIDv0:
+		r.CIDv0 = digest
+	case HashNames.CIDv1:
+		r.CIDv1 = digest
+	case HashNames.GitSHA1:
+		r.GitSHA1 = digest
+	case HashNames.GitSHA256:
+		r.GitSHA256 = digest
+	case HashNames.SSDeep:
+		r.SSDeep = digest
+	case HashNames.TLSH:
+		r.TLSH = digest
+	default:
+		return false
+	}
+	return true
 }
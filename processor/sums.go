@@ -0,0 +1,325 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sumsFiles are the checksum files which can be written into each directory,
+// where those starting with a dot are named after the directory such as photos.sfv
+var sumsFiles = []struct {
+	name string
+	hash string
+}{
+	{"SHA256SUMS", "sha256"},
+	{"SHA1SUMS", "sha1"},
+	{"SHA384SUMS", "sha384"},
+	{"SHA512SUMS", "sha512"},
+	{"MD5SUMS", "md5"},
+	{"B2SUMS", "blake2b512"},
+	{".sfv", "crc32"},
+	{".md5", "md5"},
+}
+
+// sumsHash returns the hash used by the kind of checksum file, or an empty string if it is not one
+func sumsHash(kind string) string {
+	for _, s := range sumsFiles {
+		if strings.EqualFold(s.name, kind) || strings.EqualFold(s.name, "."+kind) {
+			return s.hash
+		}
+	}
+	return ""
+}
+
+// sumsKind returns the kind of checksum file from its name, or an empty string if it is not one
+func sumsKind(name string) string {
+	for _, s := range sumsFiles {
+		if strings.HasPrefix(s.name, ".") {
+			if strings.HasSuffix(name, s.name) && len(name) > len(s.name) {
+				return s.name
+			}
+		} else if name == s.name {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// sumsFileName returns the name of the checksum file for the directory
+func sumsFileName(kind string, dir string) string {
+	for _, s := range sumsFiles {
+		if strings.EqualFold(s.name, kind) || strings.EqualFold(s.name, "."+kind) {
+			kind = s.name
+		}
+	}
+	if !strings.HasPrefix(kind, ".") {
+		return kind
+	}
+
+	name := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	return name + kind
+}
+
+// writeSums writes a checksum file into every directory holding the files
+// which were hashed, listing only the files in that directory by name
+func writeSums(input chan Result) (string, bool) {
+	dirs := map[string][]Result{}
+	for res := range input {
+		// checksum files list other files so are not included themselves
+		if sumsKind(filepath.Base(res.File)) != "" {
+			continue
+		}
+		dir := filepath.Dir(res.File)
+		dirs[dir] = append(dirs[dir], res)
+	}
+
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	var str strings.Builder
+	valid := true
+	hash := sumsHash(WriteSums)
+	for _, dir := range names {
+		results := dirs[dir]
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].File < results[j].File
+		})
+
+		var sums strings.Builder
+		sfv := strings.HasSuffix(strings.ToLower(WriteSums), "sfv")
+		if sfv {
+			sums.WriteString(fmt.Sprintf("; Generated by hashit %s\n", Version))
+		}
+		count := 0
+		for _, res := range results {
+			name := filepath.Base(res.File)
+			if sfv && strings.ContainsAny(name, "\n\r") {
+				// sfv has no way to escape a name so it would be split across lines
+				printError(fmt.Sprintf("unable to write %q to an sfv file as the name contains a newline", res.File))
+				valid = false
+				continue
+			}
+			count++
+			if sfv {
+				sums.WriteString(fmt.Sprintf("%s %s\n", name, strings.ToUpper(res.Digest(hash))))
+			} else {
+				sums.WriteString(sumLine(res.Digest(hash), name))
+			}
+		}
+
+		file := filepath.Join(dir, sumsFileName(WriteSums, dir))
+		if err := os.WriteFile(file, []byte(sums.String()), 0644); err != nil {
+			printError(fmt.Sprintf("unable to write %s: %s", file, err.Error()))
+			return "", false
+		}
+		str.WriteString(fmt.Sprintf("%s: %d files\n", file, count))
+	}
+
+	return str.String(), valid
+}
+
+// sumLine writes the digest and name the same way as sha256sum, where names
+// with a newline or backslash are escaped and the line starts with a backslash
+func sumLine(digest string, name string) string {
+	if strings.ContainsAny(name, "\\\n\r") {
+		r := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+		return "\\" + digest + "  " + r.Replace(name) + "\n"
+	}
+	return digest + "  " + name + "\n"
+}
+
+// parseSumLine reads a line written by sha256sum and similar tools, or by
+// sha256sum --tag or cksum in the BSD form of HASH (name) = digest, returning
+// the digest, name and the hash name when the BSD form is used
+func parseSumLine(line string) (string, string, string, bool) {
+	escaped := strings.HasPrefix(line, "\\")
+	if escaped {
+		line = line[1:]
+	}
+	unescape := func(s string) string {
+		if !escaped {
+			return s
+		}
+		return strings.NewReplacer("\\\\", "\\", "\\n", "\n", "\\r", "\r").Replace(s)
+	}
+
+	// BSD form such as SHA256 (file.txt) = abc
+	if i := strings.Index(line, " ("); i != -1 && !strings.Contains(line[:i], " ") {
+		if j := strings.LastIndex(line, ") = "); j > i {
+			hash := strings.ToLower(strings.ReplaceAll(line[:i], "-", ""))
+			return line[j+4:], unescape(line[i+2 : j]), hash, true
+		}
+	}
+
+	digest, name, ok := strings.Cut(line, " ")
+	if !ok || digest == "" {
+		return "", "", "", false
+	}
+	// the second character is a space for text mode or * for binary mode
+	if strings.HasPrefix(name, " ") || strings.HasPrefix(name, "*") {
+		name = name[1:]
+	}
+	return digest, unescape(name), "", true
+}
+
+// sumsEntry is a single file listed in a checksum file
+type sumsEntry struct {
+	sums   string // checksum file listing the file
+	file   string // path of the file
+	hash   string
+	digest string
+}
+
+// parseSumsFile reads the entries of a checksum file, which are relative to the directory it is in
+func parseSumsFile(path string) ([]sumsEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash := sumsHash(sumsKind(filepath.Base(path)))
+	dir := filepath.Dir(path)
+	var entries []sumsEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var digest, name string
+		if hash == HashNames.CRC32 {
+			// sfv lines are the name followed by the CRC32 where the name may contain spaces
+			i := strings.LastIndex(line, " ")
+			if i == -1 {
+				return nil, fmt.Errorf("invalid line %q", line)
+			}
+			name, digest = line[:i], line[i+1:]
+		} else {
+			var ok bool
+			digest, name, _, ok = parseSumLine(line)
+			if !ok {
+				return nil, fmt.Errorf("invalid line %q", line)
+			}
+		}
+
+		entries = append(entries, sumsEntry{
+			sums:   path,
+			file:   filepath.Join(dir, filepath.FromSlash(name)),
+			hash:   hash,
+			digest: strings.ToLower(digest),
+		})
+	}
+
+	return entries, scanner.Err()
+}
+
+// verifySums finds every checksum file below the paths and checks the files
+// each lists in the same way as sha256sum -c
+func verifySums(paths []string) (string, bool) {
+	found := make(chan string, FileListQueueSize)
+	go func() {
+		for _, p := range paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				printError(fmt.Sprintf("file or directory issue: %s %s", p, err.Error()))
+				continue
+			}
+			if !fi.IsDir() {
+				found <- p
+				continue
+			}
+			walkDirectoryWithIgnore(p, found)
+		}
+		close(found)
+	}()
+
+	var entries []sumsEntry
+	var sumsPaths []string
+	for f := range found {
+		if sumsKind(filepath.Base(f)) != "" {
+			sumsPaths = append(sumsPaths, f)
+		}
+	}
+	sort.Strings(sumsPaths)
+
+	var str strings.Builder
+	valid := true
+	for _, p := range sumsPaths {
+		e, err := parseSumsFile(p)
+		if err != nil {
+			str.WriteString(fmt.Sprintf("%s: unable to read: %s\n", p, err.Error()))
+			valid = false
+			continue
+		}
+		entries = append(entries, e...)
+	}
+
+	if len(sumsPaths) == 0 {
+		printError("no checksum files found")
+		return "", false
+	}
+
+	// hash every file which exists using all of the hashes needed
+	Hash = []string{}
+	Encoding = "hex"
+	files := make(chan string, len(entries))
+	queued := map[string]bool{}
+	for _, e := range entries {
+		if !contains(Hash, e.hash) {
+			Hash = append(Hash, e.hash)
+		}
+		if _, err := os.Stat(e.file); err == nil && !queued[e.file] {
+			queued[e.file] = true
+			files <- e.file
+		}
+	}
+	close(files)
+
+	results, err := hashFiles(files)
+	if err != nil {
+		printError(err.Error())
+		valid = false
+	}
+	digests := map[string]Result{}
+	for _, res := range results {
+		digests[res.File] = res
+	}
+
+	failed := 0
+	missing := 0
+	for _, e := range entries {
+		res, ok := digests[e.file]
+		switch {
+		case !ok:
+			str.WriteString(fmt.Sprintf("%s: FAILED open or read\n", e.file))
+			missing++
+		case res.Digest(e.hash) != e.digest:
+			str.WriteString(fmt.Sprintf("%s: FAILED\n", e.file))
+			failed++
+		default:
+			if Verbose {
+				str.WriteString(fmt.Sprintf("%s: OK\n", e.file))
+			}
+		}
+	}
+
+	if failed != 0 || missing != 0 {
+		valid = false
+	}
+	str.WriteString(fmt.Sprintf("%d checksum files, %d files checked, %d failed, %d missing\n", len(sumsPaths), len(entries), failed, missing))
+	return str.String(), valid
+}
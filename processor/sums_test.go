@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSumLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		digest string
+		name   string
+		hash   string
+	}{
+		{"abc  a.txt", "abc", "a.txt", ""},
+		{"abc *b c.txt", "abc", "b c.txt", ""},
+		{"SHA256 (a (1).txt) = abc", "abc", "a (1).txt", "sha256"},
+		{"SHA3-256 (a.txt) = abc", "abc", "a.txt", "sha3256"},
+		{"abc  a (1) = b.txt", "abc", "a (1) = b.txt", ""},
+		{sumLine("abc", "new\nline\\.txt")[:len(sumLine("abc", "new\nline\\.txt"))-1], "abc", "new\nline\\.txt", ""},
+	}
+
+	for _, tc := range tests {
+		digest, name, hash, ok := parseSumLine(tc.line)
+		if !ok || digest != tc.digest || name != tc.name || hash != tc.hash {
+			t.Errorf("%q expected %s %q %s got %s %q %s", tc.line, tc.digest, tc.name, tc.hash, digest, name, hash)
+		}
+	}
+}
+
+func TestSumsKind(t *testing.T) {
+	tests := map[string]string{
+		"SHA256SUMS":  "SHA256SUMS",
+		"photos.sfv":  ".sfv",
+		"photos.md5":  ".md5",
+		".sfv":        "",
+		"a.txt":       "",
+		"SHA256SUMS2": "",
+	}
+	for name, kind := range tests {
+		if got := sumsKind(name); got != kind {
+			t.Errorf("%s expected %q got %q", name, kind, got)
+		}
+	}
+
+	if got := sumsFileName("sfv", "/data/photos"); got != "photos.sfv" {
+		t.Errorf("expected photos.sfv got %s", got)
+	}
+	if got := sumsFileName("sha256sums", "/data/photos"); got != "SHA256SUMS" {
+		t.Errorf("expected SHA256SUMS got %s", got)
+	}
+}
+
+func TestWriteSumsSFVNewline(t *testing.T) {
+	WriteSums = "sfv"
+	t.Cleanup(func() { WriteSums = "" })
+
+	dir := t.TempDir()
+	input := make(chan Result, 2)
+	input <- Result{File: filepath.Join(dir, "a.txt"), CRC32: "3610a686"}
+	input <- Result{File: filepath.Join(dir, "new\nline.txt"), CRC32: "0d4a1185"}
+	close(input)
+
+	out, ok := writeSums(input)
+	if ok {
+		t.Error("expected the name with a newline to fail")
+	}
+	sfv := filepath.Join(dir, filepath.Base(dir)+".sfv")
+	if out != sfv+": 1 files\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	b, _ := os.ReadFile(sfv)
+	expected := "; Generated by hashit " + Version + "\na.txt 3610A686\n"
+	if string(b) != expected {
+		t.Errorf("expected %q got %q", expected, b)
+	}
+}
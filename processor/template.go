@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateSummary is passed to the header and footer templates, where the
+// counts are only filled in for the footer
+type templateSummary struct {
+	Version string
+	Time    time.Time
+	Hashes  []string
+	Files   int
+	Bytes   int64
+}
+
+var templateFuncs = template.FuncMap{
+	"csv":    templateCSV,
+	"json":   templateJSON,
+	"shell":  templateShell,
+	"time":   templateTime,
+	"digest": func(res Result, name string) string { return res.Digest(name) },
+}
+
+// loadTemplate parses the template from Template or the contents of
+// TemplateFile, which may define header and footer templates
+func loadTemplate() (*template.Template, error) {
+	text := Template
+	if TemplateFile != "" {
+		b, err := os.ReadFile(TemplateFile)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+
+	// each result is written on its own line unless the template has its own newline
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	return template.New("result").Funcs(templateFuncs).Parse(text)
+}
+
+// toTemplate executes the template for every result, with the header template
+// before the first result and the footer template after the last
+func toTemplate(input chan Result) (string, bool) {
+	var str strings.Builder
+	summary := templateSummary{
+		Version: Version,
+		Time:    time.Now().UTC(),
+		Hashes:  Hash,
+	}
+
+	if !templateSection(&str, "header", summary) {
+		return "", false
+	}
+
+	for res := range input {
+		if err := outputTemplate.Execute(&str, res); err != nil {
+			printError(fmt.Sprintf("unable to execute template for %s: %s", res.File, err.Error()))
+			return "", false
+		}
+		summary.Files++
+		summary.Bytes += res.Bytes
+
+		if !NoStream && FileOutput == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	if !templateSection(&str, "footer", summary) {
+		return "", false
+	}
+	return str.String(), true
+}
+
+func templateSection(str *strings.Builder, name string, summary templateSummary) bool {
+	if outputTemplate.Lookup(name) == nil {
+		return true
+	}
+	if err := outputTemplate.ExecuteTemplate(str, name, summary); err != nil {
+		printError(fmt.Sprintf("unable to execute %s template: %s", name, err.Error()))
+		return false
+	}
+	return true
+}
+
+// templateCSV quotes the value if needed to be a single CSV field
+func templateCSV(v any) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	_ = w.Write([]string{fmt.Sprint(v)})
+	w.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// templateShell single quotes the value so it is safe to use as a shell argument
+func templateShell(v any) string {
+	return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", `'\''`) + "'"
+}
+
+// templateTime formats the time using a Go layout, or unix for seconds since the epoch,
+// returning an empty string when there is no time such as without --mtime
+func templateTime(layout string, v any) string {
+	var t time.Time
+	switch x := v.(type) {
+	case time.Time:
+		t = x
+	case *time.Time:
+		if x == nil {
+			return ""
+		}
+		t = *x
+	default:
+		return ""
+	}
+
+	if layout == "unix" {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.Format(layout)
+}
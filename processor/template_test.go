@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestToTemplate(t *testing.T) {
+	Template = `{{define "header"}}file,size
+{{end}}{{define "footer"}}{{.Files}} files {{.Bytes}} bytes
+{{end}}{{csv .File}},{{.Bytes}}`
+	NoStream = true
+	defer func() {
+		Template = ""
+		NoStream = false
+		outputTemplate = nil
+	}()
+
+	var err error
+	outputTemplate, err = loadTemplate()
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+
+	input := make(chan Result, 2)
+	input <- Result{File: "a.txt", Bytes: 6}
+	input <- Result{File: "b,c.txt", Bytes: 4}
+	close(input)
+
+	out, ok := toTemplate(input)
+	expected := "file,size\na.txt,6\n\"b,c.txt\",4\n2 files 10 bytes\n"
+	if !ok || out != expected {
+		t.Errorf("expected %q got %q", expected, out)
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	if got := templateShell("it's"); got != `'it'\''s'` {
+		t.Errorf("unexpected shell quoting %s", got)
+	}
+	if got, _ := templateJSON("a\"b"); got != `"a\"b"` {
+		t.Errorf("unexpected json %s", got)
+	}
+
+	mtime := time.Unix(1700000000, 0).UTC()
+	if got := templateTime("2006-01-02", &mtime); got != "2023-11-14" {
+		t.Errorf("unexpected time %s", got)
+	}
+	if got := templateTime("unix", &mtime); got != "1700000000" {
+		t.Errorf("unexpected time %s", got)
+	}
+	var missing *time.Time
+	if got := templateTime("unix", missing); got != "" {
+		t.Errorf("expected no time got %s", got)
+	}
+}
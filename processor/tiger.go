@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+)
+
+// Tiger/192 as used by the Tiger Tree Hash, which uses the original padding
+// of 0x01 rather than the 0x80 of Tiger2
+
+const tigerSize = 24
+
+// tigerTable holds the four s-boxes one after another. They are generated at
+// start up using the method from the reference implementation rather than
+// including 8KB of constants.
+var tigerTable [4 * 256]uint64
+
+func init() {
+	// the table generation uses the compression function on the table itself
+	for i := range tigerTable {
+		for col := 0; col < 8; col++ {
+			tigerTable[i] |= uint64(i&255) << (col * 8)
+		}
+	}
+
+	var block [64]byte
+	copy(block[:], "Tiger - A Fast New Hash Function, by Ross Anderson and Eli Biham")
+	state := [3]uint64{0x0123456789ABCDEF, 0xFEDCBA9876543210, 0xF096A5B4C3B2E187}
+
+	abc := 2
+	for pass := 0; pass < 5; pass++ {
+		for i := 0; i < 256; i++ {
+			for sb := 0; sb < 1024; sb += 256 {
+				abc++
+				if abc == 3 {
+					abc = 0
+					tigerCompress(&state, block[:])
+				}
+				for col := 0; col < 8; col++ {
+					shift := col * 8
+					j := sb + int(state[abc]>>shift&0xFF)
+					a := tigerTable[sb+i] >> shift & 0xFF
+					b := tigerTable[j] >> shift & 0xFF
+					tigerTable[sb+i] = tigerTable[sb+i]&^(0xFF<<shift) | b<<shift
+					tigerTable[j] = tigerTable[j]&^(0xFF<<shift) | a<<shift
+				}
+			}
+		}
+	}
+}
+
+func tigerRound(a, b, c *uint64, x, mul uint64) {
+	*c ^= x
+	t := *c
+	*a -= tigerTable[t&0xFF] ^ tigerTable[256+(t>>16&0xFF)] ^ tigerTable[512+(t>>32&0xFF)] ^ tigerTable[768+(t>>48&0xFF)]
+	*b += tigerTable[768+(t>>8&0xFF)] ^ tigerTable[512+(t>>24&0xFF)] ^ tigerTable[256+(t>>40&0xFF)] ^ tigerTable[t>>56&0xFF]
+	*b *= mul
+}
+
+func tigerPass(a, b, c *uint64, x *[8]uint64, mul uint64) {
+	tigerRound(a, b, c, x[0], mul)
+	tigerRound(b, c, a, x[1], mul)
+	tigerRound(c, a, b, x[2], mul)
+	tigerRound(a, b, c, x[3], mul)
+	tigerRound(b, c, a, x[4], mul)
+	tigerRound(c, a, b, x[5], mul)
+	tigerRound(a, b, c, x[6], mul)
+	tigerRound(b, c, a, x[7], mul)
+}
+
+func tigerKeySchedule(x *[8]uint64) {
+	x[0] -= x[7] ^ 0xA5A5A5A5A5A5A5A5
+	x[1] ^= x[0]
+	x[2] += x[1]
+	x[3] -= x[2] ^ (^x[1] << 19)
+	x[4] ^= x[3]
+	x[5] += x[4]
+	x[6] -= x[5] ^ (^x[4] >> 23)
+	x[7] ^= x[6]
+	x[0] += x[7]
+	x[1] -= x[0] ^ (^x[7] << 19)
+	x[2] ^= x[1]
+	x[3] += x[2]
+	x[4] -= x[3] ^ (^x[2] >> 23)
+	x[5] ^= x[4]
+	x[6] += x[5]
+	x[7] -= x[6] ^ 0x0123456789ABCDEF
+}
+
+func tigerCompress(state *[3]uint64, block []byte) {
+	var x [8]uint64
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+
+	a, b, c := state[0], state[1], state[2]
+	tigerPass(&a, &b, &c, &x, 5)
+	tigerKeySchedule(&x)
+	tigerPass(&c, &a, &b, &x, 7)
+	tigerKeySchedule(&x)
+	tigerPass(&b, &c, &a, &x, 9)
+
+	state[0] = a ^ state[0]
+	state[1] = b - state[1]
+	state[2] = c + state[2]
+}
+
+// tigerSum returns the Tiger/192 digest of the supplied data
+func tigerSum(data []byte) []byte {
+	state := [3]uint64{0x0123456789ABCDEF, 0xFEDCBA9876543210, 0xF096A5B4C3B2E187}
+	length := uint64(len(data))
+
+	for len(data) >= 64 {
+		tigerCompress(&state, data[:64])
+		data = data[64:]
+	}
+
+	var block [128]byte
+	n := copy(block[:], data)
+	block[n] = 0x01
+	end := 64
+	if n >= 56 {
+		end = 128
+	}
+	binary.LittleEndian.PutUint64(block[end-8:], length*8)
+	for i := 0; i < end; i += 64 {
+		tigerCompress(&state, block[i:i+64])
+	}
+
+	out := make([]byte, tigerSize)
+	for i, s := range state {
+		binary.LittleEndian.PutUint64(out[i*8:], s)
+	}
+	return out
+}
+
+// tthState calculates the Tiger Tree Hash used by Direct Connect and Gnutella where
+// leaves are 1024 byte blocks. Only one node per level is held at a time.
+// Sum returns the hash as unpadded base32 text.
+type tthState struct {
+	block  []byte
+	stack  [][]byte // pending nodes where stack[i] covers 2^i leaves, nil if empty
+	leaves int
+}
+
+const tthBlockSize = 1024
+
+func newTTH() *tthState {
+	return &tthState{}
+}
+
+func (t *tthState) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		take := min(tthBlockSize-len(t.block), len(p))
+		t.block = append(t.block, p[:take]...)
+		p = p[take:]
+
+		if len(t.block) == tthBlockSize {
+			t.addLeaf()
+		}
+	}
+	return written, nil
+}
+
+func (t *tthState) addLeaf() {
+	node := tigerSum(append([]byte{0x00}, t.block...))
+	t.block = t.block[:0]
+	t.leaves++
+
+	for i := 0; ; i++ {
+		if i == len(t.stack) {
+			t.stack = append(t.stack, node)
+			return
+		}
+		if t.stack[i] == nil {
+			t.stack[i] = node
+			return
+		}
+		node = tthInternal(t.stack[i], node)
+		t.stack[i] = nil
+	}
+}
+
+func tthInternal(left, right []byte) []byte {
+	data := make([]byte, 0, 1+2*tigerSize)
+	data = append(data, 0x01)
+	data = append(data, left...)
+	data = append(data, right...)
+	return tigerSum(data)
+}
+
+func (t *tthState) root() []byte {
+	// an empty file is the hash of a single empty leaf
+	if t.leaves == 0 || len(t.block) != 0 {
+		c := *t
+		c.block = append([]byte{}, t.block...)
+		c.stack = append([][]byte{}, t.stack...)
+		c.addLeaf()
+		return c.fold()
+	}
+	return t.fold()
+}
+
+// fold combines the pending nodes from the smallest up, where unpaired
+// nodes are promoted to the next level unchanged
+func (t *tthState) fold() []byte {
+	var node []byte
+	for _, n := range t.stack {
+		if n == nil {
+			continue
+		}
+		if node == nil {
+			node = n
+		} else {
+			node = tthInternal(n, node)
+		}
+	}
+	return node
+}
+
+func (t *tthState) Sum(b []byte) []byte {
+	return append(b, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(t.root())...)
+}
+
+func (t *tthState) Reset() {
+	*t = tthState{}
+}
+
+func (t *tthState) Size() int {
+	return 39
+}
+
+func (t *tthState) BlockSize() int {
+	return tthBlockSize
+}
@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+)
+
+// TLSH locality sensitive hash using 128 buckets and a 1 byte checksum which is
+// the default for the reference implementation https://github.com/trendmicro/tlsh
+const (
+	tlshWindowSize    = 5
+	tlshBuckets       = 256
+	tlshEffBuckets    = 128
+	tlshCodeSize      = 32 // 128 * 2 bits / 8
+	tlshMinDataLength = 50
+	tlshNull          = "TNULL"
+)
+
+// Pearson hash table used by the reference implementation
+var tlshVTable = [256]byte{
+	1, 87, 49, 12, 176, 178, 102, 166, 121, 193, 6, 84, 249, 230, 44, 163,
+	14, 197, 213, 181, 161, 85, 218, 80, 64, 239, 24, 226, 236, 142, 38, 200,
+	110, 177, 104, 103, 141, 253, 255, 50, 77, 101, 81, 18, 45, 96, 31, 222,
+	25, 107, 190, 70, 86, 237, 240, 34, 72, 242, 20, 214, 244, 227, 149, 235,
+	97, 234, 57, 22, 60, 250, 82, 175, 208, 5, 127, 199, 111, 62, 135, 248,
+	174, 169, 211, 58, 66, 154, 106, 195, 245, 171, 17, 187, 182, 179, 0, 243,
+	132, 56, 148, 75, 128, 133, 158, 100, 130, 126, 91, 13, 153, 246, 216, 219,
+	119, 68, 223, 78, 83, 88, 201, 99, 122, 11, 92, 32, 136, 114, 52, 10,
+	138, 30, 48, 183, 156, 35, 61, 26, 143, 74, 251, 94, 129, 162, 63, 152,
+	170, 7, 115, 167, 241, 206, 3, 150, 55, 59, 151, 220, 90, 53, 23, 131,
+	125, 173, 15, 238, 79, 95, 89, 16, 105, 137, 225, 224, 217, 160, 37, 123,
+	118, 73, 2, 157, 46, 116, 9, 145, 134, 228, 207, 212, 202, 215, 69, 229,
+	27, 188, 67, 124, 168, 252, 42, 4, 29, 108, 21, 247, 19, 205, 39, 203,
+	233, 40, 186, 147, 198, 192, 155, 33, 164, 191, 98, 204, 165, 180, 117, 76,
+	140, 36, 210, 172, 41, 54, 159, 8, 185, 232, 113, 196, 231, 47, 146, 120,
+	51, 65, 28, 144, 254, 221, 93, 189, 194, 139, 112, 43, 71, 109, 184, 209,
+}
+
+// tlshState implements hash.Hash with Sum returning the hash as text
+// such as T1... or TNULL when there is not enough data to produce one
+type tlshState struct {
+	buckets  [tlshBuckets]uint32
+	window   [tlshWindowSize]byte
+	checksum byte
+	length   int
+}
+
+func newTLSH() *tlshState {
+	return &tlshState{}
+}
+
+func tlshMapping(salt, i, j, k byte) byte {
+	h := tlshVTable[salt]
+	h = tlshVTable[h^i]
+	h = tlshVTable[h^j]
+	return tlshVTable[h^k]
+}
+
+func (t *tlshState) Write(p []byte) (int, error) {
+	for _, b := range p {
+		j := t.length % tlshWindowSize
+		t.window[j] = b
+
+		if t.length >= 4 {
+			j1 := (j + 4) % tlshWindowSize
+			j2 := (j + 3) % tlshWindowSize
+			j3 := (j + 2) % tlshWindowSize
+			j4 := (j + 1) % tlshWindowSize
+			w := t.window
+
+			t.checksum = tlshMapping(0, w[j], w[j1], t.checksum)
+			t.buckets[tlshMapping(2, w[j], w[j1], w[j2])]++
+			t.buckets[tlshMapping(3, w[j], w[j1], w[j3])]++
+			t.buckets[tlshMapping(5, w[j], w[j2], w[j3])]++
+			t.buckets[tlshMapping(7, w[j], w[j2], w[j4])]++
+			t.buckets[tlshMapping(11, w[j], w[j1], w[j4])]++
+			t.buckets[tlshMapping(13, w[j], w[j3], w[j4])]++
+		}
+		t.length++
+	}
+
+	return len(p), nil
+}
+
+func (t *tlshState) Sum(b []byte) []byte {
+	return append(b, t.digest()...)
+}
+
+func (t *tlshState) Reset() {
+	*t = tlshState{}
+}
+
+func (t *tlshState) Size() int {
+	return 2 + (3+tlshCodeSize)*2
+}
+
+func (t *tlshState) BlockSize() int {
+	return 1
+}
+
+func (t *tlshState) digest() string {
+	if t.length < tlshMinDataLength {
+		return tlshNull
+	}
+
+	sorted := make([]uint32, tlshEffBuckets)
+	copy(sorted, t.buckets[:tlshEffBuckets])
+	slices.Sort(sorted)
+	q1, q2, q3 := sorted[tlshEffBuckets/4-1], sorted[tlshEffBuckets/2-1], sorted[tlshEffBuckets*3/4-1]
+	if q3 == 0 {
+		return tlshNull
+	}
+
+	// not enough variation in the input to produce anything meaningful
+	nonZero := 0
+	for _, c := range t.buckets[:tlshEffBuckets] {
+		if c > 0 {
+			nonZero++
+		}
+	}
+	if nonZero <= 4*tlshCodeSize/2 {
+		return tlshNull
+	}
+
+	code := make([]byte, tlshCodeSize)
+	for i := 0; i < tlshCodeSize; i++ {
+		var h byte
+		for j := 0; j < 4; j++ {
+			k := t.buckets[4*i+j]
+			switch {
+			case q3 < k:
+				h += 3 << (j * 2)
+			case q2 < k:
+				h += 2 << (j * 2)
+			case q1 < k:
+				h += 1 << (j * 2)
+			}
+		}
+		// the code is written out in reverse order
+		code[tlshCodeSize-1-i] = h
+	}
+
+	q1Ratio := byte((q1 * 100 / q3) % 16)
+	q2Ratio := byte((q2 * 100 / q3) % 16)
+
+	out := []byte{
+		tlshSwap(t.checksum),
+		tlshSwap(tlshLength(t.length)),
+		q1Ratio<<4 | q2Ratio,
+	}
+	out = append(out, code...)
+
+	return "T1" + strings.ToUpper(hex.EncodeToString(out))
+}
+
+func tlshSwap(b byte) byte {
+	return b<<4 | b>>4
+}
+
+// tlshLength maps the data length onto a single byte using a log scale
+func tlshLength(length int) byte {
+	l := math.Log(float64(length))
+	var i float64
+	switch {
+	case length <= 656:
+		i = math.Floor(l / 0.4054651)
+	case length <= 3199:
+		i = math.Floor(l/0.26236426 - 8.72777)
+	default:
+		i = math.Floor(l/0.095310180 - 62.5472)
+	}
+	return byte(int(i) & 0xFF)
+}
+
+// tlshDistance compares two TLSH hashes returning 0 for identical and larger
+// values the more different they are, including the length of the data
+func tlshDistance(a, b string) (int, error) {
+	x, err := tlshDecode(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := tlshDecode(b)
+	if err != nil {
+		return 0, err
+	}
+
+	diff := 0
+
+	lDiff := tlshModDiff(int(tlshSwap(x[1])), int(tlshSwap(y[1])), 256)
+	if lDiff <= 1 {
+		diff += lDiff
+	} else {
+		diff += lDiff * 12
+	}
+
+	for _, q := range []struct{ a, b int }{
+		{int(x[2] >> 4), int(y[2] >> 4)},
+		{int(x[2] & 0x0F), int(y[2] & 0x0F)},
+	} {
+		qDiff := tlshModDiff(q.a, q.b, 16)
+		if qDiff <= 1 {
+			diff += qDiff
+		} else {
+			diff += (qDiff - 1) * 12
+		}
+	}
+
+	if x[0] != y[0] {
+		diff++
+	}
+
+	for i := 3; i < len(x); i++ {
+		for j := 0; j < 4; j++ {
+			d := int(x[i]>>(j*2)&3) - int(y[i]>>(j*2)&3)
+			if d < 0 {
+				d = -d
+			}
+			// bits at opposite ends of the quartiles count for more
+			if d == 3 {
+				d = 6
+			}
+			diff += d
+		}
+	}
+
+	return diff, nil
+}
+
+func tlshDecode(input string) ([]byte, error) {
+	input = strings.TrimPrefix(strings.ToUpper(input), "T1")
+	b, err := hex.DecodeString(input)
+	if err != nil || len(b) != 3+tlshCodeSize {
+		return nil, errors.New("invalid tlsh hash")
+	}
+	return b, nil
+}
+
+func tlshModDiff(x, y, r int) int {
+	var dl, dr int
+	if y > x {
+		dl = y - x
+		dr = x + r - y
+	} else {
+		dl = x - y
+		dr = y + r - x
+	}
+	return min(dl, dr)
+}
+
+// isTLSH checks if the input looks like a TLSH hash rather than a filename
+func isTLSH(input string) bool {
+	_, err := tlshDecode(input)
+	return err == nil && strings.HasPrefix(strings.ToUpper(input), "T1")
+}
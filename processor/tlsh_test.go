@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTLSHTooShort(t *testing.T) {
+	d := newTLSH()
+	_, _ = d.Write([]byte("too short"))
+
+	if got := string(d.Sum(nil)); got != tlshNull {
+		t.Errorf("Expected %s got %s", tlshNull, got)
+	}
+}
+
+// The expected digests follow the reference implementation with its default
+// 128 buckets and 1 byte checksum, and pin the output for fixed inputs
+func TestTLSHKnownDigests(t *testing.T) {
+	var cycle []byte
+	for i := 0; i < 512; i++ {
+		cycle = append(cycle, byte(i*7%251))
+	}
+
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)), "T16811024A311C1794658A1888438D95B2D2C9C910612114116570604219482359CD8551"},
+		{cycle, "T18CF0597F006A2DF69144B08CA3E261B4FB70481AEEE87BF1029F44ACCD148EA042D21B"},
+	}
+
+	for _, c := range cases {
+		// written in pieces as files are read in chunks
+		d := newTLSH()
+		_, _ = d.Write(c.input[:7])
+		_, _ = d.Write(c.input[7:])
+		if got := string(d.Sum(nil)); got != c.expected {
+			t.Errorf("Expected %s got %s", c.expected, got)
+		}
+	}
+}
+
+func TestTLSHDistance(t *testing.T) {
+	content := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+
+	a := newTLSH()
+	_, _ = a.Write([]byte(content))
+	b := newTLSH()
+	_, _ = b.Write([]byte(strings.Replace(content, "lazy", "sleepy", 3)))
+
+	hashA := string(a.Sum(nil))
+	hashB := string(b.Sum(nil))
+
+	if !isTLSH(hashA) || len(hashA) != 72 {
+		t.Fatalf("Expected valid TLSH got %s", hashA)
+	}
+
+	if d, _ := tlshDistance(hashA, hashA); d != 0 {
+		t.Errorf("Expected 0 for identical got %d", d)
+	}
+
+	d, err := tlshDistance(hashA, hashB)
+	if err != nil || d == 0 || d > 100 {
+		t.Errorf("Expected small non zero distance got %d %v", d, err)
+	}
+}
+
+func TestLoadSimilarTargetsHash(t *testing.T) {
+	res, err := loadSimilarTargets("3:hMCE:hq")
+	if err != nil || res.SSDeep != "3:hMCE:hq" || res.TLSH != "" {
+		t.Errorf("Expected ssdeep target got %v %v", res, err)
+	}
+
+	if h := similarHashes(res); len(h) != 1 || h[0] != "ssdeep" {
+		t.Errorf("Expected only ssdeep got %v", h)
+	}
+}
@@ -0,0 +1,742 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BitTorrent v2 hashes files in 16 KiB blocks which are the leaves of a merkle tree
+const torrentBlockSize = 16 * 1024
+
+// torrentFile is a single file within a torrent
+type torrentFile struct {
+	path   []string // path within the torrent
+	source string   // location on disk
+	length int64
+	pad    bool // v1 padding file used by hybrid torrents to align files to pieces
+}
+
+// torrentPieces calculates the v1 SHA1 piece hashes over everything written
+// to it which is every file in the torrent one after another
+type torrentPieces struct {
+	pieceLength int64
+	h           hash.Hash
+	n           int64
+	pieces      []byte
+}
+
+func newTorrentPieces(pieceLength int64) *torrentPieces {
+	return &torrentPieces{pieceLength: pieceLength, h: sha1.New()}
+}
+
+func (t *torrentPieces) Write(b []byte) (int, error) {
+	written := len(b)
+	for len(b) > 0 {
+		take := min(t.pieceLength-t.n, int64(len(b)))
+		_, _ = t.h.Write(b[:take])
+		t.n += take
+		b = b[take:]
+
+		if t.n == t.pieceLength {
+			t.pieces = t.h.Sum(t.pieces)
+			t.h.Reset()
+			t.n = 0
+		}
+	}
+	return written, nil
+}
+
+// Pieces hashes anything remaining as the final piece and returns all of the hashes
+func (t *torrentPieces) Pieces() []byte {
+	if t.n != 0 {
+		t.pieces = t.h.Sum(t.pieces)
+		t.h.Reset()
+		t.n = 0
+	}
+	return t.pieces
+}
+
+// torrentMerkle calculates the v2 SHA256 merkle tree for a single file keeping only
+// the piece layer, as the tree above it is small enough to build at the end
+type torrentMerkle struct {
+	pieceLength int64
+	block       []byte
+	leaves      [][]byte
+	pieces      [][]byte
+	length      int64
+}
+
+func newTorrentMerkle(pieceLength int64) *torrentMerkle {
+	return &torrentMerkle{pieceLength: pieceLength}
+}
+
+func (m *torrentMerkle) Write(b []byte) (int, error) {
+	written := len(b)
+	m.length += int64(written)
+	for len(b) > 0 {
+		take := min(torrentBlockSize-len(m.block), len(b))
+		m.block = append(m.block, b[:take]...)
+		b = b[take:]
+
+		if len(m.block) == torrentBlockSize {
+			m.addLeaf()
+		}
+	}
+	return written, nil
+}
+
+func (m *torrentMerkle) addLeaf() {
+	sum := sha256.Sum256(m.block)
+	m.leaves = append(m.leaves, sum[:])
+	m.block = m.block[:0]
+
+	if len(m.leaves) == m.pieceLeaves() {
+		m.pieces = append(m.pieces, merkleRoot(m.leaves, m.pieceLeaves(), make([]byte, 32)))
+		m.leaves = m.leaves[:0]
+	}
+}
+
+func (m *torrentMerkle) pieceLeaves() int {
+	return int(m.pieceLength / torrentBlockSize)
+}
+
+// Root returns the pieces root and the piece layer for the file. Empty files have
+// no root and files no larger than a single piece have no piece layer.
+func (m *torrentMerkle) Root() ([]byte, [][]byte) {
+	if len(m.block) != 0 {
+		m.addLeaf()
+	}
+
+	if m.length == 0 {
+		return nil, nil
+	}
+
+	// files fitting in a single piece use a tree only as large as required
+	if m.length <= m.pieceLength {
+		if len(m.pieces) == 1 {
+			return m.pieces[0], nil
+		}
+		return merkleRoot(m.leaves, nextPowerOfTwo(len(m.leaves)), make([]byte, 32)), nil
+	}
+
+	if len(m.leaves) != 0 {
+		m.pieces = append(m.pieces, merkleRoot(m.leaves, m.pieceLeaves(), make([]byte, 32)))
+		m.leaves = m.leaves[:0]
+	}
+
+	// pieces past the end of the file are treated as though all of their leaves are zero
+	pad := merkleRoot(nil, m.pieceLeaves(), make([]byte, 32))
+	return merkleRoot(m.pieces, nextPowerOfTwo(len(m.pieces)), pad), m.pieces
+}
+
+// merkleRoot builds a SHA256 merkle tree with width nodes at the bottom
+// using pad for any missing past the end of the supplied nodes
+func merkleRoot(nodes [][]byte, width int, pad []byte) []byte {
+	layer := make([][]byte, width)
+	for i := range layer {
+		if i < len(nodes) {
+			layer[i] = nodes[i]
+		} else {
+			layer[i] = pad
+		}
+	}
+
+	for len(layer) > 1 {
+		next := make([][]byte, len(layer)/2)
+		for i := range next {
+			h := sha256.New()
+			h.Write(layer[i*2])
+			h.Write(layer[i*2+1])
+			next[i] = h.Sum(nil)
+		}
+		layer = next
+	}
+
+	return layer[0]
+}
+
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// torrentPieceLength picks a piece length giving no more than about 1500 pieces
+// keeping within the 16 KiB to 16 MiB range clients expect
+func torrentPieceLength(total int64) int64 {
+	pieceLength := int64(torrentBlockSize)
+	for total/pieceLength > 1500 && pieceLength < 16*1024*1024 {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// torrentFiles finds the files to include in the torrent for the supplied
+// file or directory using the same walker and ignore rules as hashing
+func torrentFiles(path string) (string, []torrentFile, bool, error) {
+	path = filepath.Clean(path)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, false, err
+	}
+	name := filepath.Base(abs)
+
+	if !fi.IsDir() {
+		return name, []torrentFile{{path: []string{name}, source: path, length: fi.Size()}}, true, nil
+	}
+
+	output := make(chan string, FileListQueueSize)
+	go func() {
+		walkDirectoryWithIgnore(path, output)
+		close(output)
+	}()
+
+	files := []torrentFile{}
+	for f := range output {
+		rel, err := filepath.Rel(path, f)
+		if err != nil {
+			return "", nil, false, err
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			return "", nil, false, err
+		}
+		files = append(files, torrentFile{
+			path:   strings.Split(filepath.ToSlash(rel), "/"),
+			source: f,
+			length: fi.Size(),
+		})
+	}
+
+	if len(files) == 0 {
+		return "", nil, false, errors.New("no files found")
+	}
+
+	// v2 file trees are sorted so v1 file lists use the same order to allow hybrids
+	slices.SortFunc(files, func(a, b torrentFile) int {
+		return slices.Compare(a.path, b.path)
+	})
+
+	return name, files, false, nil
+}
+
+// TorrentCreate creates a .torrent file for the supplied file or directory
+func TorrentCreate(path string) {
+	version := strings.ToLower(TorrentVersion)
+	if version != "v1" && version != "v2" && version != "hybrid" {
+		printError(fmt.Sprintf("unknown torrent version %s, must be one of v1, v2 or hybrid", TorrentVersion))
+		os.Exit(1)
+	}
+
+	name, files, single, err := torrentFiles(path)
+	if err != nil {
+		printError(fmt.Sprintf("unable to read %s: %s", path, err.Error()))
+		os.Exit(1)
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.length
+	}
+
+	pieceLength := torrentPieceLength(total)
+	if TorrentPieceLength != "" {
+		pieceLength, err = parseSize(TorrentPieceLength)
+		if err != nil {
+			printError(fmt.Sprintf("invalid piece length: %s", err.Error()))
+			os.Exit(1)
+		}
+	}
+	if pieceLength < torrentBlockSize || pieceLength&(pieceLength-1) != 0 {
+		printError("piece length must be a power of two of at least 16k")
+		os.Exit(1)
+	}
+
+	info, pieceLayers, err := torrentInfo(name, files, single, pieceLength, version)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+
+	metainfo := map[string]any{
+		"info":          info,
+		"created by":    "hashit " + Version,
+		"creation date": time.Now().Unix(),
+	}
+	if len(TorrentTrackers) != 0 {
+		metainfo["announce"] = TorrentTrackers[0]
+	}
+	if len(TorrentTrackers) > 1 {
+		tiers := []any{}
+		for _, t := range TorrentTrackers {
+			tiers = append(tiers, []any{t})
+		}
+		metainfo["announce-list"] = tiers
+	}
+	if TorrentComment != "" {
+		metainfo["comment"] = TorrentComment
+	}
+	if version != "v1" {
+		metainfo["piece layers"] = pieceLayers
+	}
+
+	encoded, err := bencode(metainfo)
+	if err != nil {
+		printError(err.Error())
+		os.Exit(1)
+	}
+	encodedInfo, _ := bencode(info)
+
+	output := FileOutput
+	if output == "" {
+		output = name + ".torrent"
+	}
+	err = os.WriteFile(output, encoded, 0644)
+	if err != nil {
+		printError(fmt.Sprintf("unable to write %s: %s", output, err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println("torrent written to " + output)
+	if version != "v2" {
+		fmt.Printf("  infohash v1 %x\n", sha1.Sum(encodedInfo))
+	}
+	if version != "v1" {
+		fmt.Printf("  infohash v2 %x\n", sha256.Sum256(encodedInfo))
+	}
+}
+
+// torrentInfo reads every file once calculating whichever of the v1 pieces
+// and v2 merkle trees are required and builds the info dictionary from them
+func torrentInfo(name string, files []torrentFile, single bool, pieceLength int64, version string) (map[string]any, map[string]any, error) {
+	v1 := version != "v2"
+	v2 := version != "v1"
+
+	pieces := newTorrentPieces(pieceLength)
+	pieceLayers := map[string]any{}
+	fileTree := map[string]any{}
+	fileList := []any{}
+	data := make([]byte, 4_194_304)
+
+	for i, f := range files {
+		merkle := newTorrentMerkle(pieceLength)
+
+		var writers []io.Writer
+		if v1 {
+			writers = append(writers, pieces)
+		}
+		if v2 {
+			writers = append(writers, merkle)
+		}
+
+		file, err := os.Open(f.source)
+		if err != nil {
+			return nil, nil, err
+		}
+		n, err := io.CopyBuffer(io.MultiWriter(writers...), file, data)
+		_ = file.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if n != f.length {
+			return nil, nil, fmt.Errorf("%s changed size while being read", f.source)
+		}
+
+		if v1 {
+			fileList = append(fileList, map[string]any{"length": f.length, "path": torrentPath(f.path)})
+
+			// hybrids align each file to a piece so the v1 and v2 pieces are the same
+			if v2 && i != len(files)-1 && f.length%pieceLength != 0 {
+				padLength := pieceLength - f.length%pieceLength
+				_, _ = pieces.Write(make([]byte, padLength))
+				fileList = append(fileList, map[string]any{
+					"attr":   "p",
+					"length": padLength,
+					"path":   []any{".pad", strconv.FormatInt(padLength, 10)},
+				})
+			}
+		}
+
+		if v2 {
+			root, layer := merkle.Root()
+			leaf := map[string]any{"length": f.length}
+			if root != nil {
+				leaf["pieces root"] = root
+			}
+			if layer != nil {
+				pieceLayers[string(root)] = bytes.Join(layer, nil)
+			}
+
+			node := fileTree
+			for _, p := range f.path {
+				next, ok := node[p].(map[string]any)
+				if !ok {
+					next = map[string]any{}
+					node[p] = next
+				}
+				node = next
+			}
+			node[""] = leaf
+		}
+	}
+
+	info := map[string]any{
+		"name":         name,
+		"piece length": pieceLength,
+	}
+	if TorrentPrivate {
+		info["private"] = 1
+	}
+
+	if v1 {
+		info["pieces"] = pieces.Pieces()
+		if single {
+			info["length"] = files[0].length
+		} else {
+			info["files"] = fileList
+		}
+	}
+	if v2 {
+		info["meta version"] = 2
+		info["file tree"] = fileTree
+	}
+
+	return info, pieceLayers, nil
+}
+
+func torrentPath(path []string) []any {
+	p := make([]any, 0, len(path))
+	for _, x := range path {
+		p = append(p, x)
+	}
+	return p
+}
+
+// torrentMeta is the parts of a parsed .torrent file needed to verify it
+type torrentMeta struct {
+	name        string
+	pieceLength int64
+	single      bool
+	files       []torrentFile
+	pieces      []byte            // v1 SHA1 piece hashes
+	roots       map[string][]byte // v2 pieces root for each file keyed by path
+	pieceLayers map[string]string // v2 piece layers keyed by pieces root
+	v2          bool
+}
+
+func loadTorrent(torrent string) (torrentMeta, error) {
+	meta := torrentMeta{roots: map[string][]byte{}, pieceLayers: map[string]string{}}
+
+	data, err := os.ReadFile(torrent)
+	if err != nil {
+		return meta, err
+	}
+	decoded, err := bdecode(data)
+	if err != nil {
+		return meta, err
+	}
+
+	root, _ := decoded.(map[string]any)
+	info, ok := root["info"].(map[string]any)
+	if !ok {
+		return meta, errors.New("missing info dictionary")
+	}
+
+	meta.name, _ = info["name"].(string)
+	meta.pieceLength, _ = info["piece length"].(int64)
+	if !torrentSafePath(meta.name) || meta.pieceLength <= 0 {
+		return meta, errors.New("invalid name or piece length")
+	}
+
+	if version, _ := info["meta version"].(int64); version == 2 {
+		meta.v2 = true
+		tree, ok := info["file tree"].(map[string]any)
+		if !ok {
+			return meta, errors.New("missing file tree")
+		}
+		err = meta.walkFileTree(tree, nil)
+		if err != nil {
+			return meta, err
+		}
+		layers, _ := root["piece layers"].(map[string]any)
+		for k, v := range layers {
+			meta.pieceLayers[k], _ = v.(string)
+		}
+
+		// a single file torrent has the name as the only entry in the file tree
+		meta.single = len(meta.files) == 1 && len(meta.files[0].path) == 1 && meta.files[0].path[0] == meta.name
+		return meta, nil
+	}
+
+	pieces, _ := info["pieces"].(string)
+	meta.pieces = []byte(pieces)
+	if len(meta.pieces)%sha1.Size != 0 {
+		return meta, errors.New("invalid pieces")
+	}
+
+	if length, ok := info["length"].(int64); ok {
+		meta.single = true
+		meta.files = []torrentFile{{path: []string{meta.name}, length: length}}
+		return meta, nil
+	}
+
+	files, _ := info["files"].([]any)
+	for _, f := range files {
+		entry, _ := f.(map[string]any)
+		length, _ := entry["length"].(int64)
+		attr, _ := entry["attr"].(string)
+		list, _ := entry["path"].([]any)
+
+		path := []string{}
+		for _, p := range list {
+			s, _ := p.(string)
+			if !torrentSafePath(s) {
+				return meta, fmt.Errorf("invalid path %v", list)
+			}
+			path = append(path, s)
+		}
+		meta.files = append(meta.files, torrentFile{path: path, length: length, pad: strings.Contains(attr, "p")})
+	}
+
+	return meta, nil
+}
+
+// walkFileTree collects the files from a v2 file tree in the order they appear
+func (meta *torrentMeta) walkFileTree(tree map[string]any, path []string) error {
+	keys := make([]string, 0, len(tree))
+	for k := range tree {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		node, _ := tree[k].(map[string]any)
+		if k == "" {
+			length, _ := node["length"].(int64)
+			root, _ := node["pieces root"].(string)
+			meta.files = append(meta.files, torrentFile{path: slices.Clone(path), length: length})
+			meta.roots[strings.Join(path, "/")] = []byte(root)
+			continue
+		}
+
+		if !torrentSafePath(k) {
+			return fmt.Errorf("invalid path %s", k)
+		}
+		err := meta.walkFileTree(node, append(path, k))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// torrentSafePath ensures a path component cannot escape the download directory
+func torrentSafePath(p string) bool {
+	return p != "" && p != "." && p != ".." && !strings.ContainsAny(p, `/\`)
+}
+
+// TorrentVerify checks the data at path against the supplied .torrent file
+// reporting any pieces that do not match and the files they belong to. Where path
+// is empty the data is expected to be in the current directory using the torrent name.
+func TorrentVerify(torrent string, path string) {
+	meta, err := loadTorrent(torrent)
+	if err != nil {
+		printError(fmt.Sprintf("unable to load torrent %s: %s", torrent, err.Error()))
+		os.Exit(1)
+	}
+
+	if path == "" {
+		path = meta.name
+	}
+	for i := range meta.files {
+		if meta.single {
+			meta.files[i].source = path
+		} else {
+			meta.files[i].source = filepath.Join(append([]string{path}, meta.files[i].path...)...)
+		}
+	}
+
+	var result string
+	var valid bool
+	if meta.v2 {
+		result, valid = torrentVerifyV2(meta)
+	} else {
+		result, valid = torrentVerifyV1(meta)
+	}
+
+	fmt.Print(result)
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// torrentRead writes the contents of the file to w returning false if it is missing
+// or the wrong size, in which case zeros are written in its place so that following
+// files still line up with their pieces
+func torrentRead(f torrentFile, w io.Writer, data []byte) bool {
+	file, err := os.Open(f.source)
+	if err != nil {
+		_, _ = io.CopyBuffer(w, io.LimitReader(zeroReader{}, f.length), data)
+		return false
+	}
+	defer file.Close()
+
+	n, _ := io.CopyBuffer(w, io.LimitReader(file, f.length), data)
+	if n != f.length {
+		_, _ = io.CopyBuffer(w, io.LimitReader(zeroReader{}, f.length-n), data)
+		return false
+	}
+
+	fi, err := file.Stat()
+	return err == nil && fi.Size() == f.length
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+func torrentVerifyV1(meta torrentMeta) (string, bool) {
+	pieces := newTorrentPieces(meta.pieceLength)
+	data := make([]byte, 4_194_304)
+
+	filesChecked := 0
+	missing := 0
+	bad := map[int]bool{}
+	for i, f := range meta.files {
+		if f.pad {
+			_, _ = io.CopyBuffer(pieces, io.LimitReader(zeroReader{}, f.length), data)
+			continue
+		}
+
+		filesChecked++
+		if _, err := os.Stat(f.source); err != nil {
+			missing++
+			if Verbose {
+				fmt.Printf("%v: File expected but not found\n", f.source)
+			}
+		}
+		if !torrentRead(f, pieces, data) {
+			bad[i] = true
+		}
+	}
+
+	computed := pieces.Pieces()
+	pieceCount := len(meta.pieces) / sha1.Size
+	badPieces := 0
+	for p := 0; p < pieceCount; p++ {
+		start := int64(p) * meta.pieceLength
+		end := start + meta.pieceLength
+
+		if p*sha1.Size+sha1.Size <= len(computed) && bytes.Equal(computed[p*sha1.Size:(p+1)*sha1.Size], meta.pieces[p*sha1.Size:(p+1)*sha1.Size]) {
+			continue
+		}
+		badPieces++
+
+		// work out which files the piece overlaps
+		affected := []string{}
+		var offset int64
+		for i, f := range meta.files {
+			if !f.pad && f.length != 0 && offset < end && offset+f.length > start {
+				affected = append(affected, f.source)
+				bad[i] = true
+			}
+			offset += f.length
+		}
+		fmt.Printf("piece %d failed: %s\n", p, strings.Join(affected, ", "))
+	}
+
+	return torrentSummary(pieceCount, badPieces, filesChecked, len(bad), missing)
+}
+
+func torrentVerifyV2(meta torrentMeta) (string, bool) {
+	data := make([]byte, 4_194_304)
+
+	pieceCount := 0
+	badPieces := 0
+	badFiles := 0
+	missing := 0
+	for _, f := range meta.files {
+		expectedRoot := meta.roots[strings.Join(f.path, "/")]
+		pieces := int((f.length + meta.pieceLength - 1) / meta.pieceLength)
+		pieceCount += pieces
+
+		if _, err := os.Stat(f.source); err != nil {
+			missing++
+			badFiles++
+			badPieces += pieces
+			if Verbose {
+				fmt.Printf("%v: File expected but not found\n", f.source)
+			}
+			continue
+		}
+
+		merkle := newTorrentMerkle(meta.pieceLength)
+		sized := torrentRead(f, merkle, data)
+		root, layer := merkle.Root()
+
+		failed := []string{}
+		if f.length > meta.pieceLength {
+			expected := meta.pieceLayers[string(expectedRoot)]
+			for i := range layer {
+				if (i+1)*sha256.Size > len(expected) || expected[i*sha256.Size:(i+1)*sha256.Size] != string(layer[i]) {
+					failed = append(failed, strconv.Itoa(i))
+				}
+			}
+		} else if !bytes.Equal(root, expectedRoot) {
+			failed = append(failed, "0")
+		}
+
+		if len(failed) != 0 || !sized || !bytes.Equal(root, expectedRoot) {
+			badFiles++
+		}
+		if len(failed) != 0 {
+			badPieces += len(failed)
+			fmt.Printf("%v: pieces %s failed\n", f.source, strings.Join(failed, ", "))
+		} else if !sized {
+			fmt.Printf("%v: File size does not match\n", f.source)
+		} else if VeryVerbose {
+			fmt.Printf("%v: Ok\n", f.source)
+		}
+	}
+
+	return torrentSummary(pieceCount, badPieces, len(meta.files), badFiles, missing)
+}
+
+func torrentSummary(pieces, badPieces, files, badFiles, missing int) (string, bool) {
+	status := Passed
+	if badPieces != 0 || badFiles != 0 {
+		status = Failed
+	}
+
+	return fmt.Sprintf(`hashit: Torrent verify %s
+       Pieces checked: %d
+          Pieces good: %d
+           Pieces bad: %d
+        Files checked: %d
+            Files bad: %d
+        Files missing: %d`+"\n", status, pieces, pieces-badPieces, badPieces, files, badFiles, missing), status == Passed
+}
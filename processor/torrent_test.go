@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestBencode(t *testing.T) {
+	input := map[string]any{
+		"name":   "test",
+		"length": int64(12),
+		"list":   []any{"a", int64(-1)},
+	}
+
+	encoded, err := bencode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// keys must be sorted
+	expected := "d6:lengthi12e4:listl1:ai-1ee4:name4:teste"
+	if string(encoded) != expected {
+		t.Errorf("expected %s got %s", expected, encoded)
+	}
+
+	decoded, err := bdecode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := bencode(decoded)
+	if !bytes.Equal(encoded, again) {
+		t.Errorf("expected round trip got %s", again)
+	}
+
+	for _, invalid := range []string{"i12", "l1:a", "5:ab", "d1:ae", "x"} {
+		if _, err := bdecode([]byte(invalid)); err == nil {
+			t.Errorf("%s expected error", invalid)
+		}
+	}
+}
+
+// The pieces root must be the same as building the whole tree from the 16 KiB
+// blocks, padded with zero hashes to a power of two
+func TestTorrentMerkleRoot(t *testing.T) {
+	for _, size := range []int{1, torrentBlockSize, 3*torrentBlockSize + 5, 9 * torrentBlockSize} {
+		content := bytes.Repeat([]byte{'a'}, size)
+
+		var leaves [][]byte
+		for i := 0; i < size; i += torrentBlockSize {
+			sum := sha256.Sum256(content[i:min(i+torrentBlockSize, size)])
+			leaves = append(leaves, sum[:])
+		}
+		expected := merkleRoot(leaves, nextPowerOfTwo(len(leaves)), make([]byte, 32))
+
+		m := newTorrentMerkle(2 * torrentBlockSize)
+		_, _ = m.Write(content)
+		root, layer := m.Root()
+
+		if !bytes.Equal(root, expected) {
+			t.Errorf("size %d expected root %x got %x", size, expected, root)
+		}
+
+		pieces := (size + 2*torrentBlockSize - 1) / (2 * torrentBlockSize)
+		if pieces == 1 && layer != nil || pieces > 1 && len(layer) != pieces {
+			t.Errorf("size %d expected %d pieces got %d", size, pieces, len(layer))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: MIT
+
+package processor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// uriHashes are the hashes which can be written as links by the uri format
+// and are used when none of them have been selected
+var uriHashes = []string{"ed2k", "aich", "tth", "cidv1"}
+
+// toURI writes links that peer-to-peer and content-addressed clients accept
+// for each file, being ed2k links, magnet links using the tiger tree hash and
+// ipfs links
+func toURI(input chan Result, output string) string {
+	var str strings.Builder
+
+	for res := range input {
+		str.WriteString(resultURIs(res))
+
+		if !NoStream && output == "" {
+			fmt.Print(str.String())
+			str.Reset()
+		}
+	}
+
+	return str.String()
+}
+
+func resultURIs(res Result) string {
+	var str strings.Builder
+	name := filepath.Base(res.File)
+
+	// ed2k links are always hex whatever the encoding
+	if hasHash(HashNames.Ed2k) {
+		str.WriteString(fmt.Sprintf("ed2k://|file|%s|%d|%s|", url.PathEscape(name), res.Bytes, hex.EncodeToString(decodeEncoded(res.Ed2k))))
+		if hasHash(HashNames.AICH) {
+			str.WriteString("h=" + res.AICH + "|")
+		}
+		str.WriteString("/\n")
+	}
+	if hasHash(HashNames.TTH) {
+		str.WriteString(fmt.Sprintf("magnet:?xt=urn:tree:tiger:%s&xl=%d&dn=%s\n", res.TTH, res.Bytes, url.QueryEscape(name)))
+	}
+	if hasHash(HashNames.CIDv0) {
+		str.WriteString(fmt.Sprintf("ipfs://%s?filename=%s\n", res.CIDv0, url.QueryEscape(name)))
+	}
+	if hasHash(HashNames.CIDv1) {
+		str.WriteString(fmt.Sprintf("ipfs://%s?filename=%s\n", res.CIDv1, url.QueryEscape(name)))
+	}
+
+	return str.String()
+}
@@ -9,9 +9,9 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
-	"encoding/hex"
 	"fmt"
 	"hash"
+	"hash/adler32"
 	"hash/crc32"
 	"io"
 	"log"
@@ -22,9 +22,11 @@ import (
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/djherbis/times"
+	"github.com/glaslos/ssdeep"
 	"github.com/gosuri/uiprogress"
 	"github.com/minio/blake2b-simd"
-	"github.com/zeebo/blake3"
+	"github.com/spaolacci/murmur3"
+	"github.com/zeebo/xxh3"
 	"go.felesatra.moe/hash/ed2k"
 	"golang.org/x/crypto/md4"
 	"golang.org/x/crypto/sha3"
@@ -118,12 +120,19 @@ func fileProcessorWorker(input chan string, output chan Result) {
 			var r Result
 
 			// For larger files if we have more than one hash try parallel
-			if fsize > 200000 && len(Hash) >= 1 && !hasHash("all") {
+			if fsize > 200000 && len(Hash) >= 1 && !readHash("all") {
 				r, err = processReadFileParallel(res, &content)
 			} else {
 				r, err = processReadFile(res, &content)
 			}
 
+			if err == nil && Piecewise > 0 {
+				r.Blocks, err = piecewiseBlocks(res, content)
+				if err != nil {
+					printError(fmt.Sprintf("piecewise hashing %s: %s", res, err.Error()))
+				}
+			}
+
 			if Progress {
 				_ = bar.Set(UiBarMax)
 			}
@@ -155,26 +164,58 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 
 	crc32_d := crc32.NewIEEE()
 	xxhash64_d := xxhash.New()
+	crc32c_d := newCRC32C()
+	crc64_ecma_d := newCRC64ECMA()
+	crc64_iso_d := newCRC64ISO()
+	adler32_d := adler32.New()
+	xxh3_d := xxh3.New()
+	xxh128_d := newXXH128()
+	murmur3_d := murmur3.New128()
+	siphash_d := newSipHash()
+	highwayhash_d := newHighwayHash()
 	md4_d := md4.New()
 	md5_d := md5.New()
 	sha1_d := sha1.New()
 	sha256_d := sha256.New()
+	sha384_d := sha512.New384()
 	sha512_d := sha512.New()
 	blake2b_256_d := blake2b.New256()
 	blake2b_512_d := blake2b.New512()
-	blake3_d := blake3.New()
+	blake3_d := newBlake3()
 	sha3_224_d := sha3.New224()
 	sha3_256_d := sha3.New256()
 	sha3_384_d := sha3.New384()
 	sha3_512_d := sha3.New512()
+	shake128_d := newShake128()
+	shake256_d := newShake256()
+	cshake128_d := newCShake128()
+	cshake256_d := newCShake256()
 	ed2k_d := ed2k.New()
+	aich_d := newAICH()
+	tth_d := newTTH()
+	cidv0_d := newCIDv0()
+	cidv1_d := newCIDv1()
+	git_sha1_d := newGitSHA1(int64(fsize))
+	git_sha256_d := newGitSHA256(int64(fsize))
+	ssdeep_d := ssdeep.New()
+	tlsh_d := newTLSH()
 
 	crc32c := make(chan []byte, 10)
 	xxhash64c := make(chan []byte, 10)
+	crc32c_c := make(chan []byte, 10)
+	crc64_ecma_c := make(chan []byte, 10)
+	crc64_iso_c := make(chan []byte, 10)
+	adler32_c := make(chan []byte, 10)
+	xxh3_c := make(chan []byte, 10)
+	xxh128_c := make(chan []byte, 10)
+	murmur3_c := make(chan []byte, 10)
+	siphash_c := make(chan []byte, 10)
+	highwayhash_c := make(chan []byte, 10)
 	md4c := make(chan []byte, 10)
 	md5c := make(chan []byte, 10)
 	sha1c := make(chan []byte, 10)
 	sha256c := make(chan []byte, 10)
+	sha384c := make(chan []byte, 10)
 	sha512c := make(chan []byte, 10)
 	blake2b_256_c := make(chan []byte, 10)
 	blake2b_512_c := make(chan []byte, 10)
@@ -183,11 +224,23 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 	sha3_256_c := make(chan []byte, 10)
 	sha3_384_c := make(chan []byte, 10)
 	sha3_512_c := make(chan []byte, 10)
+	shake128_c := make(chan []byte, 10)
+	shake256_c := make(chan []byte, 10)
+	cshake128_c := make(chan []byte, 10)
+	cshake256_c := make(chan []byte, 10)
 	ed2k_c := make(chan []byte, 10)
+	aich_c := make(chan []byte, 10)
+	tth_c := make(chan []byte, 10)
+	cidv0_c := make(chan []byte, 10)
+	cidv1_c := make(chan []byte, 10)
+	git_sha1_c := make(chan []byte, 10)
+	git_sha256_c := make(chan []byte, 10)
+	ssdeep_c := make(chan []byte, 10)
+	tlsh_c := make(chan []byte, 10)
 
 	var wg sync.WaitGroup
 
-	if hasHash(HashNames.CRC32) {
+	if readHash(HashNames.CRC32) {
 		wg.Add(1)
 		go func() {
 			for b := range crc32c {
@@ -197,7 +250,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.XxHash64) {
+	if readHash(HashNames.XxHash64) {
 		wg.Add(1)
 		go func() {
 			for b := range xxhash64c {
@@ -207,7 +260,97 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.MD4) {
+	if readHash(HashNames.CRC32C) {
+		wg.Add(1)
+		go func() {
+			for b := range crc32c_c {
+				_, _ = crc32c_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CRC64ECMA) {
+		wg.Add(1)
+		go func() {
+			for b := range crc64_ecma_c {
+				_, _ = crc64_ecma_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CRC64ISO) {
+		wg.Add(1)
+		go func() {
+			for b := range crc64_iso_c {
+				_, _ = crc64_iso_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Adler32) {
+		wg.Add(1)
+		go func() {
+			for b := range adler32_c {
+				_, _ = adler32_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.XXH3) {
+		wg.Add(1)
+		go func() {
+			for b := range xxh3_c {
+				_, _ = xxh3_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.XXH128) {
+		wg.Add(1)
+		go func() {
+			for b := range xxh128_c {
+				_, _ = xxh128_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Murmur3) {
+		wg.Add(1)
+		go func() {
+			for b := range murmur3_c {
+				_, _ = murmur3_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SipHash) {
+		wg.Add(1)
+		go func() {
+			for b := range siphash_c {
+				_, _ = siphash_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.HighwayHash) {
+		wg.Add(1)
+		go func() {
+			for b := range highwayhash_c {
+				_, _ = highwayhash_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.MD4) {
 		wg.Add(1)
 		go func() {
 			for b := range md4c {
@@ -217,7 +360,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.MD5) {
+	if readHash(HashNames.MD5) {
 		wg.Add(1)
 		go func() {
 			for b := range md5c {
@@ -227,7 +370,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.SHA1) {
+	if readHash(HashNames.SHA1) {
 		wg.Add(1)
 		go func() {
 			for b := range sha1c {
@@ -237,7 +380,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.SHA256) {
+	if readHash(HashNames.SHA256) {
 		wg.Add(1)
 		go func() {
 			for b := range sha256c {
@@ -247,7 +390,17 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.SHA512) {
+	if readHash(HashNames.SHA384) {
+		wg.Add(1)
+		go func() {
+			for b := range sha384c {
+				_, _ = sha384_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SHA512) {
 		wg.Add(1)
 		go func() {
 			for b := range sha512c {
@@ -257,7 +410,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b256) {
+	if readHash(HashNames.Blake2b256) {
 		wg.Add(1)
 		go func() {
 			for b := range blake2b_256_c {
@@ -267,7 +420,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b512) {
+	if readHash(HashNames.Blake2b512) {
 		wg.Add(1)
 		go func() {
 			for b := range blake2b_512_c {
@@ -277,7 +430,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.Blake3) {
+	if readHash(HashNames.Blake3) {
 		wg.Add(1)
 		go func() {
 			for b := range blake3c {
@@ -287,7 +440,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
-	if hasHash(HashNames.Sha3224) {
+	if readHash(HashNames.Sha3224) {
 		wg.Add(1)
 		go func() {
 			for b := range sha3_224_c {
@@ -296,7 +449,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3256) {
+	if readHash(HashNames.Sha3256) {
 		wg.Add(1)
 		go func() {
 			for b := range sha3_256_c {
@@ -305,7 +458,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3384) {
+	if readHash(HashNames.Sha3384) {
 		wg.Add(1)
 		go func() {
 			for b := range sha3_384_c {
@@ -314,7 +467,7 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3512) {
+	if readHash(HashNames.Sha3512) {
 		wg.Add(1)
 		go func() {
 			for b := range sha3_512_c {
@@ -323,7 +476,48 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Ed2k) {
+
+	if readHash(HashNames.Shake128) {
+		wg.Add(1)
+		go func() {
+			for b := range shake128_c {
+				_, _ = shake128_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Shake256) {
+		wg.Add(1)
+		go func() {
+			for b := range shake256_c {
+				_, _ = shake256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CShake128) {
+		wg.Add(1)
+		go func() {
+			for b := range cshake128_c {
+				_, _ = cshake128_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CShake256) {
+		wg.Add(1)
+		go func() {
+			for b := range cshake256_c {
+				_, _ = cshake256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Ed2k) {
 		wg.Add(1)
 		go func() {
 			for b := range ed2k_c {
@@ -334,6 +528,91 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		}()
 	}
 
+	if readHash(HashNames.AICH) {
+		wg.Add(1)
+		go func() {
+			for b := range aich_c {
+				_, _ = aich_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.TTH) {
+		wg.Add(1)
+		go func() {
+			for b := range tth_c {
+				_, _ = tth_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CIDv0) {
+		wg.Add(1)
+		go func() {
+			for b := range cidv0_c {
+				_, _ = cidv0_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CIDv1) {
+		wg.Add(1)
+		go func() {
+			for b := range cidv1_c {
+				_, _ = cidv1_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.GitSHA1) {
+		wg.Add(1)
+		go func() {
+			for b := range git_sha1_c {
+				_, _ = git_sha1_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.GitSHA256) {
+		wg.Add(1)
+		go func() {
+			for b := range git_sha256_c {
+				_, _ = git_sha256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SSDeep) {
+		wg.Add(1)
+		go func() {
+			for b := range ssdeep_c {
+				_, _ = ssdeep_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.TLSH) {
+		wg.Add(1)
+		go func() {
+			for b := range tlsh_c {
+				_, _ = tlsh_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	var pieces *piecewiseHasher
+	if Piecewise > 0 {
+		pieces = newPiecewiseHasher(filename, Piecewise)
+	}
+
 	sum := 0
 	data := make([]byte, 4_194_304)
 	for {
@@ -361,51 +640,121 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 		tmp := make([]byte, len(data))
 		copy(tmp, data)
 
-		if hasHash(HashNames.CRC32) {
+		if pieces != nil {
+			_, _ = pieces.Write(tmp[:n])
+		}
+
+		if readHash(HashNames.CRC32) {
 			crc32c <- tmp[:n]
 		}
-		if hasHash(HashNames.XxHash64) {
+		if readHash(HashNames.XxHash64) {
 			xxhash64c <- tmp[:n]
 		}
-		if hasHash(HashNames.MD4) {
+		if readHash(HashNames.CRC32C) {
+			crc32c_c <- tmp[:n]
+		}
+		if readHash(HashNames.CRC64ECMA) {
+			crc64_ecma_c <- tmp[:n]
+		}
+		if readHash(HashNames.CRC64ISO) {
+			crc64_iso_c <- tmp[:n]
+		}
+		if readHash(HashNames.Adler32) {
+			adler32_c <- tmp[:n]
+		}
+		if readHash(HashNames.XXH3) {
+			xxh3_c <- tmp[:n]
+		}
+		if readHash(HashNames.XXH128) {
+			xxh128_c <- tmp[:n]
+		}
+		if readHash(HashNames.Murmur3) {
+			murmur3_c <- tmp[:n]
+		}
+		if readHash(HashNames.SipHash) {
+			siphash_c <- tmp[:n]
+		}
+		if readHash(HashNames.HighwayHash) {
+			highwayhash_c <- tmp[:n]
+		}
+		if readHash(HashNames.MD4) {
 			md4c <- tmp[:n]
 		}
-		if hasHash(HashNames.MD5) {
+		if readHash(HashNames.MD5) {
 			md5c <- tmp[:n]
 		}
-		if hasHash(HashNames.SHA1) {
+		if readHash(HashNames.SHA1) {
 			sha1c <- tmp[:n]
 		}
-		if hasHash(HashNames.SHA256) {
+		if readHash(HashNames.SHA256) {
 			sha256c <- tmp[:n]
 		}
-		if hasHash(HashNames.SHA512) {
+		if readHash(HashNames.SHA384) {
+			sha384c <- tmp[:n]
+		}
+		if readHash(HashNames.SHA512) {
 			sha512c <- tmp[:n]
 		}
-		if hasHash(HashNames.Blake2b256) {
+		if readHash(HashNames.Blake2b256) {
 			blake2b_256_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Blake2b512) {
+		if readHash(HashNames.Blake2b512) {
 			blake2b_512_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Blake3) {
+		if readHash(HashNames.Blake3) {
 			blake3c <- tmp[:n]
 		}
-		if hasHash(HashNames.Sha3224) {
+		if readHash(HashNames.Sha3224) {
 			sha3_224_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Sha3256) {
+		if readHash(HashNames.Sha3256) {
 			sha3_256_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Sha3384) {
+		if readHash(HashNames.Sha3384) {
 			sha3_384_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Sha3512) {
+		if readHash(HashNames.Sha3512) {
 			sha3_512_c <- tmp[:n]
 		}
-		if hasHash(HashNames.Ed2k) {
+		if readHash(HashNames.Shake128) {
+			shake128_c <- tmp[:n]
+		}
+		if readHash(HashNames.Shake256) {
+			shake256_c <- tmp[:n]
+		}
+		if readHash(HashNames.CShake128) {
+			cshake128_c <- tmp[:n]
+		}
+		if readHash(HashNames.CShake256) {
+			cshake256_c <- tmp[:n]
+		}
+		if readHash(HashNames.Ed2k) {
 			ed2k_c <- tmp[:n]
 		}
+		if readHash(HashNames.AICH) {
+			aich_c <- tmp[:n]
+		}
+		if readHash(HashNames.TTH) {
+			tth_c <- tmp[:n]
+		}
+		if readHash(HashNames.CIDv0) {
+			cidv0_c <- tmp[:n]
+		}
+		if readHash(HashNames.CIDv1) {
+			cidv1_c <- tmp[:n]
+		}
+		if readHash(HashNames.GitSHA1) {
+			git_sha1_c <- tmp[:n]
+		}
+		if readHash(HashNames.GitSHA256) {
+			git_sha256_c <- tmp[:n]
+		}
+		if readHash(HashNames.SSDeep) {
+			ssdeep_c <- tmp[:n]
+		}
+		if readHash(HashNames.TLSH) {
+			tlsh_c <- tmp[:n]
+		}
 
 		if err == io.EOF {
 			break
@@ -414,10 +763,20 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 
 	close(crc32c)
 	close(xxhash64c)
+	close(crc32c_c)
+	close(crc64_ecma_c)
+	close(crc64_iso_c)
+	close(adler32_c)
+	close(xxh3_c)
+	close(xxh128_c)
+	close(murmur3_c)
+	close(siphash_c)
+	close(highwayhash_c)
 	close(md4c)
 	close(md5c)
 	close(sha1c)
 	close(sha256c)
+	close(sha384c)
 	close(sha512c)
 	close(blake2b_256_c)
 	close(blake2b_512_c)
@@ -426,58 +785,150 @@ func processScanner(filename string, fsize int, bar *uiprogress.Bar) (Result, er
 	close(sha3_256_c)
 	close(sha3_384_c)
 	close(sha3_512_c)
+	close(shake128_c)
+	close(shake256_c)
+	close(cshake128_c)
+	close(cshake256_c)
 	close(ed2k_c)
+	close(aich_c)
+	close(tth_c)
+	close(cidv0_c)
+	close(cidv1_c)
+	close(git_sha1_c)
+	close(git_sha256_c)
+	close(ssdeep_c)
+	close(tlsh_c)
 
 	wg.Wait()
 
+	var blocks []Block
+	if pieces != nil {
+		var err error
+		if blocks, err = pieces.Blocks(); err != nil {
+			printError(fmt.Sprintf("piecewise hashing %s: %s", filename, err.Error()))
+			return Result{}, err
+		}
+	}
+
 	return Result{
-		File:       filename,
-		Bytes:      0,
-		CRC32:      encodeIfHashEnabled(crc32_d, HashNames.CRC32),
-		XxHash64:   encodeIfHashEnabled(xxhash64_d, HashNames.XxHash64),
-		MD4:        encodeIfHashEnabled(md4_d, HashNames.MD4),
-		MD5:        encodeIfHashEnabled(md5_d, HashNames.MD5),
-		SHA1:       encodeIfHashEnabled(sha1_d, HashNames.SHA1),
-		SHA256:     encodeIfHashEnabled(sha256_d, HashNames.SHA256),
-		SHA512:     encodeIfHashEnabled(sha512_d, HashNames.SHA512),
-		Blake2b256: encodeIfHashEnabled(blake2b_256_d, HashNames.Blake2b256),
-		Blake2b512: encodeIfHashEnabled(blake2b_512_d, HashNames.Blake2b512),
-		Blake3:     encodeIfHashEnabled(blake3_d, HashNames.Blake3),
-		Sha3224:    encodeIfHashEnabled(sha3_224_d, HashNames.Sha3224),
-		Sha3256:    encodeIfHashEnabled(sha3_256_d, HashNames.Sha3256),
-		Sha3384:    encodeIfHashEnabled(sha3_384_d, HashNames.Sha3384),
-		Sha3512:    encodeIfHashEnabled(sha3_512_d, HashNames.Sha3512),
-		Ed2k:       encodeIfHashEnabled(ed2k_d, HashNames.Ed2k),
+		File:        filename,
+		Bytes:       0,
+		Blocks:      blocks,
+		CRC32:       encodeIfHashEnabled(crc32_d, HashNames.CRC32),
+		XxHash64:    encodeIfHashEnabled(xxhash64_d, HashNames.XxHash64),
+		CRC32C:      encodeIfHashEnabled(crc32c_d, HashNames.CRC32C),
+		CRC64ECMA:   encodeIfHashEnabled(crc64_ecma_d, HashNames.CRC64ECMA),
+		CRC64ISO:    encodeIfHashEnabled(crc64_iso_d, HashNames.CRC64ISO),
+		Adler32:     encodeIfHashEnabled(adler32_d, HashNames.Adler32),
+		XXH3:        encodeIfHashEnabled(xxh3_d, HashNames.XXH3),
+		XXH128:      encodeIfHashEnabled(xxh128_d, HashNames.XXH128),
+		Murmur3:     encodeIfHashEnabled(murmur3_d, HashNames.Murmur3),
+		SipHash:     encodeIfHashEnabled(siphash_d, HashNames.SipHash),
+		HighwayHash: encodeIfHashEnabled(highwayhash_d, HashNames.HighwayHash),
+		MD4:         encodeIfHashEnabled(md4_d, HashNames.MD4),
+		MD5:         encodeIfHashEnabled(md5_d, HashNames.MD5),
+		SHA1:        encodeIfHashEnabled(sha1_d, HashNames.SHA1),
+		SHA256:      encodeIfHashEnabled(sha256_d, HashNames.SHA256),
+		SHA384:      encodeIfHashEnabled(sha384_d, HashNames.SHA384),
+		SHA512:      encodeIfHashEnabled(sha512_d, HashNames.SHA512),
+		Blake2b256:  encodeIfHashEnabled(blake2b_256_d, HashNames.Blake2b256),
+		Blake2b512:  encodeIfHashEnabled(blake2b_512_d, HashNames.Blake2b512),
+		Blake3:      encodeIfHashEnabled(blake3_d, HashNames.Blake3),
+		Sha3224:     encodeIfHashEnabled(sha3_224_d, HashNames.Sha3224),
+		Sha3256:     encodeIfHashEnabled(sha3_256_d, HashNames.Sha3256),
+		Sha3384:     encodeIfHashEnabled(sha3_384_d, HashNames.Sha3384),
+		Sha3512:     encodeIfHashEnabled(sha3_512_d, HashNames.Sha3512),
+		Shake128:    encodeIfHashEnabled(shake128_d, HashNames.Shake128),
+		Shake256:    encodeIfHashEnabled(shake256_d, HashNames.Shake256),
+		CShake128:   encodeIfHashEnabled(cshake128_d, HashNames.CShake128),
+		CShake256:   encodeIfHashEnabled(cshake256_d, HashNames.CShake256),
+		Ed2k:        encodeIfHashEnabled(ed2k_d, HashNames.Ed2k),
+		AICH:        sumIfHashEnabled(aich_d, HashNames.AICH),
+		TTH:         sumIfHashEnabled(tth_d, HashNames.TTH),
+		CIDv0:       sumIfHashEnabled(cidv0_d, HashNames.CIDv0),
+		CIDv1:       sumIfHashEnabled(cidv1_d, HashNames.CIDv1),
+		GitSHA1:     encodeIfHashEnabled(git_sha1_d, HashNames.GitSHA1),
+		GitSHA256:   encodeIfHashEnabled(git_sha256_d, HashNames.GitSHA256),
+		SSDeep:      sumIfHashEnabled(ssdeep_d, HashNames.SSDeep),
+		TLSH:        sumIfHashEnabled(tlsh_d, HashNames.TLSH),
 	}, nil
 }
 
 func processStandardInput(output chan Result) {
+	res, err := processReader("stdin", os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	output <- res
+	close(output)
+}
+
+// processReader hashes everything read from the reader in a single pass
+// which is used for standard input and for files as they are copied
+func processReader(name string, input io.Reader) (Result, error) {
 	total, nChunks := int64(0), int64(0)
-	r := bufio.NewReader(os.Stdin)
-	buf := make([]byte, 0, 4*1024)
+	r := bufio.NewReader(input)
+
+	var pieces *piecewiseHasher
+	if Piecewise > 0 {
+		pieces = newPiecewiseHasher(name, Piecewise)
+	}
 
 	crc32_d := crc32.NewIEEE()
 	xxhash64_d := xxhash.New()
+	crc32c_d := newCRC32C()
+	crc64_ecma_d := newCRC64ECMA()
+	crc64_iso_d := newCRC64ISO()
+	adler32_d := adler32.New()
+	xxh3_d := xxh3.New()
+	xxh128_d := newXXH128()
+	murmur3_d := murmur3.New128()
+	siphash_d := newSipHash()
+	highwayhash_d := newHighwayHash()
 	md4_d := md4.New()
 	md5_d := md5.New()
 	sha1_d := sha1.New()
 	sha256_d := sha256.New()
+	sha384_d := sha512.New384()
 	sha512_d := sha512.New()
 	blake2b_256_d := blake2b.New256()
 	blake2b_512_d := blake2b.New512()
-	blake3_d := blake3.New()
+	blake3_d := newBlake3()
 	sha3_224_d := sha3.New224()
 	sha3_256_d := sha3.New256()
 	sha3_384_d := sha3.New384()
 	sha3_512_d := sha3.New512()
+	shake128_d := newShake128()
+	shake256_d := newShake256()
+	cshake128_d := newCShake128()
+	cshake256_d := newCShake256()
 	ed2k_d := ed2k.New()
+	aich_d := newAICH()
+	tth_d := newTTH()
+	cidv0_d := newCIDv0()
+	cidv1_d := newCIDv1()
+	git_sha1_d := newGitSHA1(-1)
+	git_sha256_d := newGitSHA256(-1)
+	ssdeep_d := ssdeep.New()
+	tlsh_d := newTLSH()
 
 	crc32c := make(chan []byte, 10)
 	xxhash64c := make(chan []byte, 10)
+	crc32c_c := make(chan []byte, 10)
+	crc64_ecma_c := make(chan []byte, 10)
+	crc64_iso_c := make(chan []byte, 10)
+	adler32_c := make(chan []byte, 10)
+	xxh3_c := make(chan []byte, 10)
+	xxh128_c := make(chan []byte, 10)
+	murmur3_c := make(chan []byte, 10)
+	siphash_c := make(chan []byte, 10)
+	highwayhash_c := make(chan []byte, 10)
 	md4c := make(chan []byte, 10)
 	md5c := make(chan []byte, 10)
 	sha1c := make(chan []byte, 10)
 	sha256c := make(chan []byte, 10)
+	sha384c := make(chan []byte, 10)
 	sha512c := make(chan []byte, 10)
 	blake2b_256_c := make(chan []byte, 10)
 	blake2b_512_c := make(chan []byte, 10)
@@ -486,11 +937,23 @@ func processStandardInput(output chan Result) {
 	sha3_256_c := make(chan []byte, 10)
 	sha3_384_c := make(chan []byte, 10)
 	sha3_512_c := make(chan []byte, 10)
+	shake128_c := make(chan []byte, 10)
+	shake256_c := make(chan []byte, 10)
+	cshake128_c := make(chan []byte, 10)
+	cshake256_c := make(chan []byte, 10)
 	ed2k_c := make(chan []byte, 10)
+	aich_c := make(chan []byte, 10)
+	tth_c := make(chan []byte, 10)
+	cidv0_c := make(chan []byte, 10)
+	cidv1_c := make(chan []byte, 10)
+	git_sha1_c := make(chan []byte, 10)
+	git_sha256_c := make(chan []byte, 10)
+	ssdeep_c := make(chan []byte, 10)
+	tlsh_c := make(chan []byte, 10)
 
 	var wg sync.WaitGroup
 
-	if hasHash(HashNames.CRC32) {
+	if readHash(HashNames.CRC32) {
 		wg.Add(1)
 		go func() {
 			for b := range crc32c {
@@ -500,7 +963,7 @@ func processStandardInput(output chan Result) {
 		}()
 	}
 
-	if hasHash(HashNames.XxHash64) {
+	if readHash(HashNames.XxHash64) {
 		wg.Add(1)
 		go func() {
 			for b := range xxhash64c {
@@ -510,208 +973,513 @@ func processStandardInput(output chan Result) {
 		}()
 	}
 
-	if hasHash(HashNames.MD4) {
+	if readHash(HashNames.CRC32C) {
 		wg.Add(1)
 		go func() {
-			for b := range md4c {
-				md4_d.Write(b)
+			for b := range crc32c_c {
+				_, _ = crc32c_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.MD5) {
+	if readHash(HashNames.CRC64ECMA) {
 		wg.Add(1)
 		go func() {
-			for b := range md5c {
-				md5_d.Write(b)
+			for b := range crc64_ecma_c {
+				_, _ = crc64_ecma_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA1) {
+	if readHash(HashNames.CRC64ISO) {
 		wg.Add(1)
 		go func() {
-			for b := range sha1c {
-				sha1_d.Write(b)
+			for b := range crc64_iso_c {
+				_, _ = crc64_iso_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA256) {
+	if readHash(HashNames.Adler32) {
 		wg.Add(1)
 		go func() {
-			for b := range sha256c {
-				sha256_d.Write(b)
+			for b := range adler32_c {
+				_, _ = adler32_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA512) {
+	if readHash(HashNames.XXH3) {
 		wg.Add(1)
 		go func() {
-			for b := range sha512c {
-				sha512_d.Write(b)
+			for b := range xxh3_c {
+				_, _ = xxh3_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b256) {
+	if readHash(HashNames.XXH128) {
 		wg.Add(1)
 		go func() {
-			for b := range blake2b_256_c {
-				blake2b_256_d.Write(b)
+			for b := range xxh128_c {
+				_, _ = xxh128_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b512) {
+	if readHash(HashNames.Murmur3) {
 		wg.Add(1)
 		go func() {
-			for b := range blake2b_512_c {
-				blake2b_512_d.Write(b)
+			for b := range murmur3_c {
+				_, _ = murmur3_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Blake3) {
+	if readHash(HashNames.SipHash) {
 		wg.Add(1)
 		go func() {
-			for b := range blake3c {
-				_, _ = blake3_d.Write(b)
+			for b := range siphash_c {
+				_, _ = siphash_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Sha3224) {
+	if readHash(HashNames.HighwayHash) {
 		wg.Add(1)
 		go func() {
-			for b := range sha3_224_c {
-				sha3_224_d.Write(b)
+			for b := range highwayhash_c {
+				_, _ = highwayhash_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3256) {
+
+	if readHash(HashNames.MD4) {
 		wg.Add(1)
 		go func() {
-			for b := range sha3_256_c {
-				sha3_256_d.Write(b)
+			for b := range md4c {
+				md4_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3384) {
+
+	if readHash(HashNames.MD5) {
 		wg.Add(1)
 		go func() {
-			for b := range sha3_384_c {
-				sha3_384_d.Write(b)
+			for b := range md5c {
+				md5_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Sha3512) {
+
+	if readHash(HashNames.SHA1) {
 		wg.Add(1)
 		go func() {
-			for b := range sha3_512_c {
-				sha3_512_d.Write(b)
+			for b := range sha1c {
+				sha1_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
-	if hasHash(HashNames.Ed2k) {
+
+	if readHash(HashNames.SHA256) {
 		wg.Add(1)
 		go func() {
-			for b := range ed2k_c {
-				_, _ = ed2k_d.Write(b)
+			for b := range sha256c {
+				sha256_d.Write(b)
 			}
 			wg.Done()
 		}()
 	}
 
-	for {
-		n, err := r.Read(buf[:cap(buf)])
-		buf = buf[:n]
-
-		if n == 0 {
-			if err == nil {
-				continue
+	if readHash(HashNames.SHA384) {
+		wg.Add(1)
+		go func() {
+			for b := range sha384c {
+				_, _ = sha384_d.Write(b)
 			}
+			wg.Done()
+		}()
+	}
 
-			if err == io.EOF {
-				break
+	if readHash(HashNames.SHA512) {
+		wg.Add(1)
+		go func() {
+			for b := range sha512c {
+				sha512_d.Write(b)
 			}
+			wg.Done()
+		}()
+	}
 
-			log.Fatal(err)
-		}
-
-		nChunks++
-		total += int64(len(buf))
-
-		if hasHash(HashNames.CRC32) {
+	if readHash(HashNames.Blake2b256) {
+		wg.Add(1)
+		go func() {
+			for b := range blake2b_256_c {
+				blake2b_256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Blake2b512) {
+		wg.Add(1)
+		go func() {
+			for b := range blake2b_512_c {
+				blake2b_512_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Blake3) {
+		wg.Add(1)
+		go func() {
+			for b := range blake3c {
+				_, _ = blake3_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Sha3224) {
+		wg.Add(1)
+		go func() {
+			for b := range sha3_224_c {
+				sha3_224_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+	if readHash(HashNames.Sha3256) {
+		wg.Add(1)
+		go func() {
+			for b := range sha3_256_c {
+				sha3_256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+	if readHash(HashNames.Sha3384) {
+		wg.Add(1)
+		go func() {
+			for b := range sha3_384_c {
+				sha3_384_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+	if readHash(HashNames.Sha3512) {
+		wg.Add(1)
+		go func() {
+			for b := range sha3_512_c {
+				sha3_512_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Shake128) {
+		wg.Add(1)
+		go func() {
+			for b := range shake128_c {
+				_, _ = shake128_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Shake256) {
+		wg.Add(1)
+		go func() {
+			for b := range shake256_c {
+				_, _ = shake256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CShake128) {
+		wg.Add(1)
+		go func() {
+			for b := range cshake128_c {
+				_, _ = cshake128_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CShake256) {
+		wg.Add(1)
+		go func() {
+			for b := range cshake256_c {
+				_, _ = cshake256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Ed2k) {
+		wg.Add(1)
+		go func() {
+			for b := range ed2k_c {
+				_, _ = ed2k_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.AICH) {
+		wg.Add(1)
+		go func() {
+			for b := range aich_c {
+				_, _ = aich_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.TTH) {
+		wg.Add(1)
+		go func() {
+			for b := range tth_c {
+				_, _ = tth_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CIDv0) {
+		wg.Add(1)
+		go func() {
+			for b := range cidv0_c {
+				_, _ = cidv0_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CIDv1) {
+		wg.Add(1)
+		go func() {
+			for b := range cidv1_c {
+				_, _ = cidv1_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.GitSHA1) {
+		wg.Add(1)
+		go func() {
+			for b := range git_sha1_c {
+				_, _ = git_sha1_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.GitSHA256) {
+		wg.Add(1)
+		go func() {
+			for b := range git_sha256_c {
+				_, _ = git_sha256_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SSDeep) {
+		wg.Add(1)
+		go func() {
+			for b := range ssdeep_c {
+				_, _ = ssdeep_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.TLSH) {
+		wg.Add(1)
+		go func() {
+			for b := range tlsh_c {
+				_, _ = tlsh_d.Write(b)
+			}
+			wg.Done()
+		}()
+	}
+
+	var readErr error
+	for {
+		// Need a new buffer each time as the previous one can still be
+		// waiting in a channel for a goroutine to process it
+		buf := make([]byte, 4*1024)
+		n, err := r.Read(buf)
+		buf = buf[:n]
+
+		if n == 0 {
+			if err == nil {
+				continue
+			}
+
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+
+		nChunks++
+		total += int64(len(buf))
+
+		if pieces != nil {
+			_, _ = pieces.Write(buf)
+		}
+
+		if readHash(HashNames.CRC32) {
 			crc32c <- buf
 		}
-		if hasHash(HashNames.XxHash64) {
+		if readHash(HashNames.XxHash64) {
 			xxhash64c <- buf
 		}
-		if hasHash(HashNames.MD4) {
+		if readHash(HashNames.CRC32C) {
+			crc32c_c <- buf
+		}
+		if readHash(HashNames.CRC64ECMA) {
+			crc64_ecma_c <- buf
+		}
+		if readHash(HashNames.CRC64ISO) {
+			crc64_iso_c <- buf
+		}
+		if readHash(HashNames.Adler32) {
+			adler32_c <- buf
+		}
+		if readHash(HashNames.XXH3) {
+			xxh3_c <- buf
+		}
+		if readHash(HashNames.XXH128) {
+			xxh128_c <- buf
+		}
+		if readHash(HashNames.Murmur3) {
+			murmur3_c <- buf
+		}
+		if readHash(HashNames.SipHash) {
+			siphash_c <- buf
+		}
+		if readHash(HashNames.HighwayHash) {
+			highwayhash_c <- buf
+		}
+		if readHash(HashNames.MD4) {
 			md4c <- buf
 		}
-		if hasHash(HashNames.MD5) {
+		if readHash(HashNames.MD5) {
 			md5c <- buf
 		}
-		if hasHash(HashNames.SHA1) {
+		if readHash(HashNames.SHA1) {
 			sha1c <- buf
 		}
-		if hasHash(HashNames.SHA256) {
+		if readHash(HashNames.SHA256) {
 			sha256c <- buf
 		}
-		if hasHash(HashNames.SHA512) {
+		if readHash(HashNames.SHA384) {
+			sha384c <- buf
+		}
+		if readHash(HashNames.SHA512) {
 			sha512c <- buf
 		}
-		if hasHash(HashNames.Blake2b256) {
+		if readHash(HashNames.Blake2b256) {
 			blake2b_256_c <- buf
 		}
-		if hasHash(HashNames.Blake2b512) {
+		if readHash(HashNames.Blake2b512) {
 			blake2b_512_c <- buf
 		}
-		if hasHash(HashNames.Blake3) {
+		if readHash(HashNames.Blake3) {
 			blake3c <- buf
 		}
-		if hasHash(HashNames.Sha3224) {
+		if readHash(HashNames.Sha3224) {
 			sha3_224_c <- buf
 		}
-		if hasHash(HashNames.Sha3256) {
+		if readHash(HashNames.Sha3256) {
 			sha3_256_c <- buf
 		}
-		if hasHash(HashNames.Sha3384) {
+		if readHash(HashNames.Sha3384) {
 			sha3_384_c <- buf
 		}
-		if hasHash(HashNames.Sha3512) {
+		if readHash(HashNames.Sha3512) {
 			sha3_512_c <- buf
 		}
-		if hasHash(HashNames.Ed2k) {
+		if readHash(HashNames.Shake128) {
+			shake128_c <- buf
+		}
+		if readHash(HashNames.Shake256) {
+			shake256_c <- buf
+		}
+		if readHash(HashNames.CShake128) {
+			cshake128_c <- buf
+		}
+		if readHash(HashNames.CShake256) {
+			cshake256_c <- buf
+		}
+		if readHash(HashNames.Ed2k) {
 			ed2k_c <- buf
 		}
+		if readHash(HashNames.AICH) {
+			aich_c <- buf
+		}
+		if readHash(HashNames.TTH) {
+			tth_c <- buf
+		}
+		if readHash(HashNames.CIDv0) {
+			cidv0_c <- buf
+		}
+		if readHash(HashNames.CIDv1) {
+			cidv1_c <- buf
+		}
+		if readHash(HashNames.GitSHA1) {
+			git_sha1_c <- buf
+		}
+		if readHash(HashNames.GitSHA256) {
+			git_sha256_c <- buf
+		}
+		if readHash(HashNames.SSDeep) {
+			ssdeep_c <- buf
+		}
+		if readHash(HashNames.TLSH) {
+			tlsh_c <- buf
+		}
 
 		if err != nil && err != io.EOF {
-			log.Fatal(err)
+			readErr = err
+			break
 		}
 	}
 
 	close(crc32c)
 	close(xxhash64c)
+	close(crc32c_c)
+	close(crc64_ecma_c)
+	close(crc64_iso_c)
+	close(adler32_c)
+	close(xxh3_c)
+	close(xxh128_c)
+	close(murmur3_c)
+	close(siphash_c)
+	close(highwayhash_c)
 	close(md4c)
 	close(md5c)
 	close(sha1c)
 	close(sha256c)
+	close(sha384c)
 	close(sha512c)
 	close(blake2b_256_c)
 	close(blake2b_512_c)
@@ -720,248 +1488,635 @@ func processStandardInput(output chan Result) {
 	close(sha3_256_c)
 	close(sha3_384_c)
 	close(sha3_512_c)
+	close(shake128_c)
+	close(shake256_c)
+	close(cshake128_c)
+	close(cshake256_c)
 	close(ed2k_c)
+	close(aich_c)
+	close(tth_c)
+	close(cidv0_c)
+	close(cidv1_c)
+	close(git_sha1_c)
+	close(git_sha256_c)
+	close(ssdeep_c)
+	close(tlsh_c)
 
 	wg.Wait()
 
-	output <- Result{
-		File:       "stdin",
-		Bytes:      total,
-		CRC32:      encodeIfHashEnabled(crc32_d, HashNames.CRC32),
-		XxHash64:   encodeIfHashEnabled(xxhash64_d, HashNames.XxHash64),
-		MD4:        encodeIfHashEnabled(md4_d, HashNames.MD4),
-		MD5:        encodeIfHashEnabled(md5_d, HashNames.MD5),
-		SHA1:       encodeIfHashEnabled(sha1_d, HashNames.SHA1),
-		SHA256:     encodeIfHashEnabled(sha256_d, HashNames.SHA256),
-		SHA512:     encodeIfHashEnabled(sha512_d, HashNames.SHA512),
-		Blake2b256: encodeIfHashEnabled(blake2b_256_d, HashNames.Blake2b256),
-		Blake2b512: encodeIfHashEnabled(blake2b_512_d, HashNames.Blake2b512),
-		Blake3:     encodeIfHashEnabled(blake3_d, HashNames.Blake3),
-		Sha3224:    encodeIfHashEnabled(sha3_224_d, HashNames.Sha3224),
-		Sha3256:    encodeIfHashEnabled(sha3_256_d, HashNames.Sha3256),
-		Sha3384:    encodeIfHashEnabled(sha3_384_d, HashNames.Sha3384),
-		Sha3512:    encodeIfHashEnabled(sha3_512_d, HashNames.Sha3512),
-		Ed2k:       encodeIfHashEnabled(ed2k_d, HashNames.Ed2k),
+	var blocks []Block
+	if pieces != nil {
+		var err error
+		if blocks, err = pieces.Blocks(); err != nil {
+			printError(fmt.Sprintf("piecewise hashing %s: %s", name, err.Error()))
+			return Result{}, err
+		}
+	}
+
+	return Result{
+		File:        name,
+		Bytes:       total,
+		Blocks:      blocks,
+		CRC32:       encodeIfHashEnabled(crc32_d, HashNames.CRC32),
+		XxHash64:    encodeIfHashEnabled(xxhash64_d, HashNames.XxHash64),
+		CRC32C:      encodeIfHashEnabled(crc32c_d, HashNames.CRC32C),
+		CRC64ECMA:   encodeIfHashEnabled(crc64_ecma_d, HashNames.CRC64ECMA),
+		CRC64ISO:    encodeIfHashEnabled(crc64_iso_d, HashNames.CRC64ISO),
+		Adler32:     encodeIfHashEnabled(adler32_d, HashNames.Adler32),
+		XXH3:        encodeIfHashEnabled(xxh3_d, HashNames.XXH3),
+		XXH128:      encodeIfHashEnabled(xxh128_d, HashNames.XXH128),
+		Murmur3:     encodeIfHashEnabled(murmur3_d, HashNames.Murmur3),
+		SipHash:     encodeIfHashEnabled(siphash_d, HashNames.SipHash),
+		HighwayHash: encodeIfHashEnabled(highwayhash_d, HashNames.HighwayHash),
+		MD4:         encodeIfHashEnabled(md4_d, HashNames.MD4),
+		MD5:         encodeIfHashEnabled(md5_d, HashNames.MD5),
+		SHA1:        encodeIfHashEnabled(sha1_d, HashNames.SHA1),
+		SHA256:      encodeIfHashEnabled(sha256_d, HashNames.SHA256),
+		SHA384:      encodeIfHashEnabled(sha384_d, HashNames.SHA384),
+		SHA512:      encodeIfHashEnabled(sha512_d, HashNames.SHA512),
+		Blake2b256:  encodeIfHashEnabled(blake2b_256_d, HashNames.Blake2b256),
+		Blake2b512:  encodeIfHashEnabled(blake2b_512_d, HashNames.Blake2b512),
+		Blake3:      encodeIfHashEnabled(blake3_d, HashNames.Blake3),
+		Sha3224:     encodeIfHashEnabled(sha3_224_d, HashNames.Sha3224),
+		Sha3256:     encodeIfHashEnabled(sha3_256_d, HashNames.Sha3256),
+		Sha3384:     encodeIfHashEnabled(sha3_384_d, HashNames.Sha3384),
+		Sha3512:     encodeIfHashEnabled(sha3_512_d, HashNames.Sha3512),
+		Shake128:    encodeIfHashEnabled(shake128_d, HashNames.Shake128),
+		Shake256:    encodeIfHashEnabled(shake256_d, HashNames.Shake256),
+		CShake128:   encodeIfHashEnabled(cshake128_d, HashNames.CShake128),
+		CShake256:   encodeIfHashEnabled(cshake256_d, HashNames.CShake256),
+		Ed2k:        encodeIfHashEnabled(ed2k_d, HashNames.Ed2k),
+		AICH:        sumIfHashEnabled(aich_d, HashNames.AICH),
+		TTH:         sumIfHashEnabled(tth_d, HashNames.TTH),
+		CIDv0:       sumIfHashEnabled(cidv0_d, HashNames.CIDv0),
+		CIDv1:       sumIfHashEnabled(cidv1_d, HashNames.CIDv1),
+		GitSHA1:     encodeIfHashEnabled(git_sha1_d, HashNames.GitSHA1),
+		GitSHA256:   encodeIfHashEnabled(git_sha256_d, HashNames.GitSHA256),
+		SSDeep:      sumIfHashEnabled(ssdeep_d, HashNames.SSDeep),
+		TLSH:        sumIfHashEnabled(tlsh_d, HashNames.TLSH),
+	}, readErr
+}
+
+// For files under a certain size its faster to just read them into memory in one
+// chunk and then process them which this method does
+// NB there is little point in multi-processing at this level, it would be
+// better done on the input channel if required
+func processReadFileParallel(filename string, content *[]byte) (Result, error) {
+	startTime := makeTimestampNano()
+
+	var wg sync.WaitGroup
+	result := Result{}
+
+	if readHash(HashNames.CRC32) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := crc32.NewIEEE()
+			d.Write(*content)
+			result.CRC32 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing crc32: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.XxHash64) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := xxhash.New()
+			_, _ = d.Write(*content)
+			result.XxHash64 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing xxhash64: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CRC32C) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newCRC32C()
+			_, _ = d.Write(*content)
+			result.CRC32C = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing crc32c: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CRC64ECMA) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newCRC64ECMA()
+			_, _ = d.Write(*content)
+			result.CRC64ECMA = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing crc64-ecma: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.CRC64ISO) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newCRC64ISO()
+			_, _ = d.Write(*content)
+			result.CRC64ISO = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing crc64-iso: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Adler32) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := adler32.New()
+			_, _ = d.Write(*content)
+			result.Adler32 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing adler32: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.XXH3) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := xxh3.New()
+			_, _ = d.Write(*content)
+			result.XXH3 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing xxh3: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.XXH128) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newXXH128()
+			_, _ = d.Write(*content)
+			result.XXH128 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing xxh128: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Murmur3) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := murmur3.New128()
+			_, _ = d.Write(*content)
+			result.Murmur3 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing murmur3: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SipHash) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newSipHash()
+			_, _ = d.Write(*content)
+			result.SipHash = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing siphash: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.HighwayHash) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newHighwayHash()
+			_, _ = d.Write(*content)
+			result.HighwayHash = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing highwayhash: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.MD4) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := md4.New()
+			d.Write(*content)
+			result.MD4 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing md4: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.MD5) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := md5.New()
+			d.Write(*content)
+			result.MD5 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing md5: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SHA1) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha1.New()
+			d.Write(*content)
+			result.SHA1 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha1: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SHA256) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha256.New()
+			d.Write(*content)
+			result.SHA256 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha256: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SHA384) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha512.New384()
+			_, _ = d.Write(*content)
+			result.SHA384 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha384: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.SHA512) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha512.New()
+			d.Write(*content)
+			result.SHA512 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha512: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Blake2b256) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := blake2b.New256()
+			d.Write(*content)
+			result.Blake2b256 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing blake2b-256: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Blake2b512) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := blake2b.New512()
+			d.Write(*content)
+			result.Blake2b512 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing blake2b-512: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Blake3) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := newBlake3()
+			_, _ = d.Write(*content)
+			result.Blake3 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing blake3: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
+
+	if readHash(HashNames.Sha3224) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha3.New224()
+			d.Write(*content)
+			result.Sha3224 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha3-224: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
 	}
 
-	close(output)
-}
+	if readHash(HashNames.Sha3256) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha3.New256()
+			d.Write(*content)
+			result.Sha3256 = encodeDigest(d.Sum(nil))
 
-// For files under a certain size its faster to just read them into memory in one
-// chunk and then process them which this method does
-// NB there is little point in multi-processing at this level, it would be
-// better done on the input channel if required
-func processReadFileParallel(filename string, content *[]byte) (Result, error) {
-	startTime := makeTimestampNano()
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha3-256: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
 
-	var wg sync.WaitGroup
-	result := Result{}
+	if readHash(HashNames.Sha3384) {
+		wg.Add(1)
+		go func() {
+			startTime = makeTimestampNano()
+			d := sha3.New384()
+			d.Write(*content)
+			result.Sha3384 = encodeDigest(d.Sum(nil))
+
+			if Trace {
+				printTrace(fmt.Sprintf("nanoseconds processing sha3-384: %s: %d", filename, makeTimestampNano()-startTime))
+			}
+			wg.Done()
+		}()
+	}
 
-	if hasHash(HashNames.CRC32) {
+	if readHash(HashNames.Sha3512) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := crc32.NewIEEE()
+			d := sha3.New512()
 			d.Write(*content)
-			result.CRC32 = hex.EncodeToString(d.Sum(nil))
+			result.Sha3512 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing crc32: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing sha3-512: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.XxHash64) {
+	if readHash(HashNames.Shake128) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := xxhash.New()
+			d := newShake128()
 			_, _ = d.Write(*content)
-			result.XxHash64 = hex.EncodeToString(d.Sum(nil))
+			result.Shake128 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing xxhash64: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing shake128: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.MD4) {
+	if readHash(HashNames.Shake256) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := md4.New()
-			d.Write(*content)
-			result.MD4 = hex.EncodeToString(d.Sum(nil))
+			d := newShake256()
+			_, _ = d.Write(*content)
+			result.Shake256 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing md4: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing shake256: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.MD5) {
+	if readHash(HashNames.CShake128) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := md5.New()
-			d.Write(*content)
-			result.MD5 = hex.EncodeToString(d.Sum(nil))
+			d := newCShake128()
+			_, _ = d.Write(*content)
+			result.CShake128 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing md5: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing cshake128: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA1) {
+	if readHash(HashNames.CShake256) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha1.New()
-			d.Write(*content)
-			result.SHA1 = hex.EncodeToString(d.Sum(nil))
+			d := newCShake256()
+			_, _ = d.Write(*content)
+			result.CShake256 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha1: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing cshake256: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA256) {
+	if readHash(HashNames.Ed2k) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha256.New()
-			d.Write(*content)
-			result.SHA256 = hex.EncodeToString(d.Sum(nil))
+			d := ed2k.New()
+			_, _ = d.Write(*content)
+			result.Ed2k = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha256: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing ed2k: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.SHA512) {
+	if readHash(HashNames.AICH) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha512.New()
-			d.Write(*content)
-			result.SHA512 = hex.EncodeToString(d.Sum(nil))
+			d := newAICH()
+			_, _ = d.Write(*content)
+			result.AICH = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha512: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing aich: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b256) {
+	if readHash(HashNames.TTH) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := blake2b.New256()
-			d.Write(*content)
-			result.Blake2b256 = hex.EncodeToString(d.Sum(nil))
+			d := newTTH()
+			_, _ = d.Write(*content)
+			result.TTH = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing blake2b-256: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing tth: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Blake2b512) {
+	if readHash(HashNames.CIDv0) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := blake2b.New512()
-			d.Write(*content)
-			result.Blake2b512 = hex.EncodeToString(d.Sum(nil))
+			d := newCIDv0()
+			_, _ = d.Write(*content)
+			result.CIDv0 = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing blake2b-512: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing cidv0: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Sha3224) {
+	if readHash(HashNames.CIDv1) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha3.New224()
-			d.Write(*content)
-			result.Sha3224 = hex.EncodeToString(d.Sum(nil))
+			d := newCIDv1()
+			_, _ = d.Write(*content)
+			result.CIDv1 = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha3-224: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing cidv1: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Sha3256) {
+	if readHash(HashNames.GitSHA1) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha3.New256()
-			d.Write(*content)
-			result.Sha3256 = hex.EncodeToString(d.Sum(nil))
+			d := newGitSHA1(int64(len(*content)))
+			_, _ = d.Write(*content)
+			result.GitSHA1 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha3-256: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing git-sha1: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Sha3384) {
+	if readHash(HashNames.GitSHA256) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha3.New384()
-			d.Write(*content)
-			result.Sha3384 = hex.EncodeToString(d.Sum(nil))
+			d := newGitSHA256(int64(len(*content)))
+			_, _ = d.Write(*content)
+			result.GitSHA256 = encodeDigest(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha3-384: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing git-sha256: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Sha3512) {
+	if readHash(HashNames.SSDeep) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := sha3.New512()
-			d.Write(*content)
-			result.Sha3512 = hex.EncodeToString(d.Sum(nil))
+			d := ssdeep.New()
+			_, _ = d.Write(*content)
+			result.SSDeep = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing sha3-512: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing ssdeep: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
 	}
 
-	if hasHash(HashNames.Ed2k) {
+	if readHash(HashNames.TLSH) {
 		wg.Add(1)
 		go func() {
 			startTime = makeTimestampNano()
-			d := ed2k.New()
+			d := newTLSH()
 			_, _ = d.Write(*content)
-			result.Ed2k = hex.EncodeToString(d.Sum(nil))
+			result.TLSH = string(d.Sum(nil))
 
 			if Trace {
-				printTrace(fmt.Sprintf("nanoseconds processing ed2k: %s: %d", filename, makeTimestampNano()-startTime))
+				printTrace(fmt.Sprintf("nanoseconds processing tlsh: %s: %d", filename, makeTimestampNano()-startTime))
 			}
 			wg.Done()
 		}()
@@ -972,174 +2127,421 @@ func processReadFileParallel(filename string, content *[]byte) (Result, error) {
 }
 
 func processReadFile(filename string, content *[]byte) (Result, error) {
+	return processReadFileHashes(filename, content, readHash)
+}
+
+// processReadFileHashes hashes the content using only the hashes has returns true for
+func processReadFileHashes(filename string, content *[]byte, has func(string) bool) (Result, error) {
 
 	result := Result{}
 
-	if hasHash(HashNames.CRC32) {
+	if has(HashNames.CRC32) {
 		startTime := makeTimestampNano()
 		d := crc32.NewIEEE()
 		d.Write(*content)
-		result.CRC32 = hex.EncodeToString(d.Sum(nil))
+		result.CRC32 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing crc32: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.XxHash64) {
+	if has(HashNames.XxHash64) {
 		startTime := makeTimestampNano()
 		d := xxhash.New()
 		_, _ = d.Write(*content)
-		result.XxHash64 = hex.EncodeToString(d.Sum(nil))
+		result.XxHash64 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing xxhash64: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.MD4) {
+	if has(HashNames.CRC32C) {
+		startTime := makeTimestampNano()
+		d := newCRC32C()
+		_, _ = d.Write(*content)
+		result.CRC32C = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing crc32c: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CRC64ECMA) {
+		startTime := makeTimestampNano()
+		d := newCRC64ECMA()
+		_, _ = d.Write(*content)
+		result.CRC64ECMA = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing crc64-ecma: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CRC64ISO) {
+		startTime := makeTimestampNano()
+		d := newCRC64ISO()
+		_, _ = d.Write(*content)
+		result.CRC64ISO = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing crc64-iso: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.Adler32) {
+		startTime := makeTimestampNano()
+		d := adler32.New()
+		_, _ = d.Write(*content)
+		result.Adler32 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing adler32: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.XXH3) {
+		startTime := makeTimestampNano()
+		d := xxh3.New()
+		_, _ = d.Write(*content)
+		result.XXH3 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing xxh3: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.XXH128) {
+		startTime := makeTimestampNano()
+		d := newXXH128()
+		_, _ = d.Write(*content)
+		result.XXH128 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing xxh128: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.Murmur3) {
+		startTime := makeTimestampNano()
+		d := murmur3.New128()
+		_, _ = d.Write(*content)
+		result.Murmur3 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing murmur3: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.SipHash) {
+		startTime := makeTimestampNano()
+		d := newSipHash()
+		_, _ = d.Write(*content)
+		result.SipHash = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing siphash: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.HighwayHash) {
+		startTime := makeTimestampNano()
+		d := newHighwayHash()
+		_, _ = d.Write(*content)
+		result.HighwayHash = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing highwayhash: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.MD4) {
 		startTime := makeTimestampNano()
 		d := md4.New()
 		d.Write(*content)
-		result.MD4 = hex.EncodeToString(d.Sum(nil))
+		result.MD4 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing md4: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.MD5) {
+	if has(HashNames.MD5) {
 		startTime := makeTimestampNano()
 		d := md5.New()
 		d.Write(*content)
-		result.MD5 = hex.EncodeToString(d.Sum(nil))
+		result.MD5 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing md5: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.SHA1) {
+	if has(HashNames.SHA1) {
 		startTime := makeTimestampNano()
 		d := sha1.New()
 		d.Write(*content)
-		result.SHA1 = hex.EncodeToString(d.Sum(nil))
+		result.SHA1 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha1: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.SHA256) {
+	if has(HashNames.SHA256) {
 		startTime := makeTimestampNano()
 		d := sha256.New()
 		d.Write(*content)
-		result.SHA256 = hex.EncodeToString(d.Sum(nil))
+		result.SHA256 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha256: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.SHA512) {
+	if has(HashNames.SHA384) {
+		startTime := makeTimestampNano()
+		d := sha512.New384()
+		_, _ = d.Write(*content)
+		result.SHA384 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing sha384: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.SHA512) {
 		startTime := makeTimestampNano()
 		d := sha512.New()
 		d.Write(*content)
-		result.SHA512 = hex.EncodeToString(d.Sum(nil))
+		result.SHA512 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha512: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Blake2b256) {
+	if has(HashNames.Blake2b256) {
 		startTime := makeTimestampNano()
 		d := blake2b.New256()
 		d.Write(*content)
-		result.Blake2b256 = hex.EncodeToString(d.Sum(nil))
+		result.Blake2b256 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing blake2b-256: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Blake2b512) {
+	if has(HashNames.Blake2b512) {
 		startTime := makeTimestampNano()
 		d := blake2b.New512()
 		d.Write(*content)
-		result.Blake2b512 = hex.EncodeToString(d.Sum(nil))
+		result.Blake2b512 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing blake2b-512: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Blake3) {
+	if has(HashNames.Blake3) {
 		startTime := makeTimestampNano()
-		d := blake3.New()
+		d := newBlake3()
 		_, _ = d.Write(*content)
-		result.Blake3 = hex.EncodeToString(d.Sum(nil))
+		result.Blake3 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing blake3: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Sha3224) {
+	if has(HashNames.Sha3224) {
 		startTime := makeTimestampNano()
 		d := sha3.New224()
 		d.Write(*content)
-		result.Sha3224 = hex.EncodeToString(d.Sum(nil))
+		result.Sha3224 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha3-224: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Sha3256) {
+	if has(HashNames.Sha3256) {
 		startTime := makeTimestampNano()
 		d := sha3.New256()
 		d.Write(*content)
-		result.Sha3256 = hex.EncodeToString(d.Sum(nil))
+		result.Sha3256 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha3-256: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Sha3384) {
+	if has(HashNames.Sha3384) {
 		startTime := makeTimestampNano()
 		d := sha3.New384()
 		d.Write(*content)
-		result.Sha3384 = hex.EncodeToString(d.Sum(nil))
+		result.Sha3384 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha3-384: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Sha3512) {
+	if has(HashNames.Sha3512) {
 		startTime := makeTimestampNano()
 		d := sha3.New512()
 		d.Write(*content)
-		result.Sha3512 = hex.EncodeToString(d.Sum(nil))
+		result.Sha3512 = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing sha3-512: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
-	if hasHash(HashNames.Ed2k) {
+	if has(HashNames.Shake128) {
+		startTime := makeTimestampNano()
+		d := newShake128()
+		_, _ = d.Write(*content)
+		result.Shake128 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing shake128: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.Shake256) {
+		startTime := makeTimestampNano()
+		d := newShake256()
+		_, _ = d.Write(*content)
+		result.Shake256 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing shake256: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CShake128) {
+		startTime := makeTimestampNano()
+		d := newCShake128()
+		_, _ = d.Write(*content)
+		result.CShake128 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing cshake128: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CShake256) {
+		startTime := makeTimestampNano()
+		d := newCShake256()
+		_, _ = d.Write(*content)
+		result.CShake256 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing cshake256: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.Ed2k) {
 		startTime := makeTimestampNano()
 		d := ed2k.New()
 		_, _ = d.Write(*content)
-		result.Ed2k = hex.EncodeToString(d.Sum(nil))
+		result.Ed2k = encodeDigest(d.Sum(nil))
 
 		if Trace {
 			printTrace(fmt.Sprintf("nanoseconds processing ed2k: %s: %d", filename, makeTimestampNano()-startTime))
 		}
 	}
 
+	if has(HashNames.AICH) {
+		startTime := makeTimestampNano()
+		d := newAICH()
+		_, _ = d.Write(*content)
+		result.AICH = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing aich: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.TTH) {
+		startTime := makeTimestampNano()
+		d := newTTH()
+		_, _ = d.Write(*content)
+		result.TTH = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing tth: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CIDv0) {
+		startTime := makeTimestampNano()
+		d := newCIDv0()
+		_, _ = d.Write(*content)
+		result.CIDv0 = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing cidv0: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.CIDv1) {
+		startTime := makeTimestampNano()
+		d := newCIDv1()
+		_, _ = d.Write(*content)
+		result.CIDv1 = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing cidv1: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.GitSHA1) {
+		startTime := makeTimestampNano()
+		d := newGitSHA1(int64(len(*content)))
+		_, _ = d.Write(*content)
+		result.GitSHA1 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing git-sha1: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.GitSHA256) {
+		startTime := makeTimestampNano()
+		d := newGitSHA256(int64(len(*content)))
+		_, _ = d.Write(*content)
+		result.GitSHA256 = encodeDigest(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing git-sha256: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.SSDeep) {
+		startTime := makeTimestampNano()
+		d := ssdeep.New()
+		_, _ = d.Write(*content)
+		result.SSDeep = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing ssdeep: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
+	if has(HashNames.TLSH) {
+		startTime := makeTimestampNano()
+		d := newTLSH()
+		_, _ = d.Write(*content)
+		result.TLSH = string(d.Sum(nil))
+
+		if Trace {
+			printTrace(fmt.Sprintf("nanoseconds processing tlsh: %s: %d", filename, makeTimestampNano()-startTime))
+		}
+	}
+
 	return result, nil
 }
 
@@ -1170,8 +2572,17 @@ func readAll(r io.Reader, capacity int64) (b []byte, err error) {
 // digest of an empty buffer.
 // By returning an empty string we can also make use of omitting it in the JSON output.
 func encodeIfHashEnabled(h hash.Hash, hashName string) string {
-	if hasHash(hashName) {
-		return hex.EncodeToString(h.Sum(nil))
+	if readHash(hashName) {
+		return encodeDigest(h.Sum(nil))
+	}
+	return ""
+}
+
+// Fuzzy hashes such as ssdeep and TLSH produce text digests rather than bytes
+// so they are returned as is rather than encoded
+func sumIfHashEnabled(h hash.Hash, hashName string) string {
+	if readHash(hashName) {
+		return string(h.Sum(nil))
 	}
 	return ""
 }
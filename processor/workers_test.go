@@ -27,6 +27,91 @@ func TestProcessReadFile(t *testing.T) {
 	}
 }
 
+func TestProcessReadFileChecksums(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = append(Hash, "all")
+
+	content := []byte("123456789")
+	res, _ := processReadFile("filename", &content)
+	resParallel, _ := processReadFileParallel("filename", &content)
+
+	expected := map[string]string{
+		"crc32":     "cbf43926",
+		"crc32c":    "e3069283",
+		"crc64ecma": "995dc9bbdf1939fa",
+		"crc64iso":  "b90956c775a41001",
+		"adler32":   "091e01de",
+	}
+
+	for name, want := range expected {
+		if got := res.Digest(name); got != want {
+			t.Errorf("%s expected %s got %s", name, want, got)
+		}
+		if got := resParallel.Digest(name); got != want {
+			t.Errorf("%s parallel expected %s got %s", name, want, got)
+		}
+	}
+}
+
+func TestProcessReadFileEmptyChecksums(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = append(Hash, "all")
+
+	res, _ := processReadFile("filename", &[]byte{})
+
+	if res.XXH3 != "2d06800538d394c2" {
+		t.Errorf("Expected 2d06800538d394c2 got %s", res.XXH3)
+	}
+
+	if res.XXH128 != "99aa06d3014798d86001c324468d497f" {
+		t.Errorf("Expected 99aa06d3014798d86001c324468d497f got %s", res.XXH128)
+	}
+
+	if res.Murmur3 != "00000000000000000000000000000000" {
+		t.Errorf("Expected 00000000000000000000000000000000 got %s", res.Murmur3)
+	}
+}
+
+func TestProcessReadFileDigestLengths(t *testing.T) {
+	t.Cleanup(resetState)
+	Hash = []string{"blake3", "shake128", "shake256", "cshake128"}
+	HashLengths["blake3"] = 512
+
+	res, _ := processReadFile("filename", &[]byte{})
+
+	if res.Blake3 != "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262e00f03e7b69af26b7faaf09fcd333050338ddfe085b8cc869ca98b206c08243a" {
+		t.Errorf("Expected 512 bit blake3 got %s", res.Blake3)
+	}
+
+	if res.Shake128 != "7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26" {
+		t.Errorf("Expected 256 bit shake128 got %s", res.Shake128)
+	}
+
+	if res.Shake256 != "46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be" {
+		t.Errorf("Expected 512 bit shake256 got %s", res.Shake256)
+	}
+
+	// with no customization cSHAKE is defined to be SHAKE
+	if res.CShake128 != res.Shake128 {
+		t.Errorf("Expected cshake128 to match shake128 got %s", res.CShake128)
+	}
+}
+
+func TestParseHashLength(t *testing.T) {
+	name, bits, err := parseHashLength("shake256:64")
+	if err != nil || name != "shake256" || bits != 64 {
+		t.Errorf("Expected shake256 64 got %s %d %v", name, bits, err)
+	}
+
+	if _, _, err := parseHashLength("md5:128"); err == nil {
+		t.Error("Expected error for fixed length hash")
+	}
+
+	if _, _, err := parseHashLength("blake3:12"); err == nil {
+		t.Error("Expected error for length not a multiple of 8")
+	}
+}
+
 func TestEncodeIfHashEnabled(t *testing.T) {
 	t.Cleanup(resetState)
 	hash_md5 := md5.New()
@@ -50,6 +135,9 @@ func TestEncodeIfHashEnabled(t *testing.T) {
 // Reset the global state after test.
 func resetState() {
 	Hash = []string{}
+	HashLengths = map[string]int{}
+	Encoding = "hex"
+	readHashes = nil
 }
 
 //////////////////////////////////////////////////